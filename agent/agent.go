@@ -22,6 +22,7 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/auth"
 	agentinternal "google.golang.org/adk/internal/agent"
 	"google.golang.org/adk/internal/plugininternal/plugincontext"
 	"google.golang.org/adk/memory"
@@ -118,6 +119,15 @@ type Memory interface {
 	Search(ctx context.Context, query string) (*memory.SearchResponse, error)
 }
 
+// Credentials interface provides methods to access the credentials of the
+// current user, e.g. the tokens obtained once they authorized a tool.
+type Credentials interface {
+	// Load returns the credential stored under the key, or nil if there is
+	// none.
+	Load(ctx context.Context, key string) (*auth.Credential, error)
+	Save(ctx context.Context, key string, cred *auth.Credential) error
+}
+
 // BeforeAgentCallback is a function that is called before the agent starts
 // its run.
 // If it returns non-nil content or error, the agent run will be skipped and a
@@ -159,11 +169,12 @@ func (a *agent) Run(ctx InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
 		// TODO: verify&update the setup here. Should we branch etc.
 		ctx := &invocationContext{
-			Context:   ctx,
-			agent:     a,
-			artifacts: ctx.Artifacts(),
-			memory:    ctx.Memory(),
-			session:   ctx.Session(),
+			Context:     ctx,
+			agent:       a,
+			artifacts:   ctx.Artifacts(),
+			memory:      ctx.Memory(),
+			credentials: ctx.Credentials(),
+			session:     ctx.Session(),
 
 			invocationID:  ctx.InvocationID(),
 			branch:        ctx.Branch(),
@@ -418,10 +429,11 @@ func (c *callbackContextState) All() iter.Seq2[string, any] {
 type invocationContext struct {
 	context.Context
 
-	agent     Agent
-	artifacts Artifacts
-	memory    Memory
-	session   session.Session
+	agent       Agent
+	artifacts   Artifacts
+	memory      Memory
+	credentials Credentials
+	session     session.Session
 
 	invocationID  string
 	branch        string
@@ -442,6 +454,10 @@ func (c *invocationContext) Memory() Memory {
 	return c.memory
 }
 
+func (c *invocationContext) Credentials() Credentials {
+	return c.credentials
+}
+
 func (c *invocationContext) Session() session.Session {
 	return c.session
 }
@@ -0,0 +1,513 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+type namedModel struct {
+	name string
+}
+
+func (m *namedModel) Name() string {
+	return m.name
+}
+
+func (m *namedModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {}
+}
+
+type namedToolset struct {
+	name string
+}
+
+func (ts *namedToolset) Name() string {
+	return ts.name
+}
+
+func (ts *namedToolset) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
+	return nil, nil
+}
+
+func testRegistry(t *testing.T) *Registry {
+	t.Helper()
+	search, err := functiontool.New(functiontool.Config{Name: "search", Description: "Searches the web."},
+		func(ctx tool.Context, args struct{ Query string }) (string, error) { return "", nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	reviewer, err := llmagent.New(llmagent.Config{Name: "reviewer", Model: &namedModel{name: "go-model"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Registry{
+		Models: func(ctx context.Context, name string) (model.LLM, error) {
+			if name == "broken" {
+				return nil, errors.New("no such model")
+			}
+			return &namedModel{name: name}, nil
+		},
+		Agents:   map[string]agent.Agent{"reviewer": reviewer},
+		Tools:    map[string]tool.Tool{"search": search},
+		Toolsets: map[string]tool.Toolset{"mcp": &namedToolset{name: "mcp"}},
+		BeforeAgentCallbacks: map[string]agent.BeforeAgentCallback{
+			"greet": func(agent.CallbackContext) (*genai.Content, error) { return nil, nil },
+		},
+		BeforeModelCallbacks: map[string]llmagent.BeforeModelCallback{
+			"log_request": func(agent.CallbackContext, *model.LLMRequest) (*model.LLMResponse, error) { return nil, nil },
+		},
+	}
+}
+
+// writeFiles writes the files to a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// summary is the part of an agent tree the tests compare.
+type summary struct {
+	Name        string
+	Description string
+	Model       string
+	Instruction string
+	OutputKey   string
+	Tools       []string
+	Toolsets    []string
+	SubAgents   []summary
+}
+
+func summarize(a agent.Agent) summary {
+	s := summary{Name: a.Name(), Description: a.Description()}
+	if llmAgent, ok := a.(llminternal.Agent); ok {
+		state := llminternal.Reveal(llmAgent)
+		s.Model = state.Model.Name()
+		s.Instruction = state.Instruction
+		s.OutputKey = state.OutputKey
+		for _, t := range state.Tools {
+			s.Tools = append(s.Tools, t.Name())
+		}
+		for _, ts := range state.Toolsets {
+			s.Toolsets = append(s.Toolsets, ts.Name())
+		}
+	}
+	for _, sub := range a.SubAgents() {
+		s.SubAgents = append(s.SubAgents, summarize(sub))
+	}
+	return s
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  summary
+	}{
+		{
+			name: "llm agent",
+			files: map[string]string{
+				"root.yaml": `
+name: assistant
+description: Answers questions.
+model: gemini-2.5-flash
+instruction: Answer {question}.
+output_key: answer
+include_contents: none
+tools:
+  - name: search
+  - name: mcp
+before_agent_callbacks:
+  - name: greet
+before_model_callbacks:
+  - name: log_request
+`,
+			},
+			want: summary{
+				Name:        "assistant",
+				Description: "Answers questions.",
+				Model:       "gemini-2.5-flash",
+				Instruction: "Answer {question}.",
+				OutputKey:   "answer",
+				Tools:       []string{"search"},
+				Toolsets:    []string{"mcp"},
+			},
+		},
+		{
+			name: "workflow tree",
+			files: map[string]string{
+				"root.yaml": `
+name: pipeline
+agent_class: SequentialAgent
+sub_agents:
+  - config_path: agents/loop.yaml
+  - code: reviewer
+`,
+				"agents/loop.yaml": `
+name: refine
+agent_class: LoopAgent
+max_iterations: 3
+sub_agents:
+  - config_path: parallel.yaml
+`,
+				"agents/parallel.yaml": `
+name: drafts
+agent_class: ParallelAgent
+sub_agents:
+  - config_path: writer.yaml
+`,
+				"agents/writer.yaml": `
+name: writer
+model: gemini-2.5-pro
+instruction: Write a draft.
+output_key: draft
+`,
+			},
+			want: summary{
+				Name: "pipeline",
+				SubAgents: []summary{
+					{
+						Name: "refine",
+						SubAgents: []summary{{
+							Name: "drafts",
+							SubAgents: []summary{{
+								Name:        "writer",
+								Model:       "gemini-2.5-pro",
+								Instruction: "Write a draft.",
+								OutputKey:   "draft",
+							}},
+						}},
+					},
+					{Name: "reviewer", Model: "go-model"},
+				},
+			},
+		},
+		{
+			name: "inherited model",
+			files: map[string]string{
+				"root.yaml": `
+name: coordinator
+model: gemini-2.5-flash
+sub_agents:
+  - config_path: helper.yaml
+`,
+				"helper.yaml": `
+name: helper
+instruction: Help.
+`,
+			},
+			want: summary{
+				Name:  "coordinator",
+				Model: "gemini-2.5-flash",
+				SubAgents: []summary{
+					{Name: "helper", Model: "gemini-2.5-flash", Instruction: "Help."},
+				},
+			},
+		},
+		{
+			name: "json",
+			files: map[string]string{
+				"root.yaml": `{
+  "name": "assistant",
+  "model": "gemini-2.5-flash",
+  "instruction": "Be brief.",
+  "tools": [{"name": "search"}]
+}`,
+			},
+			want: summary{
+				Name:        "assistant",
+				Model:       "gemini-2.5-flash",
+				Instruction: "Be brief.",
+				Tools:       []string{"search"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			a, err := Load(t.Context(), filepath.Join(dir, "root.yaml"), testRegistry(t))
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, summarize(a)); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		// wantErr is the error, with the file path relative to the
+		// directory of the files.
+		wantErr Error
+		wantMsg string
+	}{
+		{
+			name: "unknown field",
+			files: map[string]string{
+				"root.yaml": `
+name: assistant
+model: gemini-2.5-flash
+instructions: Be brief.
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 4, Field: "instructions"},
+			wantMsg: "unknown field",
+		},
+		{
+			name: "unknown nested field",
+			files: map[string]string{
+				"root.yaml": `
+name: assistant
+model: gemini-2.5-flash
+tools:
+  - name: search
+  - tool: mcp
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 6, Field: "tools[1].tool"},
+			wantMsg: "unknown field",
+		},
+		{
+			name: "wrong kind",
+			files: map[string]string{
+				"root.yaml": `
+name: assistant
+model: gemini-2.5-flash
+tools: search
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 4, Field: "tools"},
+			wantMsg: "want a list",
+		},
+		{
+			name: "missing name",
+			files: map[string]string{
+				"root.yaml": "model: gemini-2.5-flash\n",
+			},
+			wantErr: Error{File: "root.yaml", Field: "name"},
+			wantMsg: "name is required",
+		},
+		{
+			name: "missing model",
+			files: map[string]string{
+				"root.yaml": "name: assistant\n",
+			},
+			wantErr: Error{File: "root.yaml", Field: "model"},
+			wantMsg: "model is required",
+		},
+		{
+			name: "model error",
+			files: map[string]string{
+				"root.yaml": "name: assistant\nmodel: broken\n",
+			},
+			wantErr: Error{File: "root.yaml", Line: 2, Field: "model"},
+			wantMsg: "no such model",
+		},
+		{
+			name: "unknown agent class",
+			files: map[string]string{
+				"root.yaml": "name: assistant\nagent_class: GraphAgent\n",
+			},
+			wantErr: Error{File: "root.yaml", Line: 2, Field: "agent_class"},
+			wantMsg: `unknown agent class "GraphAgent"`,
+		},
+		{
+			name: "llm field on workflow agent",
+			files: map[string]string{
+				"root.yaml": "name: pipeline\nagent_class: SequentialAgent\ninstruction: Do things.\n",
+			},
+			wantErr: Error{File: "root.yaml", Line: 3, Field: "instruction"},
+			wantMsg: "not supported by SequentialAgent",
+		},
+		{
+			name: "max iterations on llm agent",
+			files: map[string]string{
+				"root.yaml": "name: assistant\nmodel: gemini-2.5-flash\nmax_iterations: 2\n",
+			},
+			wantErr: Error{File: "root.yaml", Line: 3, Field: "max_iterations"},
+			wantMsg: "not supported by LlmAgent",
+		},
+		{
+			name: "unknown tool in sub-agent",
+			files: map[string]string{
+				"root.yaml": `
+name: pipeline
+agent_class: SequentialAgent
+sub_agents:
+  - config_path: writer.yaml
+`,
+				"writer.yaml": `
+name: writer
+model: gemini-2.5-flash
+tools:
+  - name: search
+  - name: calculator
+`,
+			},
+			wantErr: Error{File: "writer.yaml", Line: 6, Field: "tools[1].name"},
+			wantMsg: `unknown tool or toolset "calculator"`,
+		},
+		{
+			name: "unknown callback",
+			files: map[string]string{
+				"root.yaml": `
+name: assistant
+model: gemini-2.5-flash
+after_model_callbacks:
+  - name: log_response
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 5, Field: "after_model_callbacks[0].name"},
+			wantMsg: `unknown after model callback "log_response"`,
+		},
+		{
+			name: "unknown code agent",
+			files: map[string]string{
+				"root.yaml": `
+name: pipeline
+agent_class: SequentialAgent
+sub_agents:
+  - code: critic
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 5, Field: "sub_agents[0].code"},
+			wantMsg: `unknown agent "critic"`,
+		},
+		{
+			name: "ambiguous sub-agent",
+			files: map[string]string{
+				"root.yaml": `
+name: pipeline
+agent_class: SequentialAgent
+sub_agents:
+  - code: reviewer
+    config_path: reviewer.yaml
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 5, Field: "sub_agents[0]"},
+			wantMsg: "exactly one of config_path or code is required",
+		},
+		{
+			name: "missing sub-agent file",
+			files: map[string]string{
+				"root.yaml": `
+name: pipeline
+agent_class: SequentialAgent
+sub_agents:
+  - config_path: missing.yaml
+`,
+			},
+			wantErr: Error{File: "root.yaml", Line: 5, Field: "sub_agents[0].config_path"},
+			wantMsg: "no such file or directory",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"root.yaml": `
+name: pipeline
+agent_class: SequentialAgent
+sub_agents:
+  - config_path: loop.yaml
+`,
+				"loop.yaml": `
+name: refine
+agent_class: LoopAgent
+sub_agents:
+  - config_path: root.yaml
+`,
+			},
+			wantErr: Error{File: "loop.yaml", Line: 5, Field: "sub_agents[0].config_path"},
+			wantMsg: "root.yaml references itself",
+		},
+		{
+			name: "invalid yaml",
+			files: map[string]string{
+				"root.yaml": "name: [assistant\n",
+			},
+			wantErr: Error{File: "root.yaml"},
+			wantMsg: "yaml:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			_, err := Load(t.Context(), filepath.Join(dir, "root.yaml"), testRegistry(t))
+			var cfgErr *Error
+			if !errors.As(err, &cfgErr) {
+				t.Fatalf("Load() error = %v, want *Error", err)
+			}
+			got := Error{File: strings.TrimPrefix(cfgErr.File, dir+string(filepath.Separator)), Line: cfgErr.Line, Field: cfgErr.Field}
+			if diff := cmp.Diff(tt.wantErr, got); diff != "" {
+				t.Errorf("Load() error mismatch (-want +got):\n%s", diff)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("Load() error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestNewLoader(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"root.yaml":   "name: assistant\nmodel: gemini-2.5-flash\n",
+		"helper.yaml": "name: helper\nmodel: gemini-2.5-flash\n",
+	})
+	loader, err := NewLoader(t.Context(), testRegistry(t), filepath.Join(dir, "root.yaml"), filepath.Join(dir, "helper.yaml"))
+	if err != nil {
+		t.Fatalf("NewLoader() error = %v", err)
+	}
+	if got := loader.RootAgent().Name(); got != "assistant" {
+		t.Errorf("RootAgent().Name() = %q, want %q", got, "assistant")
+	}
+	got := loader.ListAgents()
+	slices.Sort(got)
+	if diff := cmp.Diff([]string{"assistant", "helper"}, got); diff != "" {
+		t.Errorf("ListAgents() mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := loader.LoadAgent("helper"); err != nil {
+		t.Errorf("LoadAgent(%q) error = %v", "helper", err)
+	}
+}
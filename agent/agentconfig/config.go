@@ -0,0 +1,164 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentconfig loads agent trees from declarative YAML or JSON files,
+// so instructions and the structure of the tree can change without changing
+// Go code.
+//
+// A file describes one agent. For example:
+//
+//	name: root
+//	agent_class: SequentialAgent
+//	description: Writes and reviews a story.
+//	sub_agents:
+//	  - config_path: writer.yaml
+//	  - code: reviewer
+//
+// with writer.yaml:
+//
+//	name: writer
+//	model: gemini-2.5-flash
+//	instruction: Write a short story about {topic}.
+//	output_key: story
+//	tools:
+//	  - name: search
+//	before_model_callbacks:
+//	  - name: log_request
+//
+// agent_class is one of LlmAgent, the default, SequentialAgent,
+// ParallelAgent or LoopAgent. The fields are:
+//
+//   - name, description, sub_agents, before_agent_callbacks and
+//     after_agent_callbacks for all agents.
+//   - model, instruction, global_instruction, output_key, include_contents,
+//     disallow_transfer_to_parent, disallow_transfer_to_peers, tools,
+//     before_model_callbacks, after_model_callbacks, before_tool_callbacks
+//     and after_tool_callbacks for LlmAgent.
+//   - max_iterations for LoopAgent.
+//
+// Sub-agents are either other config files, with config_path relative to the
+// file referencing them, or agents created in Go, with code set to their name
+// in the [Registry]. Tools, toolsets and callbacks are referenced by their
+// name in the Registry. An LlmAgent without a model uses the model of its
+// nearest LlmAgent ancestor.
+//
+// JSON files use the same field names. Invalid files are reported with an
+// [*Error] pointing at the file and the field.
+package agentconfig
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool"
+)
+
+// Agent classes of the agent_class field.
+const (
+	LLMAgent        = "LlmAgent"
+	SequentialAgent = "SequentialAgent"
+	ParallelAgent   = "ParallelAgent"
+	LoopAgent       = "LoopAgent"
+)
+
+// Registry holds the Go values config files reference by name.
+type Registry struct {
+	// Models returns the model with the given name, e.g. by calling
+	// gemini.NewModel. It is required if any file sets a model.
+	Models func(ctx context.Context, name string) (model.LLM, error)
+
+	// Agents are the agents referenced by the code field of sub_agents.
+	Agents map[string]agent.Agent
+
+	// Tools and Toolsets are referenced by the tools field. A name must not
+	// be both a tool and a toolset.
+	Tools    map[string]tool.Tool
+	Toolsets map[string]tool.Toolset
+
+	BeforeAgentCallbacks map[string]agent.BeforeAgentCallback
+	AfterAgentCallbacks  map[string]agent.AfterAgentCallback
+	BeforeModelCallbacks map[string]llmagent.BeforeModelCallback
+	AfterModelCallbacks  map[string]llmagent.AfterModelCallback
+	BeforeToolCallbacks  map[string]llmagent.BeforeToolCallback
+	AfterToolCallbacks   map[string]llmagent.AfterToolCallback
+}
+
+// Error is an invalid config file.
+type Error struct {
+	// File is the path of the config file.
+	File string
+	// Line is the line of the field in the file, or 0 if unknown, e.g. for
+	// a missing field.
+	Line int
+	// Field is the path of the field, e.g. "sub_agents[1].config_path", or
+	// empty if the error is not about a field.
+	Field string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	pos := e.File
+	if e.Line > 0 {
+		pos = fmt.Sprintf("%s:%d", pos, e.Line)
+	}
+	if e.Field == "" {
+		return fmt.Sprintf("%s: %v", pos, e.Err)
+	}
+	return fmt.Sprintf("%s: %s: %v", pos, e.Field, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// agentConfig is the content of a config file.
+type agentConfig struct {
+	AgentClass           string      `yaml:"agent_class"`
+	Name                 string      `yaml:"name"`
+	Description          string      `yaml:"description"`
+	SubAgents            []subAgent  `yaml:"sub_agents"`
+	BeforeAgentCallbacks []reference `yaml:"before_agent_callbacks"`
+	AfterAgentCallbacks  []reference `yaml:"after_agent_callbacks"`
+
+	// LlmAgent fields.
+	Model                    string      `yaml:"model"`
+	Instruction              string      `yaml:"instruction"`
+	GlobalInstruction        string      `yaml:"global_instruction"`
+	OutputKey                string      `yaml:"output_key"`
+	IncludeContents          string      `yaml:"include_contents"`
+	DisallowTransferToParent bool        `yaml:"disallow_transfer_to_parent"`
+	DisallowTransferToPeers  bool        `yaml:"disallow_transfer_to_peers"`
+	Tools                    []reference `yaml:"tools"`
+	BeforeModelCallbacks     []reference `yaml:"before_model_callbacks"`
+	AfterModelCallbacks      []reference `yaml:"after_model_callbacks"`
+	BeforeToolCallbacks      []reference `yaml:"before_tool_callbacks"`
+	AfterToolCallbacks       []reference `yaml:"after_tool_callbacks"`
+
+	// LoopAgent fields.
+	MaxIterations uint `yaml:"max_iterations"`
+}
+
+// subAgent references a sub-agent by exactly one of its fields.
+type subAgent struct {
+	ConfigPath string `yaml:"config_path"`
+	Code       string `yaml:"code"`
+}
+
+// reference references a value of the Registry.
+type reference struct {
+	Name string `yaml:"name"`
+}
@@ -0,0 +1,416 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
+	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/model"
+)
+
+// Load creates the agent tree described by the config file at path.
+//
+// The values referenced by name are looked up in reg, which may be nil if
+// the files reference none.
+func Load(ctx context.Context, path string, reg *Registry) (agent.Agent, error) {
+	if reg == nil {
+		reg = &Registry{}
+	}
+	b := &builder{ctx: ctx, reg: reg}
+	a, err := b.load(path, nil)
+	if err != nil {
+		var cfgErr *Error
+		if errors.As(err, &cfgErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load agent config: %w", err)
+	}
+	return a, nil
+}
+
+// NewLoader returns an [agent.Loader] serving the agent trees described by
+// the config files, e.g. for the launchers or the REST server. The agent of
+// the root file is the root agent.
+func NewLoader(ctx context.Context, reg *Registry, root string, others ...string) (agent.Loader, error) {
+	rootAgent, err := Load(ctx, root, reg)
+	if err != nil {
+		return nil, err
+	}
+	agents := make([]agent.Agent, 0, len(others))
+	for _, path := range others {
+		a, err := Load(ctx, path, reg)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, a)
+	}
+	return agent.NewMultiLoader(rootAgent, agents...)
+}
+
+type builder struct {
+	ctx context.Context
+	reg *Registry
+	// loading are the absolute paths of the files being loaded, to detect
+	// cycles.
+	loading []string
+}
+
+// file is a parsed config file.
+type file struct {
+	path string
+	// lines are the lines of the fields, by field path.
+	lines map[string]int
+}
+
+func (f *file) errorf(field, format string, args ...any) error {
+	return &Error{File: f.path, Line: f.lines[field], Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// load creates the agent of the file at path. An LlmAgent without a model
+// uses the inherited one.
+func (b *builder) load(path string, inherited model.LLM) (agent.Agent, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	f := &file{path: path, lines: make(map[string]int)}
+	cfg, err := f.parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	b.loading = append(b.loading, abs)
+	defer func() { b.loading = b.loading[:len(b.loading)-1] }()
+	return b.build(f, cfg, inherited)
+}
+
+// parse decodes the config. Unknown fields are errors.
+func (f *file) parse(data []byte) (*agentConfig, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, &Error{File: f.path, Err: err}
+	}
+	if len(doc.Content) == 0 {
+		return nil, &Error{File: f.path, Err: errors.New("empty config")}
+	}
+	root := doc.Content[0]
+	if err := f.check(root, reflect.TypeFor[agentConfig](), ""); err != nil {
+		return nil, err
+	}
+	var cfg agentConfig
+	if err := root.Decode(&cfg); err != nil {
+		return nil, &Error{File: f.path, Err: err}
+	}
+	return &cfg, nil
+}
+
+// check reports unknown fields and fields of the wrong kind, and records the
+// lines of the fields.
+func (f *file) check(n *yaml.Node, t reflect.Type, field string) error {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Tag == "!!null" {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			return &Error{File: f.path, Line: n.Line, Field: field, Err: errors.New("want a mapping")}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			name := key.Value
+			if field != "" {
+				name = field + "." + key.Value
+			}
+			sf, ok := fieldByName(t, key.Value)
+			if !ok {
+				return &Error{File: f.path, Line: key.Line, Field: name, Err: errors.New("unknown field")}
+			}
+			f.lines[name] = key.Line
+			if err := f.check(value, sf.Type, name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			return &Error{File: f.path, Line: n.Line, Field: field, Err: errors.New("want a list")}
+		}
+		for i, item := range n.Content {
+			name := fmt.Sprintf("%s[%d]", field, i)
+			f.lines[name] = item.Line
+			if err := f.check(item, t.Elem(), name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldByName returns the field of the struct type with the yaml name.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if tag, _, _ := strings.Cut(sf.Tag.Get("yaml"), ","); tag == name {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func (b *builder) build(f *file, cfg *agentConfig, inherited model.LLM) (agent.Agent, error) {
+	if cfg.Name == "" {
+		return nil, f.errorf("name", "name is required")
+	}
+	class := cfg.AgentClass
+	if class == "" {
+		class = LLMAgent
+	}
+	llm := inherited
+	switch class {
+	case LLMAgent:
+		if cfg.Model != "" {
+			var err error
+			if llm, err = b.model(f, cfg.Model); err != nil {
+				return nil, err
+			}
+		} else if llm == nil {
+			return nil, f.errorf("model", "model is required, since no ancestor has one")
+		}
+	case SequentialAgent, ParallelAgent, LoopAgent:
+		if field := llmAgentField(cfg); field != "" {
+			return nil, f.errorf(field, "not supported by %s", class)
+		}
+	default:
+		return nil, f.errorf("agent_class", "unknown agent class %q, want one of %s, %s, %s or %s",
+			cfg.AgentClass, LLMAgent, SequentialAgent, ParallelAgent, LoopAgent)
+	}
+	if class != LoopAgent && cfg.MaxIterations != 0 {
+		return nil, f.errorf("max_iterations", "not supported by %s", class)
+	}
+
+	subAgents, err := b.subAgents(f, cfg.SubAgents, llm)
+	if err != nil {
+		return nil, err
+	}
+	beforeAgentCallbacks, err := lookup(f, "before_agent_callbacks", cfg.BeforeAgentCallbacks, b.reg.BeforeAgentCallbacks, "before agent callback")
+	if err != nil {
+		return nil, err
+	}
+	afterAgentCallbacks, err := lookup(f, "after_agent_callbacks", cfg.AfterAgentCallbacks, b.reg.AfterAgentCallbacks, "after agent callback")
+	if err != nil {
+		return nil, err
+	}
+	agentCfg := agent.Config{
+		Name:                 cfg.Name,
+		Description:          cfg.Description,
+		SubAgents:            subAgents,
+		BeforeAgentCallbacks: beforeAgentCallbacks,
+		AfterAgentCallbacks:  afterAgentCallbacks,
+	}
+
+	var a agent.Agent
+	switch class {
+	case SequentialAgent:
+		a, err = sequentialagent.New(sequentialagent.Config{AgentConfig: agentCfg})
+	case ParallelAgent:
+		a, err = parallelagent.New(parallelagent.Config{AgentConfig: agentCfg})
+	case LoopAgent:
+		a, err = loopagent.New(loopagent.Config{AgentConfig: agentCfg, MaxIterations: cfg.MaxIterations})
+	default:
+		var llmCfg llmagent.Config
+		if llmCfg, err = b.llmAgentConfig(f, cfg, agentCfg, llm); err != nil {
+			return nil, err
+		}
+		a, err = llmagent.New(llmCfg)
+	}
+	if err != nil {
+		return nil, &Error{File: f.path, Err: err}
+	}
+	return a, nil
+}
+
+func (b *builder) llmAgentConfig(f *file, cfg *agentConfig, agentCfg agent.Config, llm model.LLM) (llmagent.Config, error) {
+	llmCfg := llmagent.Config{
+		Name:                     agentCfg.Name,
+		Description:              agentCfg.Description,
+		SubAgents:                agentCfg.SubAgents,
+		BeforeAgentCallbacks:     agentCfg.BeforeAgentCallbacks,
+		AfterAgentCallbacks:      agentCfg.AfterAgentCallbacks,
+		Model:                    llm,
+		Instruction:              cfg.Instruction,
+		GlobalInstruction:        cfg.GlobalInstruction,
+		OutputKey:                cfg.OutputKey,
+		DisallowTransferToParent: cfg.DisallowTransferToParent,
+		DisallowTransferToPeers:  cfg.DisallowTransferToPeers,
+	}
+
+	switch llmagent.IncludeContents(cfg.IncludeContents) {
+	case "", llmagent.IncludeContentsDefault, llmagent.IncludeContentsNone:
+		llmCfg.IncludeContents = llmagent.IncludeContents(cfg.IncludeContents)
+	default:
+		return llmagent.Config{}, f.errorf("include_contents", "unknown value %q, want %s or %s",
+			cfg.IncludeContents, llmagent.IncludeContentsDefault, llmagent.IncludeContentsNone)
+	}
+
+	for i, ref := range cfg.Tools {
+		field := fmt.Sprintf("tools[%d].name", i)
+		if ref.Name == "" {
+			return llmagent.Config{}, f.errorf(field, "name is required")
+		}
+		if t, ok := b.reg.Tools[ref.Name]; ok {
+			llmCfg.Tools = append(llmCfg.Tools, t)
+		} else if ts, ok := b.reg.Toolsets[ref.Name]; ok {
+			llmCfg.Toolsets = append(llmCfg.Toolsets, ts)
+		} else {
+			return llmagent.Config{}, f.errorf(field, "unknown tool or toolset %q", ref.Name)
+		}
+	}
+
+	var err error
+	if llmCfg.BeforeModelCallbacks, err = lookup(f, "before_model_callbacks", cfg.BeforeModelCallbacks, b.reg.BeforeModelCallbacks, "before model callback"); err != nil {
+		return llmagent.Config{}, err
+	}
+	if llmCfg.AfterModelCallbacks, err = lookup(f, "after_model_callbacks", cfg.AfterModelCallbacks, b.reg.AfterModelCallbacks, "after model callback"); err != nil {
+		return llmagent.Config{}, err
+	}
+	if llmCfg.BeforeToolCallbacks, err = lookup(f, "before_tool_callbacks", cfg.BeforeToolCallbacks, b.reg.BeforeToolCallbacks, "before tool callback"); err != nil {
+		return llmagent.Config{}, err
+	}
+	if llmCfg.AfterToolCallbacks, err = lookup(f, "after_tool_callbacks", cfg.AfterToolCallbacks, b.reg.AfterToolCallbacks, "after tool callback"); err != nil {
+		return llmagent.Config{}, err
+	}
+	return llmCfg, nil
+}
+
+func (b *builder) model(f *file, name string) (model.LLM, error) {
+	if b.reg.Models == nil {
+		return nil, f.errorf("model", "the registry has no models")
+	}
+	llm, err := b.reg.Models(b.ctx, name)
+	if err != nil {
+		return nil, f.errorf("model", "failed to create model %q: %w", name, err)
+	}
+	return llm, nil
+}
+
+func (b *builder) subAgents(f *file, refs []subAgent, inherited model.LLM) ([]agent.Agent, error) {
+	var agents []agent.Agent
+	for i, ref := range refs {
+		field := fmt.Sprintf("sub_agents[%d]", i)
+		if (ref.ConfigPath == "") == (ref.Code == "") {
+			return nil, f.errorf(field, "exactly one of config_path or code is required")
+		}
+		if ref.Code != "" {
+			a, ok := b.reg.Agents[ref.Code]
+			if !ok {
+				return nil, f.errorf(field+".code", "unknown agent %q", ref.Code)
+			}
+			agents = append(agents, a)
+			continue
+		}
+
+		field += ".config_path"
+		path := ref.ConfigPath
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(f.path), path)
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, f.errorf(field, "%w", err)
+		}
+		if slices.Contains(b.loading, abs) {
+			return nil, f.errorf(field, "%s references itself", ref.ConfigPath)
+		}
+		a, err := b.load(path, inherited)
+		if err != nil {
+			var cfgErr *Error
+			if errors.As(err, &cfgErr) {
+				return nil, err
+			}
+			return nil, f.errorf(field, "%w", err)
+		}
+		agents = append(agents, a)
+	}
+	return agents, nil
+}
+
+// lookup resolves the references of the field to the values of the registry.
+func lookup[T any](f *file, field string, refs []reference, values map[string]T, kind string) ([]T, error) {
+	var resolved []T
+	for i, ref := range refs {
+		name := fmt.Sprintf("%s[%d].name", field, i)
+		if ref.Name == "" {
+			return nil, f.errorf(name, "name is required")
+		}
+		v, ok := values[ref.Name]
+		if !ok {
+			return nil, f.errorf(name, "unknown %s %q", kind, ref.Name)
+		}
+		resolved = append(resolved, v)
+	}
+	return resolved, nil
+}
+
+// llmAgentField returns the first set field that only an LlmAgent supports,
+// or "".
+func llmAgentField(cfg *agentConfig) string {
+	switch {
+	case cfg.Model != "":
+		return "model"
+	case cfg.Instruction != "":
+		return "instruction"
+	case cfg.GlobalInstruction != "":
+		return "global_instruction"
+	case cfg.OutputKey != "":
+		return "output_key"
+	case cfg.IncludeContents != "":
+		return "include_contents"
+	case cfg.DisallowTransferToParent:
+		return "disallow_transfer_to_parent"
+	case cfg.DisallowTransferToPeers:
+		return "disallow_transfer_to_peers"
+	case len(cfg.Tools) > 0:
+		return "tools"
+	case len(cfg.BeforeModelCallbacks) > 0:
+		return "before_model_callbacks"
+	case len(cfg.AfterModelCallbacks) > 0:
+		return "after_model_callbacks"
+	case len(cfg.BeforeToolCallbacks) > 0:
+		return "before_tool_callbacks"
+	case len(cfg.AfterToolCallbacks) > 0:
+		return "after_tool_callbacks"
+	}
+	return ""
+}
@@ -69,6 +69,9 @@ type InvocationContext interface {
 	// Memory is scoped to sessions of the current user_id.
 	Memory() Memory
 
+	// Credentials of the current user.
+	Credentials() Credentials
+
 	// Session of the current invocation context.
 	Session() session.Session
 
@@ -15,6 +15,7 @@
 package llmagent
 
 import (
+	"encoding/json"
 	"fmt"
 	"iter"
 	"strings"
@@ -22,10 +23,12 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
 	agentinternal "google.golang.org/adk/internal/agent"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
 )
@@ -62,8 +65,15 @@ func New(cfg Config) (agent.Agent, error) {
 		onToolErrorCallback = append(onToolErrorCallback, llminternal.OnToolErrorCallback(c))
 	}
 
+	requestProcessors, responseProcessors, err := processorFuncs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	a := &llmAgent{
 		model:                 cfg.Model,
+		requestProcessors:     requestProcessors,
+		responseProcessors:    responseProcessors,
 		beforeModelCallbacks:  beforeModelCallbacks,
 		afterModelCallbacks:   afterModelCallbacks,
 		onModelErrorCallbacks: onModelErrorCallbacks,
@@ -83,6 +93,9 @@ func New(cfg Config) (agent.Agent, error) {
 			DisallowTransferToPeers:  cfg.DisallowTransferToPeers,
 			InputSchema:              cfg.InputSchema,
 			OutputSchema:             cfg.OutputSchema,
+			MaxOutputSchemaRetries:   cfg.MaxOutputSchemaRetries,
+			MaxContinuations:         cfg.MaxContinuations,
+			ErrorOnTruncation:        cfg.ErrorOnTruncation,
 			// TODO: internal type for includeContents
 			IncludeContents:           string(cfg.IncludeContents),
 			Instruction:               cfg.Instruction,
@@ -90,6 +103,9 @@ func New(cfg Config) (agent.Agent, error) {
 			GlobalInstruction:         cfg.GlobalInstruction,
 			GlobalInstructionProvider: llminternal.InstructionProvider(cfg.GlobalInstructionProvider),
 			OutputKey:                 cfg.OutputKey,
+			TokenBudget:               (*llminternal.TokenBudget)(cfg.TokenBudget),
+			Planner:                   cfg.Planner,
+			CodeExecutor:              cfg.CodeExecutor,
 		},
 	}
 
@@ -233,9 +249,32 @@ type Config struct {
 	InputSchema *genai.Schema
 	// The output schema when agent replies.
 	//
-	// NOTE: when this is set, agent can only reply and cannot use any tools,
-	// such as function tools, RAGs, agent transfer, etc.
+	// It can be combined with Tools and Toolsets. When the model can't use
+	// a response schema together with tools, the agent is given a
+	// set_model_response tool taking the schema as parameters, and is
+	// instructed to call it with its final answer, after using any other
+	// tools. The arguments of the call become the final response of the
+	// agent.
+	//
+	// The final response of the agent is validated against the schema, and
+	// stored under OutputKey as a decoded JSON object. Use [DecodeOutput] or
+	// [OutputFromState] to decode it into a Go value.
 	OutputSchema *genai.Schema
+	// MaxOutputSchemaRetries is the number of times the model is re-prompted
+	// with the validation error when its final response doesn't match
+	// OutputSchema. Once exhausted, the agent fails with [ErrInvalidOutput].
+	MaxOutputSchemaRetries int
+
+	// MaxContinuations is the number of times the model is asked to continue
+	// a final response truncated because it reached the output token limit,
+	// i.e. with the MAX_TOKENS finish reason. The continuations are stitched
+	// into one final response event. Once exhausted, the truncated response
+	// is the final response of the agent.
+	MaxContinuations int
+	// ErrorOnTruncation makes the agent fail with an [*OutputTruncatedError]
+	// instead when the final response is still truncated after
+	// MaxContinuations continuations.
+	ErrorOnTruncation bool
 
 	// Callbacks are executed in the order they are provided.
 	// If a callback returns result/error, then the execution of the callback
@@ -259,8 +298,94 @@ type Config struct {
 	// - Extracts agent reply for later use, such as in tools, callbacks, etc.
 	// - Connects agents to coordinate with each other.
 	OutputKey string
+
+	// TokenBudget limits the number of input tokens of the requests sent to
+	// the model. If nil, requests are sent as built from the session.
+	TokenBudget *TokenBudget
+
+	// Planner lets the agent plan its actions before executing them, e.g.
+	// [planner.BuiltIn] to use the thinking features of the model, or
+	// [planner.PlanReAct] to make the model write down its plan and reasoning.
+	// Planning parts of the responses are marked as thoughts.
+	// If nil, no planning is done.
+	Planner planner.Planner
+
+	// CodeExecutor runs the code written by the model, e.g.
+	// [codeexecutor.BuiltIn] to let Gemini models run it themselves, or the
+	// executors of [codeexecutor.NewLocal] and [codeexecutor.NewContainer].
+	//
+	// Unless the model runs the code itself, the first code block of each
+	// model response, fenced with ```python or ```tool_code, is executed and
+	// its result is sent back to the model, until the model responds without
+	// code or fails twice in a row. CSV files sent by the user are made
+	// available to the code, and the files created by the code are saved as
+	// artifacts.
+	// If nil, no code is executed.
+	CodeExecutor codeexecutor.Executor
+
+	// RequestProcessors build the request of each model call, in order.
+	// If nil, [DefaultRequestProcessors] are used. To add, replace or reorder
+	// processors, modify the slice returned by DefaultRequestProcessors, e.g.
+	// insert a processor adding retrieved context after ContentsProcessor.
+	// The names of the processors must be unique.
+	RequestProcessors []RequestProcessor
+	// ResponseProcessors process each response of the model, in order.
+	// If nil, [DefaultResponseProcessors] are used.
+	ResponseProcessors []ResponseProcessor
 }
 
+// TokenBudget limits the number of input tokens of the model requests of an
+// agent, so long sessions don't exceed the context window of the model.
+//
+// Tokens are counted by the model if it implements [model.TokenCounter], and
+// estimated with [model.EstimateTokens] otherwise. To avoid a call to the
+// model before each step, they are only counted once the estimate reaches 80%
+// of MaxTokens. When a request is over budget, the following is trimmed in
+// order, until it fits:
+//   - inline and file data of the history, oldest first, replaced by a short
+//     text placeholder;
+//   - function responses larger than MaxFunctionResponseTokens, oldest first,
+//     replaced by a truncated copy;
+//   - the oldest turns of the history.
+//
+// The latest content is never dropped. When anything is trimmed, a partial
+// event with a [TokenBudgetMetadataKey] entry in its CustomMetadata is
+// emitted before the model is called. It isn't saved in the session. If the
+// request still doesn't fit, the agent fails with [ErrTokenBudgetExceeded].
+type TokenBudget struct {
+	// MaxTokens is the maximum number of input tokens of a request,
+	// including the system instruction and tool declarations.
+	MaxTokens int
+	// MaxFunctionResponseTokens is the size above which function responses
+	// are truncated when the request is over budget.
+	// If zero, MaxTokens/4 is used.
+	MaxFunctionResponseTokens int
+}
+
+// TokenBudgetMetadataKey is the key of the CustomMetadata entry of the event
+// emitted when a request is trimmed to fit the [TokenBudget]. The value is a
+// map with the "max_tokens", "tokens_before", "tokens_after",
+// "dropped_contents", "truncated_function_responses" and "omitted_blobs"
+// entries.
+const TokenBudgetMetadataKey = llminternal.TokenBudgetMetadataKey
+
+// ErrInvalidOutput is returned when the final response of the model doesn't
+// match the OutputSchema of the agent after MaxOutputSchemaRetries re-prompts.
+var ErrInvalidOutput = llminternal.ErrInvalidOutput
+
+// OutputTruncatedError is returned when the final response of the model is
+// truncated at the output token limit after MaxContinuations continuations
+// and ErrorOnTruncation is set.
+type OutputTruncatedError = llminternal.OutputTruncatedError
+
+// ErrNoFinalResponse is returned when the model stream ends with a partial
+// response instead of a final one, e.g. because the connection dropped.
+var ErrNoFinalResponse = llminternal.ErrNoFinalResponse
+
+// ErrTokenBudgetExceeded is returned when a request doesn't fit the
+// [TokenBudget] even after trimming the conversation history.
+var ErrTokenBudgetExceeded = llminternal.ErrTokenBudgetExceeded
+
 // BeforeModelCallback that is called before sending a request to the model.
 //
 // If it returns non-nil LLMResponse or error, the actual model call is skipped
@@ -331,6 +456,9 @@ type llmAgent struct {
 
 	inputSchema  *genai.Schema
 	outputSchema *genai.Schema
+
+	requestProcessors  []func(ctx agent.InvocationContext, req *model.LLMRequest) error
+	responseProcessors []func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error
 }
 
 type agentState = agentinternal.State
@@ -340,6 +468,7 @@ func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, er
 	ctx = icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
 		Artifacts:   ctx.Artifacts(),
 		Memory:      ctx.Memory(),
+		Credentials: ctx.Credentials(),
 		Session:     ctx.Session(),
 		Branch:      ctx.Branch(),
 		Agent:       a,
@@ -349,8 +478,8 @@ func (a *llmAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, er
 
 	f := &llminternal.Flow{
 		Model:                 a.model,
-		RequestProcessors:     llminternal.DefaultRequestProcessors,
-		ResponseProcessors:    llminternal.DefaultResponseProcessors,
+		RequestProcessors:     a.requestProcessors,
+		ResponseProcessors:    a.responseProcessors,
 		BeforeModelCallbacks:  a.beforeModelCallbacks,
 		AfterModelCallbacks:   a.afterModelCallbacks,
 		OnModelErrorCallbacks: a.onModelErrorCallbacks,
@@ -388,7 +517,7 @@ func (a *llmAgent) maybeSaveOutputToState(event *session.Event) {
 		}
 		result := sb.String()
 
-		// TODO: add output schema validation and unmarshalling
+		var output any = result
 		if a.OutputSchema != nil {
 			// If the result from the final chunk is just whitespace or empty,
 			// it means this is an empty final chunk of a stream.
@@ -396,13 +525,18 @@ func (a *llmAgent) maybeSaveOutputToState(event *session.Event) {
 			if strings.TrimSpace(result) == "" {
 				return
 			}
+			// The output was validated against the schema by the flow.
+			var decoded any
+			if err := json.Unmarshal([]byte(result), &decoded); err == nil {
+				output = decoded
+			}
 		}
 
 		if event.Actions.StateDelta == nil {
 			event.Actions.StateDelta = make(map[string]any)
 		}
 
-		event.Actions.StateDelta[a.OutputKey] = result
+		event.Actions.StateDelta[a.OutputKey] = output
 	}
 }
 
@@ -105,7 +105,24 @@ func TestLlmAgent_MaybeSaveOutputToState(t *testing.T) {
 			event:          createTestEvent("testagent", "Test response", true),
 			wantStateDelta: map[string]any{},
 		},
-		// TODO tests with OutputSchema
+		{
+			name: "decodes output with schema",
+			agentConfig: Config{Name: "test_agent", OutputKey: "result", OutputSchema: &genai.Schema{
+				Type: genai.TypeObject,
+				Properties: map[string]*genai.Schema{
+					"message":    {Type: genai.TypeString},
+					"confidence": {Type: genai.TypeNumber},
+				},
+			}},
+			event:          createTestEvent("test_agent", `{"message": "hi", "confidence": 0.5}`, true),
+			wantStateDelta: map[string]any{"result": map[string]any{"message": "hi", "confidence": 0.5}},
+		},
+		{
+			name:           "skips empty final chunk with schema",
+			agentConfig:    Config{Name: "test_agent", OutputKey: "result", OutputSchema: &genai.Schema{Type: genai.TypeObject}},
+			event:          createTestEvent("test_agent", "  ", true),
+			wantStateDelta: map[string]any{},
+		},
 	}
 
 	// Iterate over the test cases
@@ -15,25 +15,35 @@
 package llmagent_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"iter"
 	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/codeexecutor"
 	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/model/modeltest"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/agenttool"
 	"google.golang.org/adk/tool/functiontool"
 )
 
@@ -1111,3 +1121,716 @@ type roundTripperFunc func(*http.Request) (*http.Response, error)
 func (fn roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return fn(req)
 }
+
+func TestTokenBudget(t *testing.T) {
+	llm := modeltest.New(modeltest.Text("ok"), modeltest.Text("ok2"), modeltest.Text("ok3"))
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "budget_agent",
+		Model:       llm,
+		TokenBudget: &llmagent.TokenBudget{MaxTokens: 30},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	// The first request fits the budget.
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", strings.Repeat("a", 80))); err != nil {
+		t.Fatal(err)
+	}
+	// The second one doesn't, the first turn is dropped.
+	var events []*session.Event
+	for ev, err := range runner.Run(t, "session", "q2") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, ev)
+	}
+	wantWarning := map[string]any{
+		"max_tokens":                   30,
+		"tokens_before":                34,
+		"tokens_after":                 5,
+		"dropped_contents":             2,
+		"truncated_function_responses": 0,
+		"omitted_blobs":                0,
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want the warning and the response", len(events))
+	}
+	if diff := cmp.Diff(wantWarning, events[0].CustomMetadata[llmagent.TokenBudgetMetadataKey]); diff != "" {
+		t.Errorf("warning metadata mismatch (-want +got):\n%s", diff)
+	}
+	if !events[0].Partial {
+		t.Error("warning is not partial, want it kept out of the session")
+	}
+	if diff := cmp.Diff(genai.Text("q2"), llm.Requests()[1].Contents); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+
+	// A single message larger than the budget can't be trimmed.
+	_, err = testutil.CollectEvents(runner.Run(t, "session", strings.Repeat("a", 400)))
+	if !errors.Is(err, llmagent.ErrTokenBudgetExceeded) {
+		t.Errorf("Run() error = %v, want %v", err, llmagent.ErrTokenBudgetExceeded)
+	}
+}
+
+// countingModel counts the calls to CountTokens.
+type countingModel struct {
+	*modeltest.Model
+	counts int
+}
+
+func (m *countingModel) CountTokens(_ context.Context, req *model.LLMRequest) (int, error) {
+	m.counts++
+	return model.EstimateTokens(req), nil
+}
+
+func TestTokenBudget_CountTokens(t *testing.T) {
+	llm := &countingModel{Model: modeltest.New(modeltest.Text("ok"), modeltest.Text("ok2"))}
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "budget_agent",
+		Model:       llm,
+		TokenBudget: &llmagent.TokenBudget{MaxTokens: 100},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	// The estimate is well below the budget, the tokens aren't counted.
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "hi")); err != nil {
+		t.Fatal(err)
+	}
+	if llm.counts != 0 {
+		t.Errorf("CountTokens calls = %d, want 0", llm.counts)
+	}
+	// The estimate is close to the budget, the tokens are counted.
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", strings.Repeat("a", 320))); err != nil {
+		t.Fatal(err)
+	}
+	if llm.counts != 1 {
+		t.Errorf("CountTokens calls = %d, want 1", llm.counts)
+	}
+}
+
+func TestPlanner(t *testing.T) {
+	llm := modeltest.New(
+		modeltest.Text("/*PLANNING*/ 1. Answer from memory. /*FINAL_ANSWER*/ Paris."),
+		modeltest.Text("/*FINAL_ANSWER*/ Berlin."),
+	)
+	a, err := llmagent.New(llmagent.Config{
+		Name:    "planning_agent",
+		Model:   llm,
+		Planner: planner.PlanReAct{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "Capital of France?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantParts := []*genai.Part{
+		{Text: "/*PLANNING*/ 1. Answer from memory. /*FINAL_ANSWER*/", Thought: true},
+		{Text: " Paris."},
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if diff := cmp.Diff(wantParts, events[0].Content.Parts); diff != "" {
+		t.Errorf("event parts mismatch (-want +got):\n%s", diff)
+	}
+	if !strings.Contains(llm.Requests()[0].Config.SystemInstruction.Parts[0].Text, planner.FinalAnswerTag) {
+		t.Errorf("planning instruction missing from the system instruction")
+	}
+
+	// The thoughts of the previous turn are not sent back to the model.
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "Capital of Germany?")); err != nil {
+		t.Fatal(err)
+	}
+	wantContents := []*genai.Content{
+		genai.NewContentFromText("Capital of France?", genai.RoleUser),
+		genai.NewContentFromText(" Paris.", genai.RoleModel),
+		genai.NewContentFromText("Capital of Germany?", genai.RoleUser),
+	}
+	if diff := cmp.Diff(wantContents, llm.Requests()[1].Contents); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type fakeCodeExecutor struct {
+	inputs  []*codeexecutor.Input
+	results []*codeexecutor.Result
+}
+
+func (e *fakeCodeExecutor) Execute(ctx context.Context, input *codeexecutor.Input) (*codeexecutor.Result, error) {
+	e.inputs = append(e.inputs, input)
+	result := e.results[0]
+	e.results = e.results[1:]
+	return result, nil
+}
+
+func TestCodeExecutor(t *testing.T) {
+	testCases := []struct {
+		name         string
+		turns        []*modeltest.Turn
+		results      []*codeexecutor.Result
+		wantCode     []string
+		wantEvents   []*genai.Content
+		wantContents []*genai.Content
+	}{
+		{
+			name: "executes code",
+			turns: []*modeltest.Turn{
+				modeltest.Text("Let me compute.\n```python\nprint(1 + 1)\n```\nignored"),
+				modeltest.Text("It's 2."),
+			},
+			results:  []*codeexecutor.Result{{Stdout: "2\n"}},
+			wantCode: []string{"print(1 + 1)"},
+			wantEvents: []*genai.Content{
+				genai.NewContentFromParts([]*genai.Part{
+					genai.NewPartFromText("Let me compute.\n"),
+					genai.NewPartFromExecutableCode("print(1 + 1)", genai.LanguagePython),
+				}, genai.RoleModel),
+				genai.NewContentFromCodeExecutionResult(genai.OutcomeOK, "Code execution result:\n2\n\n", genai.RoleModel),
+				genai.NewContentFromText("It's 2.", genai.RoleModel),
+			},
+			wantContents: []*genai.Content{
+				genai.NewContentFromText("1 + 1?", genai.RoleUser),
+				genai.NewContentFromParts([]*genai.Part{
+					genai.NewPartFromText("Let me compute.\n"),
+					genai.NewPartFromText("```tool_code\nprint(1 + 1)\n```"),
+				}, genai.RoleModel),
+				genai.NewContentFromText("```tool_output\nCode execution result:\n2\n\n\n```", genai.RoleUser),
+			},
+		},
+		{
+			name: "stops after two errors",
+			turns: []*modeltest.Turn{
+				modeltest.Text("```python\nprint(1 +)\n```"),
+				modeltest.Text("```python\nprint(1 +)\n```"),
+				modeltest.Text("```python\nprint(1 +)\n```"),
+			},
+			results: []*codeexecutor.Result{
+				{Stderr: "SyntaxError"},
+				{Stderr: "SyntaxError"},
+			},
+			wantCode: []string{"print(1 +)", "print(1 +)"},
+			wantEvents: []*genai.Content{
+				genai.NewContentFromExecutableCode("print(1 +)", genai.LanguagePython, genai.RoleModel),
+				genai.NewContentFromCodeExecutionResult(genai.OutcomeFailed, "SyntaxError", genai.RoleModel),
+				genai.NewContentFromExecutableCode("print(1 +)", genai.LanguagePython, genai.RoleModel),
+				genai.NewContentFromCodeExecutionResult(genai.OutcomeFailed, "SyntaxError", genai.RoleModel),
+				genai.NewContentFromText("```python\nprint(1 +)\n```", genai.RoleModel),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			llm := modeltest.New(tc.turns...)
+			executor := &fakeCodeExecutor{results: tc.results}
+			a, err := llmagent.New(llmagent.Config{
+				Name:         "code_agent",
+				Model:        llm,
+				CodeExecutor: executor,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+			events, err := testutil.CollectEvents(runner.Run(t, "session", "1 + 1?"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotEvents []*genai.Content
+			for _, ev := range events {
+				gotEvents = append(gotEvents, ev.Content)
+			}
+			if diff := cmp.Diff(tc.wantEvents, gotEvents); diff != "" {
+				t.Errorf("event contents mismatch (-want +got):\n%s", diff)
+			}
+			var gotCode []string
+			for _, input := range executor.inputs {
+				gotCode = append(gotCode, input.Code)
+			}
+			if diff := cmp.Diff(tc.wantCode, gotCode); diff != "" {
+				t.Errorf("executed code mismatch (-want +got):\n%s", diff)
+			}
+			if tc.wantContents != nil {
+				if diff := cmp.Diff(tc.wantContents, llm.Requests()[1].Contents); diff != "" {
+					t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestToolAuthentication(t *testing.T) {
+	authConfig := &auth.Config{
+		Scheme: &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "bearer"},
+	}
+	var tokens []string
+	getData, err := functiontool.New(functiontool.Config{
+		Name:        "get_data",
+		Description: "returns the data of the user",
+	}, func(ctx tool.Context, _ struct{}) (map[string]any, error) {
+		cred, err := ctx.Credential(authConfig)
+		if err != nil {
+			return nil, err
+		}
+		if cred == nil {
+			return map[string]any{"status": "pending"}, ctx.RequestCredential(authConfig)
+		}
+		tokens = append(tokens, cred.HTTP.Credentials.Token)
+		return map[string]any{"data": 42}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	llm := modeltest.New(
+		modeltest.FunctionCall("get_data", nil),
+		modeltest.Text("The data is 42."),
+		modeltest.FunctionCall("get_data", nil),
+		modeltest.Text("Still 42."),
+	)
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "data_agent",
+		Model: llm,
+		Tools: []tool.Tool{getData},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "get my data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want the function call, response and auth events", len(events))
+	}
+	if got, want := events[1].Actions.RequestedAuthConfigs, 1; len(got) != want {
+		t.Errorf("requested auth configs = %v, want %d", got, want)
+	}
+	authEvent := events[2]
+	if len(authEvent.LongRunningToolIDs) != 1 || !authEvent.IsFinalResponse() {
+		t.Fatalf("auth event is not a final long-running call: %+v", authEvent)
+	}
+	call := authEvent.Content.Parts[0].FunctionCall
+	if call.Name != auth.RequestCredentialFunctionName || call.Args["functionCallId"] != events[0].Content.Parts[0].FunctionCall.ID {
+		t.Fatalf("unexpected credential request: %+v", call)
+	}
+
+	// The user provides the token.
+	response := map[string]any{
+		"authScheme": map[string]any{"type": "http", "scheme": "bearer"},
+		"exchangedAuthCredential": map[string]any{
+			"authType": "http",
+			"http":     map[string]any{"scheme": "bearer", "credentials": map[string]any{"token": "secret"}},
+		},
+		"credentialKey": call.Args["authConfig"].(map[string]any)["credentialKey"],
+	}
+	reply := genai.NewContentFromFunctionResponse(call.Name, response, genai.RoleUser)
+	reply.Parts[0].FunctionResponse.ID = call.ID
+	events, err = testutil.CollectEvents(runner.RunContent(t, "session", reply))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*genai.Content
+	for _, ev := range events {
+		got = append(got, ev.Content)
+	}
+	resumed := genai.NewContentFromFunctionResponse("get_data", map[string]any{"data": float64(42)}, genai.RoleUser)
+	want := []*genai.Content{
+		resumed,
+		genai.NewContentFromText("The data is 42.", genai.RoleModel),
+	}
+	ignoreIDs := cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID")
+	if diff := cmp.Diff(want, got, ignoreIDs); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+	// The model only sees the response of the resumed call.
+	wantContents := []*genai.Content{
+		genai.NewContentFromText("get my data", genai.RoleUser),
+		genai.NewContentFromFunctionCall("get_data", nil, genai.RoleModel),
+		resumed,
+	}
+	if diff := cmp.Diff(wantContents, llm.Requests()[1].Contents, ignoreIDs); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+
+	// The stored credential is reused.
+	if _, err := testutil.CollectEvents(runner.Run(t, "session", "again")); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"secret", "secret"}, tokens); diff != "" {
+		t.Errorf("tokens mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestToolAuthentication_OAuth2(t *testing.T) {
+	// The token endpoint only accepts the client secret of the tool.
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() failed: %v", err)
+		}
+		user, secret, ok := r.BasicAuth()
+		if !ok {
+			user, secret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+		}
+		if user != "client" || secret != "tool-secret" || r.Form.Get("code") != "code" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "user-token", "token_type": "Bearer"})
+	}))
+	defer tokenSrv.Close()
+
+	authConfig := &auth.Config{
+		Scheme: &auth.Scheme{
+			Type: auth.SchemeOAuth2,
+			Flows: &auth.OAuthFlows{AuthorizationCode: &auth.OAuthFlow{
+				AuthorizationURL: "https://example.com/authorize",
+				TokenURL:         tokenSrv.URL,
+			}},
+		},
+		RawCredential: &auth.Credential{
+			Type: auth.CredentialOAuth2,
+			OAuth2: &auth.OAuth2Auth{
+				ClientID:     "client",
+				ClientSecret: "tool-secret",
+				RedirectURI:  "https://example.com/callback",
+			},
+		},
+	}
+	var tokens []string
+	getData, err := functiontool.New(functiontool.Config{
+		Name:        "get_data",
+		Description: "returns the data of the user",
+	}, func(ctx tool.Context, _ struct{}) (map[string]any, error) {
+		cred, err := ctx.Credential(authConfig)
+		if err != nil {
+			return nil, err
+		}
+		if cred == nil {
+			return map[string]any{"status": "pending"}, ctx.RequestCredential(authConfig)
+		}
+		tokens = append(tokens, cred.OAuth2.AccessToken)
+		return map[string]any{"data": 42}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "data_agent",
+		Model: modeltest.New(modeltest.FunctionCall("get_data", nil), modeltest.Text("The data is 42.")),
+		Tools: []tool.Tool{getData},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "get my data"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want the function call, response and auth events", len(events))
+	}
+	for _, ev := range events {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "tool-secret") {
+			t.Errorf("event %s contains the client secret", data)
+		}
+	}
+	call := events[2].Content.Parts[0].FunctionCall
+
+	// The client returns the requested config with the authorization
+	// response.
+	response, _ := call.Args["authConfig"].(map[string]any)
+	exchanged, _ := response["exchangedAuthCredential"].(map[string]any)
+	oauth2, _ := exchanged["oauth2"].(map[string]any)
+	if oauth2 == nil {
+		t.Fatalf("no OAuth2 credential in the request %v", call.Args)
+	}
+	oauth2["authResponseUri"] = fmt.Sprintf("https://example.com/callback?code=code&state=%s", oauth2["state"])
+	reply := genai.NewContentFromFunctionResponse(call.Name, response, genai.RoleUser)
+	reply.Parts[0].FunctionResponse.ID = call.ID
+	if _, err := testutil.CollectEvents(runner.RunContent(t, "session", reply)); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"user-token"}, tokens); diff != "" {
+		t.Errorf("tokens mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRunConfigLimits(t *testing.T) {
+	newTool := func(delay time.Duration) tool.Tool {
+		t.Helper()
+		lookup, err := functiontool.New(functiontool.Config{
+			Name:        "lookup",
+			Description: "looks up the next clue",
+		}, func(ctx tool.Context, _ struct{}) (map[string]any, error) {
+			time.Sleep(delay)
+			return map[string]any{"clue": "keep looking"}, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lookup
+	}
+	loopingModel := func() model.LLM {
+		return modeltest.New(
+			modeltest.FunctionCall("lookup", nil),
+			modeltest.FunctionCall("lookup", nil),
+			modeltest.FunctionCall("lookup", nil),
+			modeltest.Text("found it"),
+		)
+	}
+	newAgent := func(t *testing.T, name string, llm model.LLM, tools ...tool.Tool) agent.Agent {
+		t.Helper()
+		a, err := llmagent.New(llmagent.Config{Name: name, Model: llm, Tools: tools})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return a
+	}
+
+	tests := []struct {
+		name       string
+		agent      func(t *testing.T) agent.Agent
+		cfg        agent.RunConfig
+		wantEvents int
+		wantAuthor string
+		wantErr    *agent.LimitExceededError
+	}{
+		{
+			name:       "no limits",
+			agent:      func(t *testing.T) agent.Agent { return newAgent(t, "detective", loopingModel(), newTool(0)) },
+			wantEvents: 7,
+		},
+		{
+			name:       "max LLM calls",
+			agent:      func(t *testing.T) agent.Agent { return newAgent(t, "detective", loopingModel(), newTool(0)) },
+			cfg:        agent.RunConfig{MaxLLMCalls: 2},
+			wantEvents: 5,
+			wantAuthor: "detective",
+			wantErr:    &agent.LimitExceededError{Limit: agent.LimitLLMCalls, Value: 2},
+		},
+		{
+			name:       "max tool calls",
+			agent:      func(t *testing.T) agent.Agent { return newAgent(t, "detective", loopingModel(), newTool(0)) },
+			cfg:        agent.RunConfig{MaxToolCalls: 1},
+			wantEvents: 4,
+			wantAuthor: "detective",
+			wantErr:    &agent.LimitExceededError{Limit: agent.LimitToolCalls, Value: 1},
+		},
+		{
+			name: "max duration",
+			agent: func(t *testing.T) agent.Agent {
+				return newAgent(t, "detective", loopingModel(), newTool(50*time.Millisecond))
+			},
+			cfg:        agent.RunConfig{MaxDuration: 10 * time.Millisecond},
+			wantEvents: 3,
+			wantAuthor: "detective",
+			wantErr:    &agent.LimitExceededError{Limit: agent.LimitDuration, Value: 10 * time.Millisecond},
+		},
+		{
+			name: "shared with agent tools",
+			agent: func(t *testing.T) agent.Agent {
+				helper := newAgent(t, "helper", loopingModel(), newTool(0))
+				llm := modeltest.New(modeltest.FunctionCall("helper", map[string]any{"request": "find it"}), modeltest.Text("done"))
+				return newAgent(t, "detective", llm, agenttool.New(helper, nil))
+			},
+			cfg: agent.RunConfig{MaxLLMCalls: 2},
+			// The agent tool fails, and the agent stops before its next step.
+			wantEvents: 3,
+			wantAuthor: "detective",
+			wantErr:    &agent.LimitExceededError{Limit: agent.LimitLLMCalls, Value: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := testutil.NewTestAgentRunner(t, tt.agent(t))
+			var events []*session.Event
+			var err error
+			for ev, evErr := range runner.RunContentWithConfig(t, "session", genai.NewContentFromText("find the culprit", genai.RoleUser), tt.cfg) {
+				if evErr != nil {
+					err = evErr
+					break
+				}
+				events = append(events, ev)
+			}
+
+			if len(events) != tt.wantEvents {
+				t.Fatalf("got %d events, want %d", len(events), tt.wantEvents)
+			}
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var limitErr *agent.LimitExceededError
+			if !errors.As(err, &limitErr) {
+				t.Fatalf("got error %v, want a LimitExceededError", err)
+			}
+			if diff := cmp.Diff(tt.wantErr, limitErr); diff != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", diff)
+			}
+			last := events[len(events)-1]
+			if last.ErrorCode != agent.LimitExceededErrorCode || last.ErrorMessage != tt.wantErr.Error() || last.Author != tt.wantAuthor {
+				t.Errorf("got last event %+v, want a terminal event of %q", last, tt.wantAuthor)
+			}
+		})
+	}
+}
+
+func TestMaxContinuations(t *testing.T) {
+	truncated := func(text string) *modeltest.Turn {
+		return modeltest.Response(&model.LLMResponse{
+			Content:      genai.NewContentFromText(text, genai.RoleModel),
+			FinishReason: genai.FinishReasonMaxTokens,
+		})
+	}
+	tests := []struct {
+		name             string
+		maxContinuations int
+		errorOnTrunc     bool
+		streaming        agent.StreamingMode
+		turns            []*modeltest.Turn
+		want             []string
+		wantErr          *llmagent.OutputTruncatedError
+	}{
+		{
+			name:  "complete response",
+			turns: []*modeltest.Turn{modeltest.Text("The butler did it.")},
+			want:  []string{"The butler did it."},
+		},
+		{
+			name:             "continued",
+			maxContinuations: 2,
+			turns:            []*modeltest.Turn{truncated("The butler"), truncated(" did it"), modeltest.Text(" with the candlestick.")},
+			want:             []string{"The butler did it with the candlestick."},
+		},
+		{
+			name:             "continued while streaming",
+			maxContinuations: 1,
+			streaming:        agent.StreamingModeSSE,
+			turns:            []*modeltest.Turn{truncated("The butler").Stream("The ", "butler"), modeltest.Text(" did it.").Stream(" did it.")},
+			want:             []string{"The ", "butler", " did it.", "The butler did it."},
+		},
+		{
+			name:             "continuations exhausted",
+			maxContinuations: 1,
+			turns:            []*modeltest.Turn{truncated("The butler"), truncated(" did")},
+			want:             []string{"The butler did"},
+		},
+		{
+			name:             "continuations exhausted with error",
+			maxContinuations: 1,
+			errorOnTrunc:     true,
+			turns:            []*modeltest.Turn{truncated("The butler"), truncated(" did")},
+			wantErr: &llmagent.OutputTruncatedError{
+				Response: &model.LLMResponse{
+					Content:      genai.NewContentFromText("The butler did", genai.RoleModel),
+					FinishReason: genai.FinishReasonMaxTokens,
+				},
+				Continuations: 1,
+			},
+		},
+		{
+			name:  "no continuations",
+			turns: []*modeltest.Turn{truncated("The butler")},
+			want:  []string{"The butler"},
+		},
+		{
+			name:         "no continuations with error",
+			errorOnTrunc: true,
+			turns:        []*modeltest.Turn{truncated("The butler")},
+			wantErr:      &llmagent.OutputTruncatedError{Response: &model.LLMResponse{Content: genai.NewContentFromText("The butler", genai.RoleModel), FinishReason: genai.FinishReasonMaxTokens}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := modeltest.New(tt.turns...)
+			a, err := llmagent.New(llmagent.Config{
+				Name:              "detective",
+				Model:             llm,
+				MaxContinuations:  tt.maxContinuations,
+				ErrorOnTruncation: tt.errorOnTrunc,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			var got []string
+			for ev, err := range runner.RunContentWithConfig(t, "session", genai.NewContentFromText("who did it?", genai.RoleUser), agent.RunConfig{StreamingMode: tt.streaming}) {
+				if err != nil {
+					var truncErr *llmagent.OutputTruncatedError
+					if !errors.As(err, &truncErr) {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					if diff := cmp.Diff(tt.wantErr, truncErr); diff != "" {
+						t.Errorf("error mismatch (-want +got):\n%s", diff)
+					}
+					return
+				}
+				got = append(got, ev.Content.Parts[0].Text)
+			}
+			if tt.wantErr != nil {
+				t.Fatalf("got no error, want %v", tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+			if pending := llm.Pending(); pending != 0 {
+				t.Errorf("%d turns not used", pending)
+			}
+			// The model is asked to continue its previous response.
+			for _, req := range llm.Requests()[1:] {
+				n := len(req.Contents)
+				if req.Contents[n-2].Role != genai.RoleModel || !strings.Contains(req.Contents[n-1].Parts[0].Text, "output token limit") {
+					t.Errorf("continuation request ends with %v, %v", req.Contents[n-2], req.Contents[n-1])
+				}
+			}
+		})
+	}
+}
+
+func TestNoFinalResponse(t *testing.T) {
+	for _, streaming := range []agent.StreamingMode{agent.StreamingModeNone, agent.StreamingModeSSE} {
+		t.Run(string(streaming), func(t *testing.T) {
+			// The stream ends on a partial response, without a finish reason.
+			llm := modeltest.New(modeltest.Response(&model.LLMResponse{
+				Content: genai.NewContentFromText("The butler", genai.RoleModel),
+				Partial: true,
+			}))
+			a, err := llmagent.New(llmagent.Config{Name: "detective", Model: llm})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			_, err = testutil.CollectEvents(runner.RunContentWithConfig(t, "session", genai.NewContentFromText("who did it?", genai.RoleUser), agent.RunConfig{StreamingMode: streaming}))
+			if !errors.Is(err, llmagent.ErrNoFinalResponse) {
+				t.Errorf("Run() error = %v, want %v", err, llmagent.ErrNoFinalResponse)
+			}
+			var truncErr *llmagent.OutputTruncatedError
+			if errors.As(err, &truncErr) {
+				t.Errorf("Run() error = %v, want no truncation error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/adk/internal/typeutil"
+	"google.golang.org/adk/session"
+)
+
+// DecodeOutput decodes the final response event of an agent with an
+// OutputSchema into a value of type T, typically a struct matching the
+// schema.
+//
+// The text parts of the event, except thoughts, are decoded as JSON.
+func DecodeOutput[T any](event *session.Event) (T, error) {
+	var zero T
+	if event == nil || event.Content == nil {
+		return zero, errors.New("event has no content")
+	}
+	var sb strings.Builder
+	for _, part := range event.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	var out T
+	if err := json.Unmarshal([]byte(sb.String()), &out); err != nil {
+		return zero, fmt.Errorf("failed to decode output: %w", err)
+	}
+	return out, nil
+}
+
+// OutputFromState decodes the value stored under key in state, e.g. by an
+// agent with an OutputKey and an OutputSchema, into a value of type T.
+//
+// Values stored as JSON strings are decoded as well.
+func OutputFromState[T any](state session.ReadonlyState, key string) (T, error) {
+	var zero T
+	value, err := state.Get(key)
+	if err != nil {
+		return zero, err
+	}
+	if v, ok := value.(T); ok {
+		return v, nil
+	}
+	if s, ok := value.(string); ok {
+		var out T
+		if err := json.Unmarshal([]byte(s), &out); err != nil {
+			return zero, fmt.Errorf("failed to decode state %q: %w", key, err)
+		}
+		return out, nil
+	}
+	out, err := typeutil.ConvertToWithJSONSchema[any, T](value, nil)
+	if err != nil {
+		return zero, fmt.Errorf("failed to decode state %q: %w", key, err)
+	}
+	return out, nil
+}
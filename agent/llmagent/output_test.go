@@ -0,0 +1,368 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"errors"
+	"iter"
+	"maps"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model/modeltest"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+type capital struct {
+	Country string `json:"country"`
+	Capital string `json:"capital"`
+}
+
+var capitalSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"country": {Type: genai.TypeString},
+		"capital": {Type: genai.TypeString},
+	},
+	Required: []string{"country", "capital"},
+}
+
+func TestOutputSchema(t *testing.T) {
+	tests := []struct {
+		name        string
+		turns       []*modeltest.Turn
+		retries     int
+		wantErr     error
+		wantState   map[string]any
+		wantOutput  capital
+		wantPrompts int
+	}{
+		{
+			name:        "valid",
+			turns:       []*modeltest.Turn{modeltest.Text(`{"country": "France", "capital": "Paris"}`)},
+			wantState:   map[string]any{"country": "France", "capital": "Paris"},
+			wantOutput:  capital{Country: "France", Capital: "Paris"},
+			wantPrompts: 1,
+		},
+		{
+			name: "re-prompted",
+			turns: []*modeltest.Turn{
+				modeltest.Text(`{"country": "France"}`),
+				modeltest.Text(`{"country": "France", "capital": "Paris"}`),
+			},
+			retries:     1,
+			wantState:   map[string]any{"country": "France", "capital": "Paris"},
+			wantOutput:  capital{Country: "France", Capital: "Paris"},
+			wantPrompts: 2,
+		},
+		{
+			name: "retries exhausted",
+			turns: []*modeltest.Turn{
+				modeltest.Text(`Paris`),
+				modeltest.Text(`{"country": "France"}`),
+			},
+			retries:     1,
+			wantErr:     llmagent.ErrInvalidOutput,
+			wantPrompts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := modeltest.New(tt.turns...)
+			a, err := llmagent.New(llmagent.Config{
+				Name:                   "capital_agent",
+				Model:                  llm,
+				OutputSchema:           capitalSchema,
+				OutputKey:              "capital",
+				MaxOutputSchemaRetries: tt.retries,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			var last *session.Event
+			for ev, err := range runner.Run(t, "session", "Capital of France?") {
+				if err != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+					}
+					break
+				}
+				last = ev
+			}
+			if got := len(llm.Requests()); got != tt.wantPrompts {
+				t.Errorf("model called %d times, want %d", got, tt.wantPrompts)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if diff := cmp.Diff(tt.wantState, last.Actions.StateDelta["capital"]); diff != "" {
+				t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+			}
+			got, err := llmagent.DecodeOutput[capital](last)
+			if err != nil {
+				t.Fatalf("DecodeOutput() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantOutput, got); diff != "" {
+				t.Errorf("DecodeOutput() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutputSchema_RepromptContents(t *testing.T) {
+	llm := modeltest.New(
+		modeltest.Text(`{"country": "France"}`),
+		modeltest.Text(`{"country": "France", "capital": "Paris"}`),
+	)
+	a, err := llmagent.New(llmagent.Config{
+		Name:                   "capital_agent",
+		Model:                  llm,
+		OutputSchema:           capitalSchema,
+		MaxOutputSchemaRetries: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "Capital of France?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The invalid response is not part of the session.
+	if len(events) != 1 {
+		t.Errorf("got %d events, want 1", len(events))
+	}
+
+	contents := llm.Requests()[1].Contents
+	if len(contents) != 3 {
+		t.Fatalf("re-prompt has %d contents, want 3", len(contents))
+	}
+	want := `Your response does not match the required output schema: "output" args does not contain required key: '"capital"'. ` +
+		"Respond again with only a JSON object matching the schema."
+	if diff := cmp.Diff(genai.NewContentFromText(want, genai.RoleUser), contents[2]); diff != "" {
+		t.Errorf("re-prompt mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestOutputSchemaWithTools_InvalidToolCall(t *testing.T) {
+	lookup, err := functiontool.New(functiontool.Config{
+		Name:        "lookup_capital",
+		Description: "Returns the capital of a country.",
+	}, func(ctx tool.Context, args struct{}) (map[string]any, error) {
+		return map[string]any{"capital": "Paris"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		turns       []*modeltest.Turn
+		wantErr     error
+		wantPrompts int
+	}{
+		{
+			name: "re-prompted",
+			turns: []*modeltest.Turn{
+				modeltest.FunctionCall("set_model_response", map[string]any{"country": "France"}),
+				modeltest.FunctionCall("set_model_response", map[string]any{"country": "France", "capital": "Paris"}),
+			},
+			wantPrompts: 2,
+		},
+		{
+			name: "retries exhausted",
+			turns: []*modeltest.Turn{
+				modeltest.FunctionCall("set_model_response", map[string]any{"country": "France"}),
+				modeltest.FunctionCall("set_model_response", map[string]any{"capital": "Paris"}),
+			},
+			wantErr:     llmagent.ErrInvalidOutput,
+			wantPrompts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := modeltest.New(tt.turns...)
+			a, err := llmagent.New(llmagent.Config{
+				Name:                   "capital_agent",
+				Model:                  llm,
+				Tools:                  []tool.Tool{lookup},
+				OutputSchema:           capitalSchema,
+				OutputKey:              "capital",
+				MaxOutputSchemaRetries: 1,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			var events []*session.Event
+			for ev, err := range runner.Run(t, "session", "Capital of France?") {
+				if err != nil {
+					if !errors.Is(err, tt.wantErr) {
+						t.Fatalf("Run() error = %v, want %v", err, tt.wantErr)
+					}
+					break
+				}
+				events = append(events, ev)
+			}
+			if got := len(llm.Requests()); got != tt.wantPrompts {
+				t.Errorf("model called %d times, want %d", got, tt.wantPrompts)
+			}
+			// The invalid output is never stored.
+			for _, ev := range events {
+				if v, ok := ev.Actions.StateDelta["capital"]; ok && tt.wantErr != nil {
+					t.Errorf("state delta has output %v, want none", v)
+				}
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			// The invalid call is answered like a failed tool call.
+			contents := llm.Requests()[1].Contents
+			if len(contents) != 3 {
+				t.Fatalf("re-prompt has %d contents, want 3", len(contents))
+			}
+			resp := contents[2].Parts[0].FunctionResponse
+			if resp == nil || resp.Name != "set_model_response" || resp.Response["error"] == nil {
+				t.Errorf("re-prompt = %+v, want set_model_response error", contents[2].Parts[0])
+			}
+
+			last := events[len(events)-1]
+			got, err := llmagent.DecodeOutput[capital](last)
+			if err != nil {
+				t.Fatalf("DecodeOutput() error = %v", err)
+			}
+			if diff := cmp.Diff(capital{Country: "France", Capital: "Paris"}, got); diff != "" {
+				t.Errorf("DecodeOutput() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(map[string]any{"country": "France", "capital": "Paris"}, last.Actions.StateDelta["capital"]); diff != "" {
+				t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOutputFromState(t *testing.T) {
+	state := testState{
+		"map":    map[string]any{"country": "France", "capital": "Paris"},
+		"string": `{"country": "Italy", "capital": "Rome"}`,
+		"struct": capital{Country: "Spain", Capital: "Madrid"},
+	}
+	tests := []struct {
+		key     string
+		want    capital
+		wantErr bool
+	}{
+		{key: "map", want: capital{Country: "France", Capital: "Paris"}},
+		{key: "string", want: capital{Country: "Italy", Capital: "Rome"}},
+		{key: "struct", want: capital{Country: "Spain", Capital: "Madrid"}},
+		{key: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := llmagent.OutputFromState[capital](state, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OutputFromState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("OutputFromState() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+type testState map[string]any
+
+func (s testState) Get(key string) (any, error) {
+	v, ok := s[key]
+	if !ok {
+		return nil, session.ErrStateKeyNotExist
+	}
+	return v, nil
+}
+
+func (s testState) All() iter.Seq2[string, any] {
+	return maps.All(s)
+}
+
+func TestOutputSchemaWithTools(t *testing.T) {
+	type args struct {
+		Country string `json:"country"`
+	}
+	lookup, err := functiontool.New(functiontool.Config{
+		Name:        "lookup_capital",
+		Description: "Returns the capital of a country.",
+	}, func(ctx tool.Context, args args) (map[string]any, error) {
+		return map[string]any{"capital": "Paris"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	llm := modeltest.New(
+		modeltest.FunctionCall("lookup_capital", map[string]any{"country": "France"}),
+		// A final text response is re-prompted.
+		modeltest.Text("The capital of France is Paris."),
+		modeltest.FunctionCall("set_model_response", map[string]any{"country": "France", "capital": "Paris"}),
+	)
+	a, err := llmagent.New(llmagent.Config{
+		Name:                   "capital_agent",
+		Model:                  llm,
+		Tools:                  []tool.Tool{lookup},
+		OutputSchema:           capitalSchema,
+		OutputKey:              "capital",
+		MaxOutputSchemaRetries: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "Capital of France?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := len(llm.Requests()); got != 3 {
+		t.Fatalf("model called %d times, want 3", got)
+	}
+	if _, ok := llm.Requests()[0].Tools["set_model_response"]; !ok {
+		t.Errorf("set_model_response tool not in the request")
+	}
+
+	last := events[len(events)-1]
+	if !last.IsFinalResponse() {
+		t.Errorf("last event is not final: %+v", last)
+	}
+	got, err := llmagent.DecodeOutput[capital](last)
+	if err != nil {
+		t.Fatalf("DecodeOutput() error = %v", err)
+	}
+	if diff := cmp.Diff(capital{Country: "France", Capital: "Paris"}, got); diff != "" {
+		t.Errorf("DecodeOutput() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]any{"country": "France", "capital": "Paris"}, last.Actions.StateDelta["capital"]); diff != "" {
+		t.Errorf("state delta mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent
+
+import (
+	"fmt"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/model"
+)
+
+// RequestProcessor modifies the request of each model call of an agent, e.g.
+// to add retrieved context or to rewrite the conversation history.
+//
+// Request processors run in order when the request is built, before the
+// BeforeModelCallbacks. Each processor sees the changes of the previous
+// ones, e.g. a processor after ContentsProcessor sees the contents built
+// from the session.
+type RequestProcessor interface {
+	// Name identifies the processor among the processors of the agent.
+	Name() string
+	// ProcessRequest modifies the request. An error ends the agent run.
+	ProcessRequest(ctx agent.InvocationContext, req *model.LLMRequest) error
+}
+
+// ResponseProcessor modifies each response of the model to an agent.
+//
+// Response processors run in order after the AfterModelCallbacks, before
+// the response is yielded as an event and its function calls are run.
+type ResponseProcessor interface {
+	// Name identifies the processor among the processors of the agent.
+	Name() string
+	// ProcessResponse modifies the response to the request. An error ends the
+	// agent run.
+	ProcessResponse(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error
+}
+
+// Names of the default processors, see [DefaultRequestProcessors] and
+// [DefaultResponseProcessors].
+const (
+	// BasicProcessor sets the model and the GenerateContentConfig.
+	BasicProcessor = llminternal.BasicProcessor
+	// AuthProcessor handles the credentials provided by the client.
+	AuthProcessor = llminternal.AuthProcessor
+	// InstructionsProcessor adds the instructions to the system instruction.
+	InstructionsProcessor = llminternal.InstructionsProcessor
+	// IdentityProcessor adds the name and description of the agent to the
+	// system instruction.
+	IdentityProcessor = llminternal.IdentityProcessor
+	// ContentsProcessor builds the contents from the session events.
+	ContentsProcessor = llminternal.ContentsProcessor
+	// PlanningProcessor applies the Planner, both to requests and responses.
+	PlanningProcessor = llminternal.PlanningProcessor
+	// CodeExecutionProcessor applies the CodeExecutor, both to requests and
+	// responses.
+	CodeExecutionProcessor = llminternal.CodeExecutionProcessor
+	// OutputSchemaProcessor adds the set_model_response tool, if needed for
+	// the OutputSchema.
+	OutputSchemaProcessor = llminternal.OutputSchemaProcessor
+	// AgentTransferProcessor adds the transfer_to_agent tool.
+	AgentTransferProcessor = llminternal.AgentTransferProcessor
+	// DisplayNameProcessor removes the display names of the inline and file
+	// data.
+	DisplayNameProcessor = llminternal.DisplayNameProcessor
+)
+
+// DefaultRequestProcessors returns the request processors of an agent with
+// no Config.RequestProcessors. Their order is BasicProcessor, AuthProcessor,
+// InstructionsProcessor, IdentityProcessor, ContentsProcessor,
+// PlanningProcessor, CodeExecutionProcessor, OutputSchemaProcessor,
+// AgentTransferProcessor and DisplayNameProcessor.
+//
+// The returned slice is new, so it can be modified to add, replace or
+// reorder processors.
+func DefaultRequestProcessors() []RequestProcessor {
+	processors := make([]RequestProcessor, 0, len(llminternal.DefaultRequestProcessors))
+	for _, p := range llminternal.DefaultRequestProcessors {
+		processors = append(processors, NewRequestProcessor(p.Name, p.Process))
+	}
+	return processors
+}
+
+// DefaultResponseProcessors returns the response processors of an agent
+// with no Config.ResponseProcessors: PlanningProcessor, then
+// CodeExecutionProcessor.
+//
+// The returned slice is new, so it can be modified to add, replace or
+// reorder processors.
+func DefaultResponseProcessors() []ResponseProcessor {
+	processors := make([]ResponseProcessor, 0, len(llminternal.DefaultResponseProcessors))
+	for _, p := range llminternal.DefaultResponseProcessors {
+		processors = append(processors, NewResponseProcessor(p.Name, p.Process))
+	}
+	return processors
+}
+
+// processorFuncs returns the request and response processors of the config,
+// or the default ones, as functions for the flow.
+func processorFuncs(cfg Config) ([]func(agent.InvocationContext, *model.LLMRequest) error, []func(agent.InvocationContext, *model.LLMRequest, *model.LLMResponse) error, error) {
+	requestProcessors := cfg.RequestProcessors
+	if requestProcessors == nil {
+		requestProcessors = DefaultRequestProcessors()
+	}
+	responseProcessors := cfg.ResponseProcessors
+	if responseProcessors == nil {
+		responseProcessors = DefaultResponseProcessors()
+	}
+
+	names := make(map[string]bool)
+	var requestFuncs []func(agent.InvocationContext, *model.LLMRequest) error
+	for i, p := range requestProcessors {
+		if p == nil {
+			return nil, nil, fmt.Errorf("request processor %d is nil", i)
+		}
+		if names[p.Name()] {
+			return nil, nil, fmt.Errorf("duplicate request processor %q", p.Name())
+		}
+		names[p.Name()] = true
+		requestFuncs = append(requestFuncs, p.ProcessRequest)
+	}
+	clear(names)
+	var responseFuncs []func(agent.InvocationContext, *model.LLMRequest, *model.LLMResponse) error
+	for i, p := range responseProcessors {
+		if p == nil {
+			return nil, nil, fmt.Errorf("response processor %d is nil", i)
+		}
+		if names[p.Name()] {
+			return nil, nil, fmt.Errorf("duplicate response processor %q", p.Name())
+		}
+		names[p.Name()] = true
+		responseFuncs = append(responseFuncs, p.ProcessResponse)
+	}
+	return requestFuncs, responseFuncs, nil
+}
+
+// NewRequestProcessor returns a RequestProcessor calling fn.
+func NewRequestProcessor(name string, fn func(ctx agent.InvocationContext, req *model.LLMRequest) error) RequestProcessor {
+	return &requestProcessor{name: name, fn: fn}
+}
+
+// NewResponseProcessor returns a ResponseProcessor calling fn.
+func NewResponseProcessor(name string, fn func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error) ResponseProcessor {
+	return &responseProcessor{name: name, fn: fn}
+}
+
+type requestProcessor struct {
+	name string
+	fn   func(ctx agent.InvocationContext, req *model.LLMRequest) error
+}
+
+func (p *requestProcessor) Name() string {
+	return p.name
+}
+
+func (p *requestProcessor) ProcessRequest(ctx agent.InvocationContext, req *model.LLMRequest) error {
+	return p.fn(ctx, req)
+}
+
+type responseProcessor struct {
+	name string
+	fn   func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error
+}
+
+func (p *responseProcessor) Name() string {
+	return p.name
+}
+
+func (p *responseProcessor) ProcessResponse(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+	return p.fn(ctx, req, resp)
+}
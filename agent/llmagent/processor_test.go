@@ -0,0 +1,148 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmagent_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/modeltest"
+)
+
+func TestProcessors(t *testing.T) {
+	retrieve := llmagent.NewRequestProcessor("retrieve", func(ctx agent.InvocationContext, req *model.LLMRequest) error {
+		req.Contents = append(req.Contents, genai.NewContentFromText("Context: the butler did it.", genai.RoleUser))
+		return nil
+	})
+	instructions := llmagent.NewRequestProcessor(llmagent.InstructionsProcessor, func(ctx agent.InvocationContext, req *model.LLMRequest) error {
+		req.Config.SystemInstruction = genai.NewContentFromText("Answer in one word.", genai.RoleUser)
+		return nil
+	})
+	shout := llmagent.NewResponseProcessor("shout", func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+		for _, part := range resp.Content.Parts {
+			part.Text = strings.ToUpper(part.Text)
+		}
+		return nil
+	})
+	insertAfter := func(processors []llmagent.RequestProcessor, name string, p llmagent.RequestProcessor) []llmagent.RequestProcessor {
+		i := slices.IndexFunc(processors, func(p llmagent.RequestProcessor) bool { return p.Name() == name })
+		return slices.Insert(processors, i+1, p)
+	}
+	replace := func(processors []llmagent.RequestProcessor, p llmagent.RequestProcessor) []llmagent.RequestProcessor {
+		for i := range processors {
+			if processors[i].Name() == p.Name() {
+				processors[i] = p
+			}
+		}
+		return processors
+	}
+
+	tests := []struct {
+		name                string
+		requestProcessors   []llmagent.RequestProcessor
+		responseProcessors  []llmagent.ResponseProcessor
+		wantContents        []*genai.Content
+		wantInstruction     string
+		wantResponse        string
+		wantProcessorsError bool
+	}{
+		{
+			name: "defaults",
+			wantContents: []*genai.Content{
+				genai.NewContentFromText("who did it?", genai.RoleUser),
+			},
+			wantInstruction: "Be brief.",
+			wantResponse:    "the butler",
+		},
+		{
+			name:              "added after contents",
+			requestProcessors: insertAfter(llmagent.DefaultRequestProcessors(), llmagent.ContentsProcessor, retrieve),
+			wantContents: []*genai.Content{
+				genai.NewContentFromText("who did it?", genai.RoleUser),
+				genai.NewContentFromText("Context: the butler did it.", genai.RoleUser),
+			},
+			wantInstruction: "Be brief.",
+			wantResponse:    "the butler",
+		},
+		{
+			name:              "replaced",
+			requestProcessors: replace(llmagent.DefaultRequestProcessors(), instructions),
+			wantContents: []*genai.Content{
+				genai.NewContentFromText("who did it?", genai.RoleUser),
+			},
+			wantInstruction: "Answer in one word.",
+			wantResponse:    "the butler",
+		},
+		{
+			name:               "response processor",
+			responseProcessors: append(llmagent.DefaultResponseProcessors(), shout),
+			wantContents: []*genai.Content{
+				genai.NewContentFromText("who did it?", genai.RoleUser),
+			},
+			wantInstruction: "Be brief.",
+			wantResponse:    "THE BUTLER",
+		},
+		{
+			name:                "duplicate names",
+			requestProcessors:   append(llmagent.DefaultRequestProcessors(), instructions),
+			wantProcessorsError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm := modeltest.New(modeltest.Text("the butler"))
+			a, err := llmagent.New(llmagent.Config{
+				Name:               "detective",
+				Model:              llm,
+				Instruction:        "Be brief.",
+				RequestProcessors:  tt.requestProcessors,
+				ResponseProcessors: tt.responseProcessors,
+			})
+			if tt.wantProcessorsError {
+				if err == nil {
+					t.Fatal("llmagent.New() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events, err := testutil.CollectEvents(testutil.NewTestAgentRunner(t, a).Run(t, "session", "who did it?"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := llm.Requests()[0]
+			if diff := cmp.Diff(tt.wantContents, req.Contents); diff != "" {
+				t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+			}
+			if got := req.Config.SystemInstruction.Parts[0].Text; !strings.HasPrefix(got, tt.wantInstruction) {
+				t.Errorf("system instruction = %q, want prefix %q", got, tt.wantInstruction)
+			}
+			if got := events[len(events)-1].Content.Parts[0].Text; got != tt.wantResponse {
+				t.Errorf("response = %q, want %q", got, tt.wantResponse)
+			}
+		})
+	}
+}
@@ -14,6 +14,11 @@
 
 package agent
 
+import (
+	"fmt"
+	"time"
+)
+
 // StreamingMode defines the streaming mode for agent execution.
 type StreamingMode string
 
@@ -26,10 +31,56 @@ const (
 )
 
 // RunConfig controls runtime behavior of an agent.
+//
+// Once an invocation exceeds one of its limits, it ends with an event whose
+// ErrorCode is LimitExceededErrorCode, followed by a *LimitExceededError.
 type RunConfig struct {
 	// StreamingMode defines the streaming mode for an agent.
 	StreamingMode StreamingMode
 	// If true, ADK runner will save each part of the user input that is a blob
 	// (e.g., images, files) as an artifact.
 	SaveInputBlobsAsArtifacts bool
+	// MaxConcurrentToolCalls limits the number of function calls of a model
+	// response which run concurrently. If 0, all of them run concurrently;
+	// set it to 1 to run them one at a time.
+	MaxConcurrentToolCalls int
+
+	// MaxLLMCalls limits the number of model calls of an invocation, across
+	// all of its agents, including the agents run by agent tools.
+	// If 0, the number of model calls isn't limited.
+	MaxLLMCalls int
+	// MaxToolCalls limits the number of function calls of an invocation,
+	// like MaxLLMCalls. If 0, the number of function calls isn't limited.
+	MaxToolCalls int
+	// MaxDuration limits the wall-clock time of an invocation. It is checked
+	// before each model call, function call and step of the agents, so
+	// calls in progress are not interrupted. If 0, the duration isn't
+	// limited.
+	MaxDuration time.Duration
+}
+
+// Limit is the name of a limit of RunConfig.
+type Limit string
+
+const (
+	LimitLLMCalls  Limit = "MaxLLMCalls"
+	LimitToolCalls Limit = "MaxToolCalls"
+	LimitDuration  Limit = "MaxDuration"
+)
+
+// LimitExceededErrorCode is the ErrorCode of the event ending an invocation
+// which exceeded a limit of its RunConfig.
+const LimitExceededErrorCode = "LIMIT_EXCEEDED"
+
+// LimitExceededError is returned when an invocation exceeds a limit of its
+// RunConfig.
+type LimitExceededError struct {
+	// Limit is the exceeded limit.
+	Limit Limit
+	// Value is the value of the limit in the RunConfig.
+	Value any
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("invocation exceeded %s of %v", e.Limit, e.Value)
 }
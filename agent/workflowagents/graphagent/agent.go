@@ -0,0 +1,459 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package graphagent provides an agent that runs its sub-agents as the nodes
+// of a graph, following the edges whose conditions hold.
+package graphagent
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+
+	"golang.org/x/sync/errgroup"
+
+	"google.golang.org/adk/agent"
+	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/agent/checkpoint"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/session"
+)
+
+// DefaultMaxSteps is the maximum number of steps of a run if
+// Config.MaxSteps is 0.
+const DefaultMaxSteps = 100
+
+// ErrMaxSteps is returned when a run exceeds the maximum number of steps,
+// e.g. because the conditions of a cycle never end it.
+var ErrMaxSteps = errors.New("graph exceeded the maximum number of steps")
+
+// Config defines the configuration for a GraphAgent.
+type Config struct {
+	// Basic agent setup. The sub-agents are the nodes of the graph.
+	AgentConfig agent.Config
+
+	// Start is the name of the first node. If empty, the first sub-agent is
+	// the first node.
+	Start string
+	// Edges connect the nodes.
+	//
+	// After a node runs, its outgoing edges are evaluated in the order they
+	// are listed, and only the first edge whose condition holds is followed.
+	// An edge without a condition after conditional edges is therefore the
+	// "else" branch. The run ends when no edge is followed.
+	Edges []Edge
+	// MaxSteps bounds the number of steps of a run, to bound cycles. If 0,
+	// DefaultMaxSteps is used.
+	MaxSteps uint
+}
+
+// Edge connects a node to the next nodes.
+type Edge struct {
+	// From is the name of the source node.
+	From string
+	// To are the names of the next nodes.
+	//
+	// More than one node fans out: the nodes run in parallel, each on its
+	// own branch like the sub-agents of a ParallelAgent. Nodes reached from
+	// several nodes of the same step run once in the next step, which joins
+	// the branches.
+	To []string
+	// Condition guards the edge. If nil, the edge is always followed.
+	Condition Condition
+}
+
+// Condition reports whether an edge is followed, given the session state and
+// the last event of the source node. The event is nil if the node yielded
+// no events.
+type Condition func(state session.ReadonlyState, event *session.Event) bool
+
+// StateEquals returns a Condition holding if the session state has the value
+// under key.
+func StateEquals(key string, value any) Condition {
+	return func(state session.ReadonlyState, _ *session.Event) bool {
+		v, err := state.Get(key)
+		return err == nil && reflect.DeepEqual(v, value)
+	}
+}
+
+// CheckpointKey returns the session state key under which the graph agent
+// with the given name checkpoints the nodes of the current step.
+//
+// The checkpoint is written before each step, updated each time a node of a
+// fan-out step completes, and set to nil when the run ends. If a run stops
+// before, e.g. because a node failed or is waiting for a long-running tool,
+// the next run resumes the step, running only the nodes which didn't
+// complete.
+func CheckpointKey(agentName string) string {
+	return "_adk_graphagent:" + agentName
+}
+
+// New creates a GraphAgent.
+//
+// GraphAgent runs its sub-agents as the nodes of a graph. It starts with the
+// start node and, after each step, follows the edges of the nodes that ran
+// to the next nodes. Edges can branch on the session state or the output of
+// a node, fan out to nodes running in parallel, join branches and form
+// bounded cycles.
+//
+// A run ends when no edge is followed or when a node escalates.
+func New(cfg Config) (agent.Agent, error) {
+	if cfg.AgentConfig.Run != nil {
+		return nil, fmt.Errorf("GraphAgent doesn't allow custom Run implementations")
+	}
+	if len(cfg.AgentConfig.SubAgents) == 0 {
+		return nil, fmt.Errorf("GraphAgent requires at least one sub-agent")
+	}
+
+	g := &graphAgent{
+		nodes:    make(map[string]agent.Agent),
+		edges:    make(map[string][]Edge),
+		start:    cfg.Start,
+		maxSteps: cfg.MaxSteps,
+	}
+	for _, sa := range cfg.AgentConfig.SubAgents {
+		g.nodes[sa.Name()] = sa
+	}
+	if g.start == "" {
+		g.start = cfg.AgentConfig.SubAgents[0].Name()
+	}
+	if _, ok := g.nodes[g.start]; !ok {
+		return nil, fmt.Errorf("start node %q is not a sub-agent", g.start)
+	}
+	if g.maxSteps == 0 {
+		g.maxSteps = DefaultMaxSteps
+	}
+	for i, e := range cfg.Edges {
+		if _, ok := g.nodes[e.From]; !ok {
+			return nil, fmt.Errorf("edge %d: source node %q is not a sub-agent", i, e.From)
+		}
+		if len(e.To) == 0 {
+			return nil, fmt.Errorf("edge %d: no next nodes", i)
+		}
+		for _, to := range e.To {
+			if _, ok := g.nodes[to]; !ok {
+				return nil, fmt.Errorf("edge %d: next node %q is not a sub-agent", i, to)
+			}
+		}
+		g.edges[e.From] = append(g.edges[e.From], e)
+	}
+
+	cfg.AgentConfig.Run = g.run
+
+	graphAgent, err := agent.New(cfg.AgentConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create base agent: %w", err)
+	}
+
+	internalAgent, ok := graphAgent.(agentinternal.Agent)
+	if !ok {
+		return nil, fmt.Errorf("internal error: failed to convert to internal agent")
+	}
+	state := agentinternal.Reveal(internalAgent)
+	state.AgentType = agentinternal.TypeGraphAgent
+	state.Config = cfg
+
+	return graphAgent, nil
+}
+
+type graphAgent struct {
+	nodes    map[string]agent.Agent
+	edges    map[string][]Edge
+	start    string
+	maxSteps uint
+}
+
+// progress is the progress of a run, stored under CheckpointKey.
+type progress struct {
+	nodes []string
+	step  uint
+	// done maps the nodes of the step which completed to the ID of their
+	// last event.
+	done map[string]string
+}
+
+func (c *progress) value() map[string]any {
+	v := map[string]any{"nodes": c.nodes, "step": c.step}
+	if len(c.done) > 0 {
+		v["done"] = maps.Clone(c.done)
+	}
+	return v
+}
+
+// stepResult is the outcome of running the nodes of a step.
+type stepResult struct {
+	// last is the last non-partial event of each node.
+	last      map[string]*session.Event
+	escalated bool
+}
+
+func (g *graphAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		key := CheckpointKey(ctx.Agent().Name())
+		cp := g.restore(ctx.Session().State(), key)
+		for {
+			if cp.step >= g.maxSteps {
+				if yield(checkpoint.Event(ctx, key, nil), nil) {
+					yield(nil, fmt.Errorf("agent %q: %w (%d)", ctx.Agent().Name(), ErrMaxSteps, g.maxSteps))
+				}
+				return
+			}
+			if !yield(checkpoint.Event(ctx, key, cp.value()), nil) {
+				return
+			}
+
+			res, ok := g.runStep(ctx, key, cp, yield)
+			if !ok {
+				return
+			}
+			if checkpoint.AnyPaused(maps.Values(res.last)) {
+				// Keep the checkpoint, so the next run resumes the step.
+				return
+			}
+
+			var next []string
+			if !res.escalated {
+				next = g.next(ctx.Session().State(), cp.nodes, res.last)
+			}
+			if len(next) == 0 {
+				yield(checkpoint.Event(ctx, key, nil), nil)
+				return
+			}
+			cp = &progress{nodes: next, step: cp.step + 1}
+		}
+	}
+}
+
+// restore returns the checkpoint of the run, or the checkpoint of the first
+// step if there is no valid one.
+func (g *graphAgent) restore(state session.ReadonlyState, key string) *progress {
+	start := &progress{nodes: []string{g.start}}
+	v, err := state.Get(key)
+	if err != nil {
+		return start
+	}
+	value, ok := v.(map[string]any)
+	if !ok {
+		return start
+	}
+
+	// Values stored as JSON are decoded as []any and map[string]any.
+	cp := &progress{}
+	switch v := value["nodes"].(type) {
+	case []string:
+		cp.nodes = v
+	case []any:
+		for _, n := range v {
+			name, ok := n.(string)
+			if !ok {
+				return start
+			}
+			cp.nodes = append(cp.nodes, name)
+		}
+	}
+	if len(cp.nodes) == 0 {
+		return start
+	}
+	for _, name := range cp.nodes {
+		if _, ok := g.nodes[name]; !ok {
+			return start
+		}
+	}
+
+	if step, ok := checkpoint.Int(value["step"]); ok {
+		cp.step = uint(max(step, 0))
+	}
+
+	switch v := value["done"].(type) {
+	case map[string]string:
+		cp.done = maps.Clone(v)
+	case map[string]any:
+		cp.done = make(map[string]string, len(v))
+		for name, id := range v {
+			id, ok := id.(string)
+			if !ok {
+				return start
+			}
+			cp.done[name] = id
+		}
+	}
+	for name := range cp.done {
+		if !slices.Contains(cp.nodes, name) {
+			return start
+		}
+	}
+	return cp
+}
+
+// runStep runs the nodes of the step which didn't complete, in parallel if
+// the step has several nodes, and yields their events. It returns false if
+// the run must stop, because the consumer stopped or a node failed.
+func (g *graphAgent) runStep(ctx agent.InvocationContext, key string, cp *progress, yield func(*session.Event, error) bool) (stepResult, bool) {
+	res := stepResult{last: make(map[string]*session.Event)}
+	observe := func(node string, event *session.Event) {
+		if event == nil || event.Partial {
+			return
+		}
+		res.last[node] = event
+		if event.Actions.Escalate {
+			res.escalated = true
+		}
+	}
+
+	if len(cp.nodes) == 1 {
+		for event, err := range g.nodes[cp.nodes[0]].Run(ctx) {
+			if !yield(event, err) || err != nil {
+				return res, false
+			}
+			observe(cp.nodes[0], event)
+		}
+		return res, true
+	}
+
+	// The nodes which completed in a previous run aren't run again, their
+	// last events are read from the session.
+	var pending []string
+	for _, name := range cp.nodes {
+		if _, ok := cp.done[name]; !ok {
+			pending = append(pending, name)
+		}
+	}
+	for event := range ctx.Session().Events().All() {
+		for name, id := range cp.done {
+			if id != "" && event.ID == id {
+				res.last[name] = event
+			}
+		}
+	}
+
+	for r := range g.runParallel(ctx, pending) {
+		if r.done {
+			if checkpoint.Paused(res.last[r.node]) {
+				continue
+			}
+			if cp.done == nil {
+				cp.done = make(map[string]string)
+			}
+			cp.done[r.node] = ""
+			if event := res.last[r.node]; event != nil {
+				cp.done[r.node] = event.ID
+			}
+			if !yield(checkpoint.Event(ctx, key, cp.value()), nil) {
+				return res, false
+			}
+			continue
+		}
+		if !yield(r.event, r.err) || r.err != nil {
+			return res, false
+		}
+		observe(r.node, r.event)
+	}
+	return res, true
+}
+
+// runParallel runs the nodes in parallel, each on its own branch.
+func (g *graphAgent) runParallel(ctx agent.InvocationContext, nodes []string) iter.Seq[result] {
+	curAgent := ctx.Agent()
+
+	var (
+		errGroup, errGroupCtx = errgroup.WithContext(ctx)
+		doneChan              = make(chan bool)
+		resultsChan           = make(chan result)
+	)
+
+	for _, name := range nodes {
+		branch := fmt.Sprintf("%s.%s", curAgent.Name(), name)
+		if ctx.Branch() != "" {
+			branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+		}
+		node := g.nodes[name]
+		errGroup.Go(func() error {
+			nodeCtx := icontext.NewInvocationContext(errGroupCtx, icontext.InvocationContextParams{
+				Artifacts:   ctx.Artifacts(),
+				Memory:      ctx.Memory(),
+				Credentials: ctx.Credentials(),
+				Session:     ctx.Session(),
+				Branch:      branch,
+				Agent:       node,
+				UserContent: ctx.UserContent(),
+				RunConfig:   ctx.RunConfig(),
+			})
+
+			for event, err := range node.Run(nodeCtx) {
+				if err != nil {
+					err = fmt.Errorf("failed to run node %q: %w", name, err)
+				}
+				select {
+				case <-doneChan:
+					return nil
+				case resultsChan <- result{node: name, event: event, err: err}:
+					if err != nil {
+						return err
+					}
+				}
+			}
+			select {
+			case <-doneChan:
+			case resultsChan <- result{node: name, done: true}:
+			}
+			return nil
+		})
+	}
+
+	go func() {
+		_ = errGroup.Wait() // this error is already sent to the consumer
+		close(resultsChan)
+	}()
+
+	return func(yield func(result) bool) {
+		defer close(doneChan)
+
+		for res := range resultsChan {
+			if !yield(res) {
+				return
+			}
+		}
+	}
+}
+
+// next returns the nodes of the next step, following the first edge whose
+// condition holds for each node.
+func (g *graphAgent) next(state session.ReadonlyState, nodes []string, last map[string]*session.Event) []string {
+	var next []string
+	for _, name := range nodes {
+		for _, e := range g.edges[name] {
+			if e.Condition != nil && !e.Condition(state, last[name]) {
+				continue
+			}
+			for _, to := range e.To {
+				if !slices.Contains(next, to) {
+					next = append(next, to)
+				}
+			}
+			break
+		}
+	}
+	return next
+}
+
+type result struct {
+	node  string
+	event *session.Event
+	err   error
+	// done is set once the node completed.
+	done bool
+}
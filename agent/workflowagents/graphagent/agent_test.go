@@ -0,0 +1,497 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package graphagent_test
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/workflowagents/graphagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+)
+
+// testNode is a node yielding one event with its name as text.
+type testNode struct {
+	// delta returns the state delta of the event.
+	delta    func(state session.ReadonlyState) map[string]any
+	escalate bool
+	// failures is the number of runs failing before the node succeeds.
+	failures int
+	// pauses is the number of runs waiting for a long-running tool before
+	// the node succeeds.
+	pauses int
+	runs   int
+}
+
+func newNode(t *testing.T, name string, n *testNode) agent.Agent {
+	t.Helper()
+	a, err := agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				n.runs++
+				if n.runs <= n.failures {
+					yield(nil, fmt.Errorf("%s failed", name))
+					return
+				}
+				event := session.NewEvent(ctx.InvocationID())
+				event.Branch = ctx.Branch()
+				event.LLMResponse = model.LLMResponse{Content: genai.NewContentFromText(name, genai.RoleModel)}
+				if n.delta != nil {
+					event.Actions.StateDelta = n.delta(ctx.Session().State())
+				}
+				event.Actions.Escalate = n.escalate
+				if n.runs <= n.failures+n.pauses {
+					event.LongRunningToolIDs = []string{"call_" + name}
+				}
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func setState(key string, value any) func(session.ReadonlyState) map[string]any {
+	return func(session.ReadonlyState) map[string]any {
+		return map[string]any{key: value}
+	}
+}
+
+// increment increments the int under key.
+func increment(key string) func(session.ReadonlyState) map[string]any {
+	return func(state session.ReadonlyState) map[string]any {
+		v, _ := state.Get(key)
+		n, _ := v.(int)
+		return map[string]any{key: n + 1}
+	}
+}
+
+func countBelow(key string, limit int) graphagent.Condition {
+	return func(state session.ReadonlyState, _ *session.Event) bool {
+		v, _ := state.Get(key)
+		n, _ := v.(int)
+		return n < limit
+	}
+}
+
+type testRunner struct {
+	runner         *runner.Runner
+	sessionService session.Service
+}
+
+func newTestRunner(t *testing.T, a agent.Agent) *testRunner {
+	t.Helper()
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          a,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return &testRunner{runner: r, sessionService: sessionService}
+}
+
+// run runs the agent and returns the texts of the events, prefixed by their
+// branch.
+func (r *testRunner) run(t *testing.T) ([]string, error) {
+	t.Helper()
+	var got []string
+	for event, err := range r.runner.Run(t.Context(), "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			return got, err
+		}
+		if event.Content == nil {
+			continue
+		}
+		got = append(got, fmt.Sprintf("%s:%s", event.Branch, event.Content.Parts[0].Text))
+	}
+	return got, nil
+}
+
+func (r *testRunner) state(t *testing.T, key string) any {
+	t.Helper()
+	resp, err := r.sessionService.Get(t.Context(), &session.GetRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, _ := resp.Session.State().Get(key)
+	return v
+}
+
+func TestGraphAgent(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		nodes    func(t *testing.T) []agent.Agent
+		edges    []graphagent.Edge
+		want     []string
+		// unordered is set if nodes run in parallel.
+		unordered bool
+	}{
+		{
+			name: "conditional edge",
+			nodes: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newNode(t, "classify", &testNode{delta: setState("category", "billing")}),
+					newNode(t, "billing", &testNode{}),
+					newNode(t, "general", &testNode{}),
+				}
+			},
+			edges: []graphagent.Edge{
+				{From: "classify", To: []string{"billing"}, Condition: graphagent.StateEquals("category", "billing")},
+				{From: "classify", To: []string{"general"}},
+			},
+			want: []string{":classify", ":billing"},
+		},
+		{
+			name: "else edge",
+			nodes: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newNode(t, "classify", &testNode{delta: setState("category", "technical")}),
+					newNode(t, "billing", &testNode{}),
+					newNode(t, "general", &testNode{}),
+				}
+			},
+			edges: []graphagent.Edge{
+				{From: "classify", To: []string{"billing"}, Condition: graphagent.StateEquals("category", "billing")},
+				{From: "classify", To: []string{"general"}},
+			},
+			want: []string{":classify", ":general"},
+		},
+		{
+			name: "condition on last event",
+			nodes: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newNode(t, "classify", &testNode{}),
+					newNode(t, "billing", &testNode{}),
+				}
+			},
+			edges: []graphagent.Edge{
+				{From: "classify", To: []string{"billing"}, Condition: func(_ session.ReadonlyState, event *session.Event) bool {
+					return event != nil && event.Author == "classify"
+				}},
+			},
+			want: []string{":classify", ":billing"},
+		},
+		{
+			name: "fan-out and join",
+			nodes: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newNode(t, "plan", &testNode{}),
+					newNode(t, "research", &testNode{}),
+					newNode(t, "draft", &testNode{}),
+					newNode(t, "merge", &testNode{}),
+				}
+			},
+			edges: []graphagent.Edge{
+				{From: "plan", To: []string{"research", "draft"}},
+				{From: "research", To: []string{"merge"}},
+				{From: "draft", To: []string{"merge"}},
+			},
+			want:      []string{":plan", ":merge", "graph.draft:draft", "graph.research:research"},
+			unordered: true,
+		},
+		{
+			name: "bounded cycle",
+			nodes: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newNode(t, "refine", &testNode{delta: increment("count")}),
+					newNode(t, "publish", &testNode{}),
+				}
+			},
+			edges: []graphagent.Edge{
+				{From: "refine", To: []string{"refine"}, Condition: countBelow("count", 3)},
+				{From: "refine", To: []string{"publish"}},
+			},
+			want: []string{":refine", ":refine", ":refine", ":publish"},
+		},
+		{
+			name: "escalation ends run",
+			nodes: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newNode(t, "check", &testNode{escalate: true}),
+					newNode(t, "next", &testNode{}),
+				}
+			},
+			edges: []graphagent.Edge{
+				{From: "check", To: []string{"next"}},
+			},
+			want: []string{":check"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			graph, err := graphagent.New(graphagent.Config{
+				AgentConfig: agent.Config{
+					Name:      "graph",
+					SubAgents: tt.nodes(t),
+				},
+				Edges: tt.edges,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := newTestRunner(t, graph)
+
+			got, err := r.run(t)
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var opts []cmp.Option
+			if tt.unordered {
+				opts = append(opts, cmpopts.SortSlices(func(a, b string) bool { return a < b }))
+			}
+			if diff := cmp.Diff(tt.want, got, opts...); diff != "" {
+				t.Errorf("Run() events mismatch (-want +got):\n%s", diff)
+			}
+			if got := r.state(t, graphagent.CheckpointKey("graph")); got != nil {
+				t.Errorf("checkpoint = %v, want nil after the run", got)
+			}
+		})
+	}
+}
+
+func TestGraphAgent_MaxSteps(t *testing.T) {
+	loop := &testNode{}
+	graph, err := graphagent.New(graphagent.Config{
+		AgentConfig: agent.Config{
+			Name:      "graph",
+			SubAgents: []agent.Agent{newNode(t, "loop", loop)},
+		},
+		Edges:    []graphagent.Edge{{From: "loop", To: []string{"loop"}}},
+		MaxSteps: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRunner(t, graph)
+
+	if _, err := r.run(t); !errors.Is(err, graphagent.ErrMaxSteps) {
+		t.Errorf("Run() error = %v, want %v", err, graphagent.ErrMaxSteps)
+	}
+	if loop.runs != 3 {
+		t.Errorf("node runs = %d, want 3", loop.runs)
+	}
+	if got := r.state(t, graphagent.CheckpointKey("graph")); got != nil {
+		t.Errorf("checkpoint = %v, want nil", got)
+	}
+}
+
+func TestGraphAgent_ResumeFromCheckpoint(t *testing.T) {
+	first := &testNode{}
+	flaky := &testNode{failures: 1}
+	graph, err := graphagent.New(graphagent.Config{
+		AgentConfig: agent.Config{
+			Name: "graph",
+			SubAgents: []agent.Agent{
+				newNode(t, "first", first),
+				newNode(t, "flaky", flaky),
+				newNode(t, "last", &testNode{}),
+			},
+		},
+		Edges: []graphagent.Edge{
+			{From: "first", To: []string{"flaky"}},
+			{From: "flaky", To: []string{"last"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRunner(t, graph)
+
+	got, err := r.run(t)
+	if err == nil {
+		t.Fatal("first Run() succeeded, want the error of the flaky node")
+	}
+	if diff := cmp.Diff([]string{":first"}, got); diff != "" {
+		t.Errorf("first Run() events mismatch (-want +got):\n%s", diff)
+	}
+	wantCheckpoint := map[string]any{"nodes": []string{"flaky"}, "step": uint(1)}
+	if diff := cmp.Diff(wantCheckpoint, r.state(t, graphagent.CheckpointKey("graph"))); diff != "" {
+		t.Errorf("checkpoint mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = r.run(t)
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{":flaky", ":last"}, got); diff != "" {
+		t.Errorf("second Run() events mismatch (-want +got):\n%s", diff)
+	}
+	if first.runs != 1 {
+		t.Errorf("first node runs = %d, want 1", first.runs)
+	}
+}
+
+func TestGraphAgent_ResumeFanOut(t *testing.T) {
+	done := &testNode{}
+	wait := &testNode{pauses: 1}
+	graph, err := graphagent.New(graphagent.Config{
+		AgentConfig: agent.Config{
+			Name: "graph",
+			SubAgents: []agent.Agent{
+				newNode(t, "first", &testNode{}),
+				newNode(t, "done", done),
+				newNode(t, "wait", wait),
+				newNode(t, "after_done", &testNode{}),
+				newNode(t, "after_wait", &testNode{}),
+			},
+		},
+		Edges: []graphagent.Edge{
+			{From: "first", To: []string{"done", "wait"}},
+			{
+				From: "done",
+				To:   []string{"after_done"},
+				// The last event of the node is restored on resume.
+				Condition: func(_ session.ReadonlyState, event *session.Event) bool {
+					return event != nil && event.Content.Parts[0].Text == "done"
+				},
+			},
+			{From: "wait", To: []string{"after_wait"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newTestRunner(t, graph)
+	sortStrings := cmpopts.SortSlices(func(a, b string) bool { return a < b })
+
+	got, err := r.run(t)
+	if err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{":first", "graph.done:done", "graph.wait:wait"}, got, sortStrings); diff != "" {
+		t.Errorf("first Run() events mismatch (-want +got):\n%s", diff)
+	}
+	checkpoint, _ := r.state(t, graphagent.CheckpointKey("graph")).(map[string]any)
+	if diff := cmp.Diff([]string{"done"}, slices.Collect(maps.Keys(checkpoint["done"].(map[string]string)))); diff != "" {
+		t.Errorf("completed nodes of the checkpoint mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = r.run(t)
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"graph.wait:wait", "graph.after_done:after_done", "graph.after_wait:after_wait"}, got, sortStrings); diff != "" {
+		t.Errorf("second Run() events mismatch (-want +got):\n%s", diff)
+	}
+	if done.runs != 1 {
+		t.Errorf("completed node runs = %d, want 1", done.runs)
+	}
+	if wait.runs != 2 {
+		t.Errorf("paused node runs = %d, want 2", wait.runs)
+	}
+	if got := r.state(t, graphagent.CheckpointKey("graph")); got != nil {
+		t.Errorf("checkpoint = %v, want nil", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  func(t *testing.T) graphagent.Config
+	}{
+		{
+			name: "no sub-agents",
+			cfg: func(t *testing.T) graphagent.Config {
+				return graphagent.Config{AgentConfig: agent.Config{Name: "graph"}}
+			},
+		},
+		{
+			name: "unknown start",
+			cfg: func(t *testing.T) graphagent.Config {
+				return graphagent.Config{
+					AgentConfig: agent.Config{Name: "graph", SubAgents: []agent.Agent{newNode(t, "a", &testNode{})}},
+					Start:       "b",
+				}
+			},
+		},
+		{
+			name: "unknown source",
+			cfg: func(t *testing.T) graphagent.Config {
+				return graphagent.Config{
+					AgentConfig: agent.Config{Name: "graph", SubAgents: []agent.Agent{newNode(t, "a", &testNode{})}},
+					Edges:       []graphagent.Edge{{From: "b", To: []string{"a"}}},
+				}
+			},
+		},
+		{
+			name: "unknown target",
+			cfg: func(t *testing.T) graphagent.Config {
+				return graphagent.Config{
+					AgentConfig: agent.Config{Name: "graph", SubAgents: []agent.Agent{newNode(t, "a", &testNode{})}},
+					Edges:       []graphagent.Edge{{From: "a", To: []string{"b"}}},
+				}
+			},
+		},
+		{
+			name: "no targets",
+			cfg: func(t *testing.T) graphagent.Config {
+				return graphagent.Config{
+					AgentConfig: agent.Config{Name: "graph", SubAgents: []agent.Agent{newNode(t, "a", &testNode{})}},
+					Edges:       []graphagent.Edge{{From: "a"}},
+				}
+			},
+		},
+		{
+			name: "custom run",
+			cfg: func(t *testing.T) graphagent.Config {
+				return graphagent.Config{
+					AgentConfig: agent.Config{
+						Name:      "graph",
+						SubAgents: []agent.Agent{newNode(t, "a", &testNode{})},
+						Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+							return func(func(*session.Event, error) bool) {}
+						},
+					},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := graphagent.New(tt.cfg(t)); err == nil {
+				t.Error("New() succeeded, want error")
+			}
+		})
+	}
+}
@@ -19,9 +19,12 @@ package loopagent
 import (
 	"fmt"
 	"iter"
+	"maps"
+	"time"
 
 	"google.golang.org/adk/agent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/agent/checkpoint"
 	"google.golang.org/adk/session"
 )
 
@@ -33,6 +36,37 @@ type Config struct {
 	// If MaxIterations == 0, then LoopAgent runs indefinitely or until any
 	// sub-agent escalates.
 	MaxIterations uint
+
+	// ExitCondition is evaluated after each iteration with the session state
+	// and the last event of the iteration, which is nil if the iteration
+	// yielded no events. The loop ends if it returns true.
+	ExitCondition func(state session.ReadonlyState, event *session.Event) bool
+
+	// MaxDuration bounds the duration of the loop: no iteration starts after
+	// MaxDuration elapsed since the loop started. The running iteration is
+	// not interrupted. If 0, the duration is unbounded.
+	MaxDuration time.Duration
+}
+
+// IterationKey returns the temp state key holding the 0-based index of the
+// current iteration of the loop agent with the given name. Instructions can
+// reference it, e.g. as {temp:refine_iteration} for the agent "refine".
+func IterationKey(agentName string) string {
+	return session.KeyPrefixTemp + agentName + "_iteration"
+}
+
+// CheckpointKey returns the session state key under which the loop agent
+// with the given name checkpoints its progress: the current iteration and
+// the index of the next sub-agent to run. SequentialAgent, which is a loop
+// with one iteration, uses the same checkpoint.
+//
+// The checkpoint is updated after each sub-agent and set to nil when the loop
+// ends, since state deltas can't delete keys. If a run stops before, e.g.
+// because a sub-agent failed or is waiting for a long-running tool, the next
+// run resumes from the checkpoint without re-running the completed
+// sub-agents.
+func CheckpointKey(agentName string) string {
+	return "_adk_loopagent:" + agentName
 }
 
 // New creates a LoopAgent.
@@ -42,6 +76,13 @@ type Config struct {
 //
 // Use the LoopAgent when your workflow involves repetition or iterative
 // refinement, such as like revising code.
+//
+// After each iteration, LoopAgent yields an event with an IterationSummary
+// action reporting the progress of the loop.
+//
+// LoopAgent checkpoints its progress in the session state, so that a run
+// interrupted by a failure or a long-running tool resumes where it stopped.
+// See [CheckpointKey].
 func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Run != nil {
 		return nil, fmt.Errorf("LoopAgent doesn't allow custom Run implementations")
@@ -49,6 +90,8 @@ func New(cfg Config) (agent.Agent, error) {
 
 	loopAgentImpl := &loopAgent{
 		maxIterations: cfg.MaxIterations,
+		exitCondition: cfg.ExitCondition,
+		maxDuration:   cfg.MaxDuration,
 	}
 	cfg.AgentConfig.Run = loopAgentImpl.Run
 
@@ -70,36 +113,133 @@ func New(cfg Config) (agent.Agent, error) {
 
 type loopAgent struct {
 	maxIterations uint
+	exitCondition func(session.ReadonlyState, *session.Event) bool
+	maxDuration   time.Duration
 }
 
 func (a *loopAgent) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
-	count := a.maxIterations
+	// SequentialAgent is a loop with one iteration, which doesn't report it.
+	sequential := isSequential(ctx.Agent())
+	start := time.Now()
 
 	return func(yield func(*session.Event, error) bool) {
-		for {
+		key := CheckpointKey(ctx.Agent().Name())
+		subAgents := ctx.Agent().SubAgents()
+		iteration, next, checkpointed := a.restore(ctx.Session().State(), key, len(subAgents))
+		for ; ; iteration++ {
+			if !sequential {
+				if err := ctx.Session().State().Set(IterationKey(ctx.Agent().Name()), iteration); err != nil {
+					yield(nil, fmt.Errorf("failed to set iteration state: %w", err))
+					return
+				}
+			}
+
+			var last *session.Event
 			shouldExit := false
-			for _, subAgent := range ctx.Agent().SubAgents() {
-				for event, err := range subAgent.Run(ctx) {
-					// TODO: ensure consistency -- if there's an error, return and close iterator, verify everywhere in ADK.
-					if !yield(event, err) {
+			for i := next; i < len(subAgents); i++ {
+				// The last event of each agent and branch, since the
+				// sub-agent may be a workflow of several agents.
+				lastByAgent := make(map[string]*session.Event)
+				for event, err := range subAgents[i].Run(ctx) {
+					if !yield(event, err) || err != nil {
 						return
 					}
 
+					if !event.Partial {
+						last = event
+						lastByAgent[event.Author+"@"+event.Branch] = event
+					}
 					if event.Actions.Escalate {
 						shouldExit = true
 					}
 				}
-				if shouldExit {
+				if checkpoint.AnyPaused(maps.Values(lastByAgent)) {
+					// Keep the checkpoint, so the next run resumes the
+					// sub-agent.
 					return
 				}
+				if shouldExit {
+					break
+				}
+				if i+1 < len(subAgents) {
+					if !yield(checkpoint.Event(ctx, key, checkpointValue(iteration, i+1)), nil) {
+						return
+					}
+					checkpointed = true
+				}
 			}
+			next = 0
 
-			if count > 0 {
-				count--
-				if count == 0 {
-					return
+			done := shouldExit ||
+				(a.maxIterations > 0 && iteration+1 >= a.maxIterations) ||
+				(a.exitCondition != nil && a.exitCondition(ctx.Session().State(), last)) ||
+				(a.maxDuration > 0 && time.Since(start) >= a.maxDuration)
+
+			// The checkpoint moves to the next iteration, or is cleared.
+			var value any
+			if !done {
+				value = checkpointValue(iteration+1, 0)
+			}
+			var event *session.Event
+			switch {
+			case !sequential:
+				event = summaryEvent(ctx, iteration, a.maxIterations, done)
+				if value != nil || checkpointed {
+					event.Actions.StateDelta[key] = value
 				}
+			case value != nil || checkpointed:
+				event = checkpoint.Event(ctx, key, value)
+			}
+			checkpointed = value != nil
+			if event != nil && !yield(event, nil) {
+				return
+			}
+			if done {
+				return
 			}
 		}
 	}
 }
+
+// restore returns the iteration and the index of the sub-agent to run, from
+// the checkpoint if there is a valid one, and whether there is one.
+func (a *loopAgent) restore(state session.ReadonlyState, key string, numSubAgents int) (uint, int, bool) {
+	v, err := state.Get(key)
+	if err != nil {
+		return 0, 0, false
+	}
+	c, ok := v.(map[string]any)
+	if !ok {
+		return 0, 0, false
+	}
+	iteration, ok := checkpoint.Int(c["iteration"])
+	if !ok || iteration < 0 || (a.maxIterations > 0 && uint(iteration) >= a.maxIterations) {
+		return 0, 0, false
+	}
+	next, ok := checkpoint.Int(c["sub_agent"])
+	if !ok || next < 0 || next >= numSubAgents {
+		return 0, 0, false
+	}
+	return uint(iteration), next, true
+}
+
+func checkpointValue(iteration uint, subAgent int) map[string]any {
+	return map[string]any{"iteration": iteration, "sub_agent": subAgent}
+}
+
+func isSequential(a agent.Agent) bool {
+	internalAgent, ok := a.(agentinternal.Agent)
+	return ok && agentinternal.Reveal(internalAgent).AgentType == agentinternal.TypeSequentialAgent
+}
+
+func summaryEvent(ctx agent.InvocationContext, iteration, maxIterations uint, done bool) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.Actions.IterationSummary = &session.IterationSummary{
+		Iteration:     iteration,
+		MaxIterations: maxIterations,
+		Done:          done,
+	}
+	return event
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"iter"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -84,6 +85,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				summaryEvent(0, 1, true),
 			},
 		},
 		{
@@ -104,6 +106,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent(),
 				{
 					Author: "custom_agent_1",
 					LLMResponse: model.LLMResponse{
@@ -115,6 +118,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				summaryEvent(0, 1, true),
 			},
 		},
 		{
@@ -150,6 +154,7 @@ func TestNewLoopAgent(t *testing.T) {
 						},
 					},
 				},
+				summaryEvent(0, 2, true),
 			},
 		},
 		{
@@ -175,6 +180,7 @@ func TestNewLoopAgent(t *testing.T) {
 						SkipSummarization: true,
 					},
 				},
+				summaryEvent(0, 2, true),
 			},
 		},
 	}
@@ -249,6 +255,25 @@ func TestNewLoopAgent(t *testing.T) {
 	}
 }
 
+func summaryEvent(iteration, maxIterations uint, done bool) *session.Event {
+	return &session.Event{
+		Author: "test_agent",
+		Actions: session.EventActions{
+			IterationSummary: &session.IterationSummary{
+				Iteration:     iteration,
+				MaxIterations: maxIterations,
+				Done:          done,
+			},
+		},
+	}
+}
+
+// checkpointEvent returns the event updating the checkpoint of the loop,
+// whose state delta isn't compared.
+func checkpointEvent() *session.Event {
+	return &session.Event{Author: "test_agent"}
+}
+
 func newCustomAgent(t *testing.T, id int) agent.Agent {
 	t.Helper()
 
@@ -357,3 +382,230 @@ func (f *FakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, st
 		}
 	}
 }
+
+func TestLoopAgent_Termination(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxIterations uint
+		exitCondition func(session.ReadonlyState, *session.Event) bool
+		maxDuration   time.Duration
+		want          []string
+	}{
+		{
+			name: "exit condition on state",
+			exitCondition: func(state session.ReadonlyState, _ *session.Event) bool {
+				v, _ := state.Get("count")
+				return v == 2
+			},
+			want: []string{"iteration 0", "summary 0/0", "iteration 1", "summary 1/0 done"},
+		},
+		{
+			name:          "exit condition on last event",
+			maxIterations: 5,
+			exitCondition: func(_ session.ReadonlyState, event *session.Event) bool {
+				return event != nil && event.Content.Parts[0].Text == "iteration 2"
+			},
+			want: []string{"iteration 0", "summary 0/5", "iteration 1", "summary 1/5", "iteration 2", "summary 2/5 done"},
+		},
+		{
+			name:        "max duration",
+			maxDuration: time.Nanosecond,
+			want:        []string{"iteration 0", "summary 0/0 done"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loopAgent, err := loopagent.New(loopagent.Config{
+				AgentConfig: agent.Config{
+					Name:      "test_agent",
+					SubAgents: []agent.Agent{newIterationAgent(t)},
+				},
+				MaxIterations: tt.maxIterations,
+				ExitCondition: tt.exitCondition,
+				MaxDuration:   tt.maxDuration,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sessionService := session.InMemoryService()
+			agentRunner, err := runner.New(runner.Config{
+				AppName:        "test_app",
+				Agent:          loopAgent,
+				SessionService: sessionService,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+				AppName:   "test_app",
+				UserID:    "user_id",
+				SessionID: "session_id",
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for event, err := range agentRunner.Run(t.Context(), "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{}) {
+				if err != nil {
+					t.Fatalf("got unexpected error: %v", err)
+				}
+				if s := event.Actions.IterationSummary; s != nil {
+					line := fmt.Sprintf("summary %d/%d", s.Iteration, s.MaxIterations)
+					if s.Done {
+						line += " done"
+					}
+					got = append(got, line)
+					continue
+				}
+				got = append(got, event.Content.Parts[0].Text)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// newIterationAgent returns an agent reporting the iteration of the loop from
+// the temp state, and counting its runs in the "count" state key.
+func newIterationAgent(t *testing.T) agent.Agent {
+	t.Helper()
+
+	a, err := agent.New(agent.Config{
+		Name: "iteration_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				iteration, err := ctx.Session().State().Get(loopagent.IterationKey("test_agent"))
+				if err != nil {
+					yield(nil, err)
+					return
+				}
+				count, _ := ctx.Session().State().Get("count")
+				n, _ := count.(int)
+
+				event := session.NewEvent(ctx.InvocationID())
+				event.LLMResponse = model.LLMResponse{
+					Content: genai.NewContentFromText(fmt.Sprintf("iteration %v", iteration), genai.RoleModel),
+				}
+				event.Actions.StateDelta["count"] = n + 1
+				yield(event, nil)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestLoopAgent_ResumeFromCheckpoint(t *testing.T) {
+	first := &customAgent{id: 0}
+	flaky := &flakyAgent{failures: 1}
+	loopAgent, err := loopagent.New(loopagent.Config{
+		AgentConfig: agent.Config{
+			Name: "test_agent",
+			SubAgents: []agent.Agent{
+				must(agent.New(agent.Config{Name: "first", Run: first.Run})),
+				must(agent.New(agent.Config{Name: "flaky", Run: flaky.Run})),
+			},
+		},
+		MaxIterations: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	agentRunner, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          loopAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	run := func() ([]string, error) {
+		var got []string
+		for event, err := range agentRunner.Run(t.Context(), "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{}) {
+			if err != nil {
+				return got, err
+			}
+			if event.Content != nil {
+				got = append(got, event.Content.Parts[0].Text)
+			}
+		}
+		return got, nil
+	}
+	checkpoint := func() any {
+		resp, err := sessionService.Get(t.Context(), &session.GetRequest{
+			AppName:   "test_app",
+			UserID:    "user_id",
+			SessionID: "session_id",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := resp.Session.State().Get(loopagent.CheckpointKey("test_agent"))
+		return v
+	}
+
+	got, err := run()
+	if err == nil {
+		t.Fatal("first Run() succeeded, want the error of the flaky agent")
+	}
+	if diff := cmp.Diff([]string{"hello 0"}, got); diff != "" {
+		t.Errorf("first Run() events mismatch (-want +got):\n%s", diff)
+	}
+	wantCheckpoint := map[string]any{"iteration": uint(0), "sub_agent": 1}
+	if diff := cmp.Diff(wantCheckpoint, checkpoint()); diff != "" {
+		t.Errorf("checkpoint mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = run()
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"flaky", "hello 0", "flaky"}, got); diff != "" {
+		t.Errorf("second Run() events mismatch (-want +got):\n%s", diff)
+	}
+	if first.callCounter != 2 {
+		t.Errorf("first agent runs = %d, want 2", first.callCounter)
+	}
+	if got := checkpoint(); got != nil {
+		t.Errorf("checkpoint = %v, want nil", got)
+	}
+}
+
+// flakyAgent fails its first runs.
+type flakyAgent struct {
+	failures int
+	runs     int
+}
+
+func (a *flakyAgent) Run(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		a.runs++
+		if a.runs <= a.failures {
+			yield(nil, fmt.Errorf("run %d failed", a.runs))
+			return
+		}
+		yield(&session.Event{
+			LLMResponse: model.LLMResponse{
+				Content: genai.NewContentFromText("flaky", genai.RoleModel),
+			},
+		}, nil)
+	}
+}
+
+func must[T agent.Agent](a T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
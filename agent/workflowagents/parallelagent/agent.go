@@ -16,14 +16,21 @@
 package parallelagent
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"iter"
+	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
+	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	agentinternal "google.golang.org/adk/internal/agent"
+	"google.golang.org/adk/internal/agent/checkpoint"
 	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 )
 
@@ -31,6 +38,67 @@ import (
 type Config struct {
 	// Basic agent setup.
 	AgentConfig agent.Config
+
+	// MaxConcurrency limits the number of sub-agents running at the same
+	// time. If 0, all sub-agents run at once.
+	MaxConcurrency int
+	// CollectErrors selects how failures are handled. If false, the first
+	// error of a sub-agent is returned and cancels the other sub-agents. If
+	// true, the other sub-agents keep running, and the errors of all failed
+	// sub-agents are returned, joined, after all sub-agents end.
+	CollectErrors bool
+	// BranchTimeout bounds the duration of each sub-agent run. A sub-agent
+	// exceeding it fails with context.DeadlineExceeded. If 0, the duration is
+	// unbounded.
+	BranchTimeout time.Duration
+
+	// Aggregator, if set, merges the results of the sub-agents after they
+	// all end. The merged output is yielded as the content of an event of
+	// the ParallelAgent, so that a following agent sees one consolidated
+	// result instead of the outputs of the isolated branches.
+	Aggregator Aggregator
+	// OutputKey, if set, is the session state key storing the merged output.
+	// If Aggregator is nil, JoinOutputs merges the results.
+	OutputKey string
+}
+
+// BranchResult is the result of a sub-agent run.
+type BranchResult struct {
+	// Agent is the name of the sub-agent.
+	Agent string
+	// Output is the text of the last final response of the sub-agent, or
+	// empty if there is none.
+	Output string
+	// Err is the error of the sub-agent, if it failed.
+	Err error
+}
+
+// Aggregator merges the results of the sub-agents, in the order of the
+// sub-agents, into one output.
+type Aggregator func(ctx agent.ReadonlyContext, results []BranchResult) (string, error)
+
+// JoinOutputs is an Aggregator listing the non-empty outputs of the
+// sub-agents, each under the name of its sub-agent.
+func JoinOutputs(_ agent.ReadonlyContext, results []BranchResult) (string, error) {
+	var parts []string
+	for _, r := range results {
+		if r.Output != "" {
+			parts = append(parts, fmt.Sprintf("%s:\n%s", r.Agent, r.Output))
+		}
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// CheckpointKey returns the session state key under which the parallel agent
+// with the given name checkpoints the outputs of the sub-agents which
+// completed.
+//
+// The checkpoint is updated each time a sub-agent completes, and holds nil
+// once all of them did. If a run stops before, e.g. because a sub-agent failed or
+// is waiting for a long-running tool, the next run only runs the pending
+// sub-agents.
+func CheckpointKey(agentName string) string {
+	return "_adk_parallelagent:" + agentName
 }
 
 // New creates a ParallelAgent.
@@ -45,8 +113,24 @@ func New(cfg Config) (agent.Agent, error) {
 	if cfg.AgentConfig.Run != nil {
 		return nil, fmt.Errorf("ParallelAgent doesn't allow custom Run implementations")
 	}
+	if cfg.MaxConcurrency < 0 {
+		return nil, fmt.Errorf("MaxConcurrency must not be negative, got %d", cfg.MaxConcurrency)
+	}
+	if cfg.BranchTimeout < 0 {
+		return nil, fmt.Errorf("BranchTimeout must not be negative, got %v", cfg.BranchTimeout)
+	}
 
-	cfg.AgentConfig.Run = run
+	p := &parallelAgent{
+		maxConcurrency: cfg.MaxConcurrency,
+		collectErrors:  cfg.CollectErrors,
+		branchTimeout:  cfg.BranchTimeout,
+		aggregator:     cfg.Aggregator,
+		outputKey:      cfg.OutputKey,
+	}
+	if p.aggregator == nil && p.outputKey != "" {
+		p.aggregator = JoinOutputs
+	}
+	cfg.AgentConfig.Run = p.run
 
 	parallelAgent, err := agent.New(cfg.AgentConfig)
 	if err != nil {
@@ -64,57 +148,196 @@ func New(cfg Config) (agent.Agent, error) {
 	return parallelAgent, nil
 }
 
-func run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
-	curAgent := ctx.Agent()
+type parallelAgent struct {
+	maxConcurrency int
+	collectErrors  bool
+	branchTimeout  time.Duration
+	aggregator     Aggregator
+	outputKey      string
+}
 
-	var (
-		errGroup, errGroupCtx = errgroup.WithContext(ctx)
-		doneChan              = make(chan bool)
-		resultsChan           = make(chan result)
-	)
+func (p *parallelAgent) run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+	return func(yield func(*session.Event, error) bool) {
+		curAgent := ctx.Agent()
+		subAgents := curAgent.SubAgents()
+		key := CheckpointKey(curAgent.Name())
+		completed := restore(ctx.Session().State(), key, subAgents)
+		checkpointed := len(completed) > 0
+		var pending []int
+		for i, sa := range subAgents {
+			if _, ok := completed[sa.Name()]; !ok {
+				pending = append(pending, i)
+			}
+		}
 
-	for _, sa := range ctx.Agent().SubAgents() {
-		branch := fmt.Sprintf("%s.%s", curAgent.Name(), sa.Name())
-		if ctx.Branch() != "" {
-			branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+		var (
+			errGroup    = &errgroup.Group{}
+			groupCtx    = context.Context(ctx)
+			doneChan    = make(chan bool)
+			resultsChan = make(chan result)
+		)
+		if !p.collectErrors {
+			// The first error cancels the other sub-agents.
+			errGroup, groupCtx = errgroup.WithContext(ctx)
 		}
-		subAgent := sa
-		errGroup.Go(func() error {
-			subCtx := icontext.NewInvocationContext(errGroupCtx, icontext.InvocationContextParams{
-				Artifacts:   ctx.Artifacts(),
-				Memory:      ctx.Memory(),
-				Session:     ctx.Session(),
-				Branch:      branch,
-				Agent:       subAgent,
-				UserContent: ctx.UserContent(),
-				RunConfig:   ctx.RunConfig(),
-			})
-
-			if err := runSubAgent(subCtx, subAgent, resultsChan, doneChan); err != nil {
-				return fmt.Errorf("failed to run sub-agent %q: %w", subAgent.Name(), err)
-			}
+		if p.maxConcurrency > 0 {
+			errGroup.SetLimit(p.maxConcurrency)
+		}
+		defer close(doneChan)
 
-			return nil
-		})
-	}
+		// Sub-agents are started from another goroutine, since starting
+		// them blocks while MaxConcurrency sub-agents are running.
+		go func() {
+			for _, i := range pending {
+				sa := subAgents[i]
+				branch := fmt.Sprintf("%s.%s", curAgent.Name(), sa.Name())
+				if ctx.Branch() != "" {
+					branch = fmt.Sprintf("%s.%s", ctx.Branch(), branch)
+				}
+				subAgent := sa
+				errGroup.Go(func() error {
+					select {
+					case <-doneChan:
+						return nil
+					default:
+					}
 
-	go func() {
-		_ = errGroup.Wait() // this error is already sent to the user via iterator
-		close(resultsChan)
-	}()
+					branchCtx, cancel := groupCtx, context.CancelFunc(func() {})
+					if p.branchTimeout > 0 {
+						branchCtx, cancel = context.WithTimeout(groupCtx, p.branchTimeout)
+					}
+					defer cancel()
 
-	return func(yield func(*session.Event, error) bool) {
-		defer close(doneChan)
+					subCtx := icontext.NewInvocationContext(branchCtx, icontext.InvocationContextParams{
+						Artifacts:   ctx.Artifacts(),
+						Memory:      ctx.Memory(),
+						Credentials: ctx.Credentials(),
+						Session:     ctx.Session(),
+						Branch:      branch,
+						Agent:       subAgent,
+						UserContent: ctx.UserContent(),
+						RunConfig:   ctx.RunConfig(),
+					})
+
+					return runSubAgent(subCtx, i, subAgent, resultsChan, doneChan)
+				})
+			}
+			_ = errGroup.Wait() // this error is already sent to the user via iterator
+			close(resultsChan)
+		}()
 
+		results := make([]BranchResult, len(subAgents))
+		for i, sa := range subAgents {
+			results[i].Agent = sa.Name()
+			results[i].Output = completed[sa.Name()]
+		}
+		// The last event of each sub-agent.
+		last := make([]*session.Event, len(subAgents))
+		var errs []error
+		failed, paused := false, false
 		for res := range resultsChan {
-			if !yield(res.event, res.err) {
-				break
+			if res.done {
+				if checkpoint.Paused(last[res.index]) {
+					paused = true
+					continue
+				}
+				completed[subAgents[res.index].Name()] = results[res.index].Output
+				if len(completed) < len(subAgents) {
+					if !yield(checkpoint.Event(ctx, key, checkpointValue(completed)), nil) {
+						return
+					}
+					checkpointed = true
+				}
+				continue
+			}
+			if res.err != nil {
+				err := fmt.Errorf("failed to run sub-agent %q: %w", subAgents[res.index].Name(), res.err)
+				if p.collectErrors {
+					if results[res.index].Err == nil {
+						results[res.index].Err = err
+						errs = append(errs, err)
+					}
+					continue
+				}
+				failed = true
+				if !yield(nil, err) {
+					return
+				}
+				continue
+			}
+			if !res.event.Partial {
+				last[res.index] = res.event
 			}
+			if output, ok := finalOutput(res.event); ok {
+				results[res.index].Output = output
+			}
+			if !yield(res.event, nil) {
+				return
+			}
+		}
+
+		if failed {
+			// A sub-agent failed, and the error was already yielded.
+			return
+		}
+		// If a sub-agent is paused, the checkpoint is kept, so the next run
+		// resumes the sub-agents which didn't complete.
+		if !paused {
+			if checkpointed && len(errs) == 0 {
+				if !yield(checkpoint.Event(ctx, key, nil), nil) {
+					return
+				}
+			}
+			if p.aggregator != nil {
+				if !p.aggregate(ctx, results, yield) {
+					return
+				}
+			}
+		}
+		if len(errs) > 0 {
+			yield(nil, errors.Join(errs...))
+		}
+	}
+}
+
+// aggregate yields an event with the merged results. It returns false if the
+// run must stop.
+func (p *parallelAgent) aggregate(ctx agent.InvocationContext, results []BranchResult, yield func(*session.Event, error) bool) bool {
+	output, err := p.aggregator(icontext.NewReadonlyContext(ctx), results)
+	if err != nil {
+		yield(nil, fmt.Errorf("failed to aggregate the results of the sub-agents: %w", err))
+		return false
+	}
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.LLMResponse = model.LLMResponse{
+		Content: genai.NewContentFromText(output, genai.RoleModel),
+	}
+	if p.outputKey != "" {
+		event.Actions.StateDelta[p.outputKey] = output
+	}
+	return yield(event, nil)
+}
+
+// finalOutput returns the text of the event if it is a final response.
+func finalOutput(event *session.Event) (string, bool) {
+	if event == nil || event.Content == nil || !event.IsFinalResponse() {
+		return "", false
+	}
+	var sb strings.Builder
+	for _, part := range event.Content.Parts {
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
 		}
 	}
+	if sb.Len() == 0 {
+		return "", false
+	}
+	return sb.String(), true
 }
 
-func runSubAgent(ctx agent.InvocationContext, agent agent.Agent, results chan<- result, done <-chan bool) error {
+func runSubAgent(ctx agent.InvocationContext, index int, agent agent.Agent, results chan<- result, done <-chan bool) error {
 	for event, err := range agent.Run(ctx) {
 		select {
 		case <-done:
@@ -123,11 +346,13 @@ func runSubAgent(ctx agent.InvocationContext, agent agent.Agent, results chan<-
 			select {
 			case <-done:
 			case results <- result{
-				err: ctx.Err(),
+				index: index,
+				err:   ctx.Err(),
 			}:
 			}
 			return ctx.Err()
 		case results <- result{
+			index: index,
 			event: event,
 			err:   err,
 		}:
@@ -136,10 +361,45 @@ func runSubAgent(ctx agent.InvocationContext, agent agent.Agent, results chan<-
 			}
 		}
 	}
+	select {
+	case <-done:
+	case results <- result{index: index, done: true}:
+	}
 	return nil
 }
 
 type result struct {
+	index int
 	event *session.Event
 	err   error
+	// done is set once the sub-agent ended without error.
+	done bool
+}
+
+// restore returns the outputs of the completed sub-agents, by name, from the
+// checkpoint.
+func restore(state session.ReadonlyState, key string, subAgents []agent.Agent) map[string]string {
+	completed := make(map[string]string)
+	v, err := state.Get(key)
+	if err != nil {
+		return completed
+	}
+	c, ok := v.(map[string]any)
+	if !ok {
+		return completed
+	}
+	for _, sa := range subAgents {
+		if output, ok := c[sa.Name()].(string); ok {
+			completed[sa.Name()] = output
+		}
+	}
+	return completed
+}
+
+func checkpointValue(completed map[string]string) map[string]any {
+	c := make(map[string]any, len(completed))
+	for name, output := range completed {
+		c[name] = output
+	}
+	return c
 }
@@ -16,10 +16,13 @@ package parallelagent_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	rand "math/rand/v2"
 	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -135,6 +138,14 @@ func TestNewParallelAgent(t *testing.T) {
 					}
 					t.Errorf("got unexpected error: %v", err)
 				}
+				// The iteration summaries of the loop sub-agents are tested
+				// by loopagent.
+				if event != nil && event.Actions.IterationSummary != nil {
+					continue
+				}
+				if isCheckpoint(event) {
+					continue
+				}
 
 				gotEvents = append(gotEvents, event)
 			}
@@ -224,3 +235,352 @@ func customRun(id int, agentErr error) func(agent.InvocationContext) iter.Seq2[*
 		}
 	}
 }
+
+func TestParallelAgent_Options(t *testing.T) {
+	errFirst := errors.New("first failed")
+	errSecond := errors.New("second failed")
+
+	tests := []struct {
+		name      string
+		cfg       parallelagent.Config
+		subAgents func(t *testing.T) []agent.Agent
+		// wantTexts are the texts of the events, sorted.
+		wantTexts []string
+		wantState map[string]any
+		wantErrs  []error
+	}{
+		{
+			name: "aggregate into state",
+			cfg:  parallelagent.Config{OutputKey: "results"},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{newTextAgent(t, "first", "a"), newTextAgent(t, "second", "b")}
+			},
+			wantTexts: []string{"a", "b", "first:\na\n\nsecond:\nb"},
+			wantState: map[string]any{"results": "first:\na\n\nsecond:\nb"},
+		},
+		{
+			name: "custom aggregator",
+			cfg: parallelagent.Config{
+				Aggregator: func(_ agent.ReadonlyContext, results []parallelagent.BranchResult) (string, error) {
+					var outputs []string
+					for _, r := range results {
+						outputs = append(outputs, r.Output)
+					}
+					return strings.Join(outputs, "+"), nil
+				},
+			},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{newTextAgent(t, "first", "a"), newTextAgent(t, "second", "b")}
+			},
+			wantTexts: []string{"a", "a+b", "b"},
+		},
+		{
+			name: "fail fast",
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{newErrorAgent(t, "first", errFirst), newBlockingAgent(t, "second")}
+			},
+			wantErrs: []error{errFirst, context.Canceled},
+		},
+		{
+			name: "collect errors",
+			cfg: parallelagent.Config{
+				CollectErrors: true,
+				Aggregator: func(_ agent.ReadonlyContext, results []parallelagent.BranchResult) (string, error) {
+					var failed []string
+					for _, r := range results {
+						if r.Err != nil {
+							failed = append(failed, r.Agent)
+						}
+					}
+					return "failed: " + strings.Join(failed, ","), nil
+				},
+			},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{
+					newErrorAgent(t, "first", errFirst),
+					newTextAgent(t, "ok", "a"),
+					newErrorAgent(t, "second", errSecond),
+				}
+			},
+			wantTexts: []string{"a", "failed: first,second"},
+			wantErrs:  []error{errors.Join(errFirst, errSecond)},
+		},
+		{
+			name: "branch timeout",
+			cfg:  parallelagent.Config{BranchTimeout: 10 * time.Millisecond, CollectErrors: true},
+			subAgents: func(t *testing.T) []agent.Agent {
+				return []agent.Agent{newBlockingAgent(t, "slow"), newTextAgent(t, "fast", "a")}
+			},
+			wantTexts: []string{"a"},
+			wantErrs:  []error{context.DeadlineExceeded},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.AgentConfig = agent.Config{Name: "test_agent", SubAgents: tt.subAgents(t)}
+			parallelAgent, err := parallelagent.New(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var gotTexts []string
+			var gotErrs []error
+			state := map[string]any{}
+			for event, err := range runAgent(t, parallelAgent) {
+				if err != nil {
+					gotErrs = append(gotErrs, err)
+					continue
+				}
+				if event.Content != nil {
+					gotTexts = append(gotTexts, event.Content.Parts[0].Text)
+				}
+				if isCheckpoint(event) {
+					continue
+				}
+				for k, v := range event.Actions.StateDelta {
+					state[k] = v
+				}
+			}
+
+			slices.Sort(gotTexts)
+			if diff := cmp.Diff(tt.wantTexts, gotTexts); diff != "" {
+				t.Errorf("event texts mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantState != nil {
+				if diff := cmp.Diff(tt.wantState, state); diff != "" {
+					t.Errorf("state mismatch (-want +got):\n%s", diff)
+				}
+			}
+			if len(gotErrs) != len(tt.wantErrs) {
+				t.Fatalf("got errors %v, want %v", gotErrs, tt.wantErrs)
+			}
+			for i, want := range tt.wantErrs {
+				for _, w := range unwrapJoined(want) {
+					if !errors.Is(gotErrs[i], w) {
+						t.Errorf("error[%d] = %v, want it to wrap %v", i, gotErrs[i], w)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParallelAgent_MaxConcurrency(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	var subAgents []agent.Agent
+	for i := range 5 {
+		subAgents = append(subAgents, must(agent.New(agent.Config{
+			Name: fmt.Sprintf("sub%d", i),
+			Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+				return func(yield func(*session.Event, error) bool) {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						m := maxRunning.Load()
+						if n <= m || maxRunning.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					yield(&session.Event{
+						LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("done", genai.RoleModel)},
+					}, nil)
+				}
+			},
+		})))
+	}
+	parallelAgent, err := parallelagent.New(parallelagent.Config{
+		AgentConfig:    agent.Config{Name: "test_agent", SubAgents: subAgents},
+		MaxConcurrency: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	for event, err := range runAgent(t, parallelAgent) {
+		if err != nil {
+			t.Fatalf("got unexpected error: %v", err)
+		}
+		if isCheckpoint(event) {
+			continue
+		}
+		count++
+	}
+	if count != 5 {
+		t.Errorf("got %d events, want 5", count)
+	}
+	if got := maxRunning.Load(); got != 2 {
+		t.Errorf("max concurrent sub-agents = %d, want 2", got)
+	}
+}
+
+func TestParallelAgent_ResumeFromCheckpoint(t *testing.T) {
+	var okRuns, flakyRuns int
+	ok := must(agent.New(agent.Config{
+		Name: "ok",
+		Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				okRuns++
+				yield(&session.Event{
+					LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("a", genai.RoleModel)},
+				}, nil)
+			}
+		},
+	}))
+	flaky := must(agent.New(agent.Config{
+		Name: "flaky",
+		Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				flakyRuns++
+				if flakyRuns == 1 {
+					yield(nil, errors.New("flaky failed"))
+					return
+				}
+				yield(&session.Event{
+					LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("b", genai.RoleModel)},
+				}, nil)
+			}
+		},
+	}))
+	parallelAgent, err := parallelagent.New(parallelagent.Config{
+		AgentConfig:   agent.Config{Name: "test_agent", SubAgents: []agent.Agent{ok, flaky}},
+		CollectErrors: true,
+		OutputKey:     "out",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionService := session.InMemoryService()
+	agentRunner, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          parallelAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	run := func() error {
+		var runErr error
+		for _, err := range agentRunner.Run(t.Context(), "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{}) {
+			if err != nil {
+				runErr = err
+			}
+		}
+		return runErr
+	}
+	state := func(key string) any {
+		resp, err := sessionService.Get(t.Context(), &session.GetRequest{
+			AppName:   "test_app",
+			UserID:    "user_id",
+			SessionID: "session_id",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := resp.Session.State().Get(key)
+		return v
+	}
+
+	if err := run(); err == nil {
+		t.Fatal("first Run() succeeded, want the error of the flaky agent")
+	}
+	if diff := cmp.Diff(map[string]any{"ok": "a"}, state(parallelagent.CheckpointKey("test_agent"))); diff != "" {
+		t.Errorf("checkpoint mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := run(); err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if okRuns != 1 {
+		t.Errorf("ok agent runs = %d, want 1", okRuns)
+	}
+	if got, want := state("out"), "ok:\na\n\nflaky:\nb"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+	if got := state(parallelagent.CheckpointKey("test_agent")); got != nil {
+		t.Errorf("checkpoint = %v, want nil", got)
+	}
+}
+
+// isCheckpoint reports whether the event updates the checkpoint of the
+// parallel agent "test_agent".
+func isCheckpoint(event *session.Event) bool {
+	if event == nil {
+		return false
+	}
+	_, ok := event.Actions.StateDelta[parallelagent.CheckpointKey("test_agent")]
+	return ok
+}
+
+func unwrapJoined(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func runAgent(t *testing.T, a agent.Agent) iter.Seq2[*session.Event, error] {
+	t.Helper()
+	sessionService := session.InMemoryService()
+	agentRunner, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          a,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return agentRunner.Run(t.Context(), "user_id", "session_id", genai.NewContentFromText("user input", genai.RoleUser), agent.RunConfig{})
+}
+
+func newTextAgent(t *testing.T, name, text string) agent.Agent {
+	t.Helper()
+	return must(agent.New(agent.Config{
+		Name: name,
+		Run: func(agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				yield(&session.Event{
+					LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, genai.RoleModel)},
+				}, nil)
+			}
+		},
+	}))
+}
+
+func newErrorAgent(t *testing.T, name string, err error) agent.Agent {
+	t.Helper()
+	return must(agent.New(agent.Config{
+		Name: name,
+		Run:  customRun(-1, err),
+	}))
+}
+
+// newBlockingAgent returns an agent running until its context is done.
+func newBlockingAgent(t *testing.T, name string) agent.Agent {
+	t.Helper()
+	return must(agent.New(agent.Config{
+		Name: name,
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				<-ctx.Done()
+				yield(nil, ctx.Err())
+			}
+		},
+	}))
+}
@@ -29,6 +29,10 @@ import (
 //
 // Use the SequentialAgent when you want the execution to occur in a fixed,
 // strict order.
+//
+// If a run stops before the last sub-agent, e.g. because a sub-agent is
+// waiting for a long-running tool, the next run resumes from that sub-agent.
+// See [loopagent.CheckpointKey].
 func New(cfg Config) (agent.Agent, error) {
 	sequentialAgent, err := loopagent.New(loopagent.Config{
 		AgentConfig:   cfg.AgentConfig,
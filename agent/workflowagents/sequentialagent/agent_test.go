@@ -26,10 +26,14 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/loopagent"
 	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
 )
 
 func TestNewSequentialAgent(t *testing.T) {
@@ -65,6 +69,7 @@ func TestNewSequentialAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent("test_agent"),
 				{
 					Author: "custom_agent_1",
 					LLMResponse: model.LLMResponse{
@@ -76,6 +81,7 @@ func TestNewSequentialAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent("test_agent"),
 			},
 		},
 		{
@@ -96,6 +102,7 @@ func TestNewSequentialAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent("test_agent"),
 				{
 					Author: "custom_agent_1",
 					LLMResponse: model.LLMResponse{
@@ -107,6 +114,7 @@ func TestNewSequentialAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent("test_agent1"),
 				{
 					Author: "custom_agent_2",
 					LLMResponse: model.LLMResponse{
@@ -118,6 +126,8 @@ func TestNewSequentialAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent("test_agent1"),
+				checkpointEvent("test_agent"),
 				{
 					Author: "custom_agent_3",
 					LLMResponse: model.LLMResponse{
@@ -129,6 +139,7 @@ func TestNewSequentialAgent(t *testing.T) {
 						},
 					},
 				},
+				checkpointEvent("test_agent"),
 			},
 		},
 		{
@@ -263,6 +274,104 @@ func TestNewSequentialAgent(t *testing.T) {
 	}
 }
 
+func TestSequentialAgent_ResumeAfterLongRunningTool(t *testing.T) {
+	approve, err := functiontool.New(functiontool.Config{
+		Name:          "approve",
+		Description:   "Requests the approval of a human.",
+		IsLongRunning: true,
+	}, func(tool.Context, struct{}) (map[string]any, error) {
+		// The approval is sent later by the client.
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prepare := &FakeLLM{id: 0}
+	approver, err := llmagent.New(llmagent.Config{
+		Name: "approver",
+		Model: &testutil.MockModel{Responses: []*genai.Content{
+			genai.NewContentFromFunctionCall("approve", map[string]any{}, genai.RoleModel),
+			genai.NewContentFromText("approved", genai.RoleModel),
+		}},
+		Tools: []tool.Tool{approve},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	prepareAgent, err := llmagent.New(llmagent.Config{Name: "prepare", Model: prepare})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sequentialAgent := newSequentialAgent(t, []agent.Agent{prepareAgent, approver, newCustomAgent(t, 1)}, "test_agent")
+
+	sessionService := session.InMemoryService()
+	agentRunner, err := runner.New(runner.Config{
+		AppName:        "test_app",
+		Agent:          sequentialAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessionService.Create(t.Context(), &session.CreateRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	run := func(msg *genai.Content) (texts []string, callID string) {
+		for event, err := range agentRunner.Run(t.Context(), "user_id", "session_id", msg, agent.RunConfig{}) {
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(event.LongRunningToolIDs) > 0 {
+				callID = event.LongRunningToolIDs[0]
+			}
+			if event.Content != nil && event.Content.Parts[0].Text != "" {
+				texts = append(texts, fmt.Sprintf("%s:%s", event.Author, event.Content.Parts[0].Text))
+			}
+		}
+		return texts, callID
+	}
+
+	got, callID := run(genai.NewContentFromText("user input", genai.RoleUser))
+	if diff := cmp.Diff([]string{"prepare:hello 0"}, got); diff != "" {
+		t.Errorf("first Run() events mismatch (-want +got):\n%s", diff)
+	}
+	if callID == "" {
+		t.Fatal("first Run() didn't call the long-running tool")
+	}
+
+	response := genai.NewContentFromFunctionResponse("approve", map[string]any{"approved": true}, genai.RoleUser)
+	response.Parts[0].FunctionResponse.ID = callID
+	got, _ = run(response)
+	if diff := cmp.Diff([]string{"approver:approved", "custom_agent_1:hello 1"}, got); diff != "" {
+		t.Errorf("second Run() events mismatch (-want +got):\n%s", diff)
+	}
+	if prepare.callCounter != 1 {
+		t.Errorf("prepare agent runs = %d, want 1", prepare.callCounter)
+	}
+
+	resp, err := sessionService.Get(t.Context(), &session.GetRequest{
+		AppName:   "test_app",
+		UserID:    "user_id",
+		SessionID: "session_id",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := resp.Session.State().Get(loopagent.CheckpointKey("test_agent")); v != nil {
+		t.Errorf("checkpoint = %v, want nil", v)
+	}
+}
+
+// checkpointEvent returns an event updating the checkpoint of the sequential
+// agent, whose state delta isn't compared.
+func checkpointEvent(author string) *session.Event {
+	return &session.Event{Author: author}
+}
+
 func newCustomAgent(t *testing.T, id int) agent.Agent {
 	t.Helper()
 
@@ -0,0 +1,103 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package auth defines the types used by tools to authenticate to the APIs
+// they call: the auth schemes of the APIs, the credentials of the users and
+// the services storing them.
+//
+// A tool needing a credential calls tool.Context.Credential with the
+// [Config] of the API. If no credential is available yet, it calls
+// tool.Context.RequestCredential and returns. The agent then emits an event
+// calling the [RequestCredentialFunctionName] function, which the client
+// answers with the config completed by the user, e.g. with the OAuth2
+// authorization response. The agent exchanges it for a credential, stores it
+// in the [CredentialService] and runs the tool again.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// RequestCredentialFunctionName is the name of the function called by the
+// events requesting credentials from the client. The arguments of the call
+// are [RequestCredentialArgs], and the client responds with the [Config]
+// completed by the user.
+const RequestCredentialFunctionName = "adk_request_credential"
+
+// RequestCredentialArgs are the arguments of the calls requesting
+// credentials.
+type RequestCredentialArgs struct {
+	// FunctionCallID is the ID of the function call of the tool requesting
+	// the credential.
+	FunctionCallID string `json:"functionCallId"`
+	// Config is the requested auth config.
+	Config *Config `json:"authConfig"`
+}
+
+// Config describes how to authenticate to an API.
+type Config struct {
+	// Scheme is the auth scheme of the API. Required.
+	Scheme *Scheme `json:"authScheme"`
+	// RawCredential is the credential provided by the tool, e.g. the client
+	// ID and secret of an OAuth2 client.
+	RawCredential *Credential `json:"rawAuthCredential,omitempty"`
+	// ExchangedCredential is the credential completed by the client, e.g.
+	// with the OAuth2 authorization response.
+	ExchangedCredential *Credential `json:"exchangedAuthCredential,omitempty"`
+	// CredentialKey identifies the credential in the CredentialService.
+	// If empty, Key derives it from the scheme and the raw credential.
+	CredentialKey string `json:"credentialKey,omitempty"`
+}
+
+// Key returns the key of the credential in the CredentialService.
+func (c *Config) Key() string {
+	if c.CredentialKey != "" {
+		return c.CredentialKey
+	}
+	var b strings.Builder
+	b.WriteString("adk_")
+	if c.Scheme != nil {
+		b.WriteString(string(c.Scheme.Type))
+		b.WriteString("_")
+		b.WriteString(hash(c.Scheme))
+	}
+	if c.RawCredential != nil {
+		b.WriteString("_")
+		b.WriteString(string(c.RawCredential.Type))
+		b.WriteString("_")
+		b.WriteString(hash(c.RawCredential))
+	}
+	return b.String()
+}
+
+func hash(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Clone returns a deep copy of the config.
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+	// The config only contains JSON values.
+	data, _ := json.Marshal(c)
+	var clone Config
+	_ = json.Unmarshal(data, &clone)
+	return &clone
+}
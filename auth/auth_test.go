@@ -0,0 +1,67 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/auth"
+)
+
+func TestInMemoryCredentialService(t *testing.T) {
+	service := auth.InMemoryCredentialService()
+	cred := &auth.Credential{Type: auth.CredentialAPIKey, APIKey: "key"}
+
+	if err := service.Save(t.Context(), &auth.SaveRequest{AppName: "app", UserID: "user1", Key: "k", Credential: cred}); err != nil {
+		t.Fatalf("Save() failed: %v", err)
+	}
+	got, err := service.Load(t.Context(), &auth.LoadRequest{AppName: "app", UserID: "user1", Key: "k"})
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	if diff := cmp.Diff(cred, got); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+	// Credentials are stored per user.
+	got, err = service.Load(t.Context(), &auth.LoadRequest{AppName: "app", UserID: "user2", Key: "k"})
+	if err != nil || got != nil {
+		t.Errorf("Load() of another user = (%v, %v), want (nil, nil)", got, err)
+	}
+	if _, err := service.Load(t.Context(), &auth.LoadRequest{AppName: "app", Key: "k"}); err == nil {
+		t.Error("Load() without user ID succeeded, want error")
+	}
+}
+
+func TestConfig_Key(t *testing.T) {
+	cfg := &auth.Config{
+		Scheme:        &auth.Scheme{Type: auth.SchemeAPIKey, In: auth.InHeader, Name: "X-Key"},
+		RawCredential: &auth.Credential{Type: auth.CredentialAPIKey, APIKey: "key"},
+	}
+	other := cfg.Clone()
+	other.RawCredential.APIKey = "other"
+
+	if cfg.Key() == other.Key() {
+		t.Errorf("configs with different credentials have the same key %q", cfg.Key())
+	}
+	if got, want := cfg.Clone().Key(), cfg.Key(); got != want {
+		t.Errorf("Key() of clone = %q, want %q", got, want)
+	}
+	other.CredentialKey = "custom"
+	if got, want := other.Key(), "custom"; got != want {
+		t.Errorf("Key() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import "time"
+
+// CredentialType is the type of a credential.
+type CredentialType string
+
+const (
+	// CredentialAPIKey is an API key.
+	CredentialAPIKey CredentialType = "apiKey"
+	// CredentialHTTP is a credential of an HTTP authentication scheme, e.g.
+	// a bearer token.
+	CredentialHTTP CredentialType = "http"
+	// CredentialOAuth2 is an OAuth2 client credential or token.
+	CredentialOAuth2 CredentialType = "oauth2"
+)
+
+// Credential is the credential of a user, or of the agent, for an API.
+type Credential struct {
+	// Type of the credential.
+	Type CredentialType `json:"authType"`
+	// APIKey is the key of an API key credential.
+	APIKey string `json:"apiKey,omitempty"`
+	// HTTP is the credential of an HTTP authentication scheme.
+	HTTP *HTTPAuth `json:"http,omitempty"`
+	// OAuth2 is the credential of an OAuth2 scheme.
+	OAuth2 *OAuth2Auth `json:"oauth2,omitempty"`
+}
+
+// HTTPAuth is the credential of an HTTP authentication scheme.
+type HTTPAuth struct {
+	// Scheme is the name of the scheme, e.g. "bearer" or "basic".
+	Scheme string `json:"scheme"`
+	// Credentials are the credentials for the scheme.
+	Credentials HTTPCredentials `json:"credentials"`
+}
+
+// HTTPCredentials are the credentials of an HTTP authentication scheme.
+type HTTPCredentials struct {
+	// Username and Password are used by the basic scheme.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Token is used by the bearer scheme.
+	Token string `json:"token,omitempty"`
+}
+
+// OAuth2Auth is the credential of an OAuth2 scheme. The client ID and
+// secret are set by the tool, the authorization response by the client, and
+// the tokens by the exchange.
+type OAuth2Auth struct {
+	ClientID string `json:"clientId,omitempty"`
+	// ClientSecret is removed from the configs sent to the client to request
+	// the credential, and restored from the config of the tool.
+	ClientSecret string `json:"clientSecret,omitempty"`
+	// AuthURI is the authorization URL the user must visit, set when the
+	// credential is requested.
+	AuthURI string `json:"authUri,omitempty"`
+	// State is the state parameter of the authorization URL.
+	State       string `json:"state,omitempty"`
+	RedirectURI string `json:"redirectUri,omitempty"`
+	// AuthResponseURI is the URL the user was redirected to after the
+	// authorization, containing the authorization code.
+	AuthResponseURI string `json:"authResponseUri,omitempty"`
+	// AuthCode is the authorization code, if the client extracted it from
+	// the authorization response.
+	AuthCode     string `json:"authCode,omitempty"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+	// ExpiresAt is the expiration time of the access token, in seconds since
+	// the Unix epoch. 0 if it doesn't expire.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+}
+
+// expired reports whether the access token expired, with a margin of a
+// minute.
+func (a *OAuth2Auth) expired(now time.Time) bool {
+	return a.ExpiresAt != 0 && now.Add(time.Minute).Unix() >= a.ExpiresAt
+}
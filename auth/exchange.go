@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// NewRequest returns the config sent to the client to request the
+// credential described by cfg. For the OAuth2 authorization code flow, its
+// exchanged credential contains the authorization URL the user must visit.
+func NewRequest(cfg *Config) (*Config, error) {
+	if cfg == nil || cfg.Scheme == nil {
+		return nil, fmt.Errorf("auth scheme is required")
+	}
+	req := cfg.Clone()
+	// The client may change the credentials of the config, so the key is
+	// fixed before sending it.
+	req.CredentialKey = cfg.Key()
+	flow := authorizationCodeFlow(cfg.Scheme)
+	if flow == nil || (req.ExchangedCredential != nil && req.ExchangedCredential.OAuth2 != nil && req.ExchangedCredential.OAuth2.AuthURI != "") {
+		return req, nil
+	}
+	if req.RawCredential == nil || req.RawCredential.OAuth2 == nil || req.RawCredential.OAuth2.ClientID == "" {
+		return nil, fmt.Errorf("OAuth2 client ID is required")
+	}
+	exchanged := cfg.Clone().RawCredential
+	exchanged.OAuth2.State = rand.Text()
+	exchanged.OAuth2.AuthURI = oauth2Config(flow, exchanged.OAuth2).AuthCodeURL(exchanged.OAuth2.State, oauth2.AccessTypeOffline)
+	req.ExchangedCredential = exchanged
+	return req, nil
+}
+
+// Exchange returns the credential to call the API with, from the config
+// completed by the client. OAuth2 authorization responses are exchanged for
+// tokens.
+func Exchange(ctx context.Context, cfg *Config) (*Credential, error) {
+	if cfg == nil || cfg.Scheme == nil {
+		return nil, fmt.Errorf("auth scheme is required")
+	}
+	cred := cfg.ExchangedCredential
+	if cred == nil {
+		cred = cfg.RawCredential
+	}
+	if cred == nil {
+		return nil, fmt.Errorf("no credential provided")
+	}
+	if cred.Type != CredentialOAuth2 || cred.OAuth2 == nil || cred.OAuth2.AccessToken != "" {
+		return cred, nil
+	}
+	flow := authorizationCodeFlow(cfg.Scheme)
+	if flow == nil {
+		resolved, err := Resolve(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if resolved == nil {
+			return nil, fmt.Errorf("no OAuth2 flow to exchange the credential")
+		}
+		return resolved, nil
+	}
+	code, err := authorizationCode(cred.OAuth2)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth2Config(flow, cred.OAuth2).Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	return fromToken(cred, token), nil
+}
+
+// Resolve returns the credential of cfg if it is usable without user
+// interaction: an API key or HTTP credential provided by the tool, or a token
+// of the OAuth2 client credentials flow. It returns nil otherwise.
+func Resolve(ctx context.Context, cfg *Config) (*Credential, error) {
+	if cfg == nil || cfg.Scheme == nil || cfg.RawCredential == nil {
+		return nil, nil
+	}
+	raw := cfg.RawCredential
+	switch raw.Type {
+	case CredentialAPIKey, CredentialHTTP:
+		return raw, nil
+	case CredentialOAuth2:
+		if cfg.Scheme.Flows == nil || cfg.Scheme.Flows.ClientCredentials == nil || raw.OAuth2 == nil {
+			return nil, nil
+		}
+		flow := cfg.Scheme.Flows.ClientCredentials
+		conf := &clientcredentials.Config{
+			ClientID:     raw.OAuth2.ClientID,
+			ClientSecret: raw.OAuth2.ClientSecret,
+			TokenURL:     flow.TokenURL,
+			Scopes:       scopes(flow),
+		}
+		token, err := conf.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client credentials token: %w", err)
+		}
+		return fromToken(raw, token), nil
+	}
+	return nil, nil
+}
+
+// Refresh returns the credential with a new access token if its OAuth2
+// access token expired, or the credential itself otherwise. It returns nil if
+// the token expired and can't be refreshed.
+func Refresh(ctx context.Context, scheme *Scheme, cred *Credential) (*Credential, error) {
+	if cred == nil || cred.OAuth2 == nil || !cred.OAuth2.expired(time.Now()) {
+		return cred, nil
+	}
+	flow := authorizationCodeFlow(scheme)
+	if flow == nil || cred.OAuth2.RefreshToken == "" {
+		return nil, nil
+	}
+	conf := oauth2Config(flow, cred.OAuth2)
+	if flow.RefreshURL != "" {
+		conf.Endpoint.TokenURL = flow.RefreshURL
+	}
+	token, err := conf.TokenSource(ctx, &oauth2.Token{
+		RefreshToken: cred.OAuth2.RefreshToken,
+		Expiry:       time.Unix(cred.OAuth2.ExpiresAt, 0),
+	}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	refreshed := fromToken(cred, token)
+	if refreshed.OAuth2.RefreshToken == "" {
+		refreshed.OAuth2.RefreshToken = cred.OAuth2.RefreshToken
+	}
+	return refreshed, nil
+}
+
+func authorizationCodeFlow(scheme *Scheme) *OAuthFlow {
+	if scheme == nil || scheme.Type != SchemeOAuth2 || scheme.Flows == nil {
+		return nil
+	}
+	return scheme.Flows.AuthorizationCode
+}
+
+// authorizationCode returns the authorization code of the authorization
+// response.
+func authorizationCode(a *OAuth2Auth) (string, error) {
+	if a.AuthCode != "" {
+		return a.AuthCode, nil
+	}
+	if a.AuthResponseURI == "" {
+		return "", fmt.Errorf("no authorization response")
+	}
+	u, err := url.Parse(a.AuthResponseURI)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization response: %w", err)
+	}
+	q := u.Query()
+	if e := q.Get("error"); e != "" {
+		return "", fmt.Errorf("authorization failed: %s", e)
+	}
+	if a.State != "" && q.Get("state") != a.State {
+		return "", errors.New("authorization response state doesn't match the request")
+	}
+	code := q.Get("code")
+	if code == "" {
+		return "", fmt.Errorf("no authorization code in the authorization response")
+	}
+	return code, nil
+}
+
+func oauth2Config(flow *OAuthFlow, a *OAuth2Auth) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     a.ClientID,
+		ClientSecret: a.ClientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  flow.AuthorizationURL,
+			TokenURL: flow.TokenURL,
+		},
+		RedirectURL: a.RedirectURI,
+		Scopes:      scopes(flow),
+	}
+}
+
+func scopes(flow *OAuthFlow) []string {
+	var s []string
+	for scope := range flow.Scopes {
+		s = append(s, scope)
+	}
+	slices.Sort(s)
+	return s
+}
+
+// fromToken returns a copy of the credential with the token, without the
+// authorization request and response.
+func fromToken(cred *Credential, token *oauth2.Token) *Credential {
+	result := &Credential{
+		Type: CredentialOAuth2,
+		OAuth2: &OAuth2Auth{
+			ClientID:     cred.OAuth2.ClientID,
+			ClientSecret: cred.OAuth2.ClientSecret,
+			RedirectURI:  cred.OAuth2.RedirectURI,
+			AccessToken:  token.AccessToken,
+			RefreshToken: token.RefreshToken,
+		},
+	}
+	if !token.Expiry.IsZero() {
+		result.OAuth2.ExpiresAt = token.Expiry.Unix()
+	}
+	return result
+}
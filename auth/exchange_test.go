@@ -0,0 +1,336 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/auth"
+)
+
+// newTokenServer returns an OAuth2 token endpoint accepting the "code"
+// authorization code, the "refresh" refresh token and the client
+// credentials of "client".
+func newTokenServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() failed: %v", err)
+		}
+		var token string
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") == "code" {
+				token = "user-token"
+			}
+		case "refresh_token":
+			if r.Form.Get("refresh_token") == "refresh" {
+				token = "refreshed-token"
+			}
+		case "client_credentials":
+			token = "client-token"
+		}
+		if token == "" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  token,
+			"token_type":    "Bearer",
+			"refresh_token": "refresh",
+			"expires_in":    3600,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func authorizationCodeConfig(tokenURL string) *auth.Config {
+	return &auth.Config{
+		Scheme: &auth.Scheme{
+			Type: auth.SchemeOAuth2,
+			Flows: &auth.OAuthFlows{AuthorizationCode: &auth.OAuthFlow{
+				AuthorizationURL: "https://example.com/authorize",
+				TokenURL:         tokenURL,
+				Scopes:           map[string]string{"read": "read the data"},
+			}},
+		},
+		RawCredential: &auth.Credential{
+			Type: auth.CredentialOAuth2,
+			OAuth2: &auth.OAuth2Auth{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				RedirectURI:  "https://example.com/callback",
+			},
+		},
+	}
+}
+
+func TestNewRequest(t *testing.T) {
+	cfg := authorizationCodeConfig("https://example.com/token")
+	req, err := auth.NewRequest(cfg)
+	if err != nil {
+		t.Fatalf("NewRequest() failed: %v", err)
+	}
+	if req.CredentialKey != cfg.Key() {
+		t.Errorf("CredentialKey = %q, want %q", req.CredentialKey, cfg.Key())
+	}
+	exchanged := req.ExchangedCredential.OAuth2
+	u, err := url.Parse(exchanged.AuthURI)
+	if err != nil {
+		t.Fatalf("invalid AuthURI %q: %v", exchanged.AuthURI, err)
+	}
+	q := u.Query()
+	got := map[string]string{
+		"host":         u.Host,
+		"client_id":    q.Get("client_id"),
+		"redirect_uri": q.Get("redirect_uri"),
+		"scope":        q.Get("scope"),
+		"state":        q.Get("state"),
+	}
+	want := map[string]string{
+		"host":         "example.com",
+		"client_id":    "client",
+		"redirect_uri": "https://example.com/callback",
+		"scope":        "read",
+		"state":        exchanged.State,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("authorization URL mismatch (-want +got):\n%s", diff)
+	}
+	if exchanged.State == "" {
+		t.Error("State is empty")
+	}
+	if cfg.ExchangedCredential != nil {
+		t.Error("NewRequest() modified the config")
+	}
+
+	if _, err := auth.NewRequest(&auth.Config{Scheme: cfg.Scheme}); err == nil {
+		t.Error("NewRequest() without client ID succeeded, want error")
+	}
+}
+
+func TestExchange(t *testing.T) {
+	srv := newTokenServer(t)
+
+	testCases := []struct {
+		name     string
+		response func(req *auth.Config)
+		want     string
+		wantErr  string
+	}{
+		{
+			name: "authorization response",
+			response: func(req *auth.Config) {
+				o := req.ExchangedCredential.OAuth2
+				o.AuthResponseURI = "https://example.com/callback?code=code&state=" + o.State
+			},
+			want: "user-token",
+		},
+		{
+			name: "authorization code",
+			response: func(req *auth.Config) {
+				req.ExchangedCredential.OAuth2.AuthCode = "code"
+			},
+			want: "user-token",
+		},
+		{
+			name: "state mismatch",
+			response: func(req *auth.Config) {
+				req.ExchangedCredential.OAuth2.AuthResponseURI = "https://example.com/callback?code=code&state=other"
+			},
+			wantErr: "state",
+		},
+		{
+			name: "authorization denied",
+			response: func(req *auth.Config) {
+				req.ExchangedCredential.OAuth2.AuthResponseURI = "https://example.com/callback?error=access_denied"
+			},
+			wantErr: "access_denied",
+		},
+		{
+			name: "invalid code",
+			response: func(req *auth.Config) {
+				req.ExchangedCredential.OAuth2.AuthCode = "other"
+			},
+			wantErr: "failed to exchange",
+		},
+		{
+			name:     "no response",
+			response: func(req *auth.Config) {},
+			wantErr:  "no authorization response",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := auth.NewRequest(authorizationCodeConfig(srv.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			tc.response(req)
+			cred, err := auth.Exchange(t.Context(), req)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Exchange() error = %v, want error containing %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() failed: %v", err)
+			}
+			want := &auth.OAuth2Auth{
+				ClientID:     "client",
+				ClientSecret: "secret",
+				RedirectURI:  "https://example.com/callback",
+				AccessToken:  tc.want,
+				RefreshToken: "refresh",
+				ExpiresAt:    cred.OAuth2.ExpiresAt,
+			}
+			if diff := cmp.Diff(want, cred.OAuth2); diff != "" {
+				t.Errorf("Exchange() mismatch (-want +got):\n%s", diff)
+			}
+			if cred.OAuth2.ExpiresAt < time.Now().Unix() {
+				t.Errorf("ExpiresAt = %d, want in the future", cred.OAuth2.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	srv := newTokenServer(t)
+	apiKey := &auth.Credential{Type: auth.CredentialAPIKey, APIKey: "key"}
+
+	testCases := []struct {
+		name string
+		cfg  *auth.Config
+		want string
+	}{
+		{
+			name: "API key",
+			cfg: &auth.Config{
+				Scheme:        &auth.Scheme{Type: auth.SchemeAPIKey, In: auth.InHeader, Name: "X-Key"},
+				RawCredential: apiKey,
+			},
+			want: "key",
+		},
+		{
+			name: "client credentials",
+			cfg: &auth.Config{
+				Scheme: &auth.Scheme{
+					Type:  auth.SchemeOAuth2,
+					Flows: &auth.OAuthFlows{ClientCredentials: &auth.OAuthFlow{TokenURL: srv.URL}},
+				},
+				RawCredential: &auth.Credential{
+					Type:   auth.CredentialOAuth2,
+					OAuth2: &auth.OAuth2Auth{ClientID: "client", ClientSecret: "secret"},
+				},
+			},
+			want: "client-token",
+		},
+		{
+			name: "authorization code",
+			cfg:  authorizationCodeConfig(srv.URL),
+		},
+		{
+			name: "no credential",
+			cfg:  &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "bearer"}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cred, err := auth.Resolve(t.Context(), tc.cfg)
+			if err != nil {
+				t.Fatalf("Resolve() failed: %v", err)
+			}
+			var got string
+			switch {
+			case cred == nil:
+			case cred.OAuth2 != nil:
+				got = cred.OAuth2.AccessToken
+			default:
+				got = cred.APIKey
+			}
+			if got != tc.want {
+				t.Errorf("Resolve() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	srv := newTokenServer(t)
+	scheme := authorizationCodeConfig(srv.URL).Scheme
+	token := func(accessToken, refreshToken string, expiresAt time.Time) *auth.Credential {
+		return &auth.Credential{
+			Type: auth.CredentialOAuth2,
+			OAuth2: &auth.OAuth2Auth{
+				ClientID:     "client",
+				AccessToken:  accessToken,
+				RefreshToken: refreshToken,
+				ExpiresAt:    expiresAt.Unix(),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name string
+		cred *auth.Credential
+		want string
+	}{
+		{
+			name: "valid",
+			cred: token("token", "refresh", time.Now().Add(time.Hour)),
+			want: "token",
+		},
+		{
+			name: "expired",
+			cred: token("token", "refresh", time.Now().Add(-time.Hour)),
+			want: "refreshed-token",
+		},
+		{
+			name: "expires soon",
+			cred: token("token", "refresh", time.Now().Add(10*time.Second)),
+			want: "refreshed-token",
+		},
+		{
+			name: "expired without refresh token",
+			cred: token("token", "", time.Now().Add(-time.Hour)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cred, err := auth.Refresh(t.Context(), scheme, tc.cred)
+			if err != nil {
+				t.Fatalf("Refresh() failed: %v", err)
+			}
+			var got string
+			if cred != nil {
+				got = cred.OAuth2.AccessToken
+			}
+			if got != tc.want {
+				t.Errorf("Refresh() access token = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
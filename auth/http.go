@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Apply sets the credential on the HTTP request, according to the scheme.
+func Apply(req *http.Request, scheme *Scheme, cred *Credential) error {
+	if scheme == nil || cred == nil {
+		return fmt.Errorf("auth scheme and credential are required")
+	}
+	switch {
+	case scheme.Type == SchemeAPIKey:
+		if cred.APIKey == "" {
+			return fmt.Errorf("API key is required")
+		}
+		switch scheme.In {
+		case InHeader:
+			req.Header.Set(scheme.Name, cred.APIKey)
+		case InQuery:
+			q := req.URL.Query()
+			q.Set(scheme.Name, cred.APIKey)
+			req.URL.RawQuery = q.Encode()
+		case InCookie:
+			req.AddCookie(&http.Cookie{Name: scheme.Name, Value: cred.APIKey})
+		default:
+			return fmt.Errorf("unsupported API key location %q", scheme.In)
+		}
+	case cred.OAuth2 != nil && cred.OAuth2.AccessToken != "":
+		req.Header.Set("Authorization", "Bearer "+cred.OAuth2.AccessToken)
+	case cred.HTTP != nil && strings.EqualFold(cred.HTTP.Scheme, "basic"):
+		c := cred.HTTP.Credentials
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password)))
+	case cred.HTTP != nil && cred.HTTP.Credentials.Token != "":
+		req.Header.Set("Authorization", "Bearer "+cred.HTTP.Credentials.Token)
+	default:
+		return fmt.Errorf("credential of type %q can't be applied to scheme %q", cred.Type, scheme.Type)
+	}
+	return nil
+}
+
+type contextKey struct{}
+
+type contextCredential struct {
+	scheme *Scheme
+	cred   *Credential
+}
+
+// WithCredential returns a copy of ctx carrying the credential applied by
+// Transport to the requests made with it.
+func WithCredential(ctx context.Context, scheme *Scheme, cred *Credential) context.Context {
+	return context.WithValue(ctx, contextKey{}, contextCredential{scheme: scheme, cred: cred})
+}
+
+// Transport is an http.RoundTripper applying the credential carried by the
+// context of the requests, set by WithCredential. Requests without
+// credential are sent unchanged.
+type Transport struct {
+	// Base is the underlying transport. If nil, http.DefaultTransport is
+	// used.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c, ok := req.Context().Value(contextKey{}).(contextCredential)
+	if !ok {
+		return base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	if err := Apply(req, c.scheme, c.cred); err != nil {
+		return nil, err
+	}
+	return base.RoundTrip(req)
+}
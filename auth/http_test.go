@@ -0,0 +1,147 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"google.golang.org/adk/auth"
+)
+
+func TestApply(t *testing.T) {
+	apiKey := &auth.Credential{Type: auth.CredentialAPIKey, APIKey: "key"}
+
+	testCases := []struct {
+		name       string
+		scheme     *auth.Scheme
+		cred       *auth.Credential
+		wantURL    string
+		wantHeader http.Header
+		wantErr    bool
+	}{
+		{
+			name:       "API key in header",
+			scheme:     &auth.Scheme{Type: auth.SchemeAPIKey, In: auth.InHeader, Name: "X-Key"},
+			cred:       apiKey,
+			wantURL:    "https://example.com/data?a=b",
+			wantHeader: http.Header{"X-Key": {"key"}},
+		},
+		{
+			name:       "API key in query",
+			scheme:     &auth.Scheme{Type: auth.SchemeAPIKey, In: auth.InQuery, Name: "key"},
+			cred:       apiKey,
+			wantURL:    "https://example.com/data?a=b&key=key",
+			wantHeader: http.Header{},
+		},
+		{
+			name:       "API key in cookie",
+			scheme:     &auth.Scheme{Type: auth.SchemeAPIKey, In: auth.InCookie, Name: "session"},
+			cred:       apiKey,
+			wantURL:    "https://example.com/data?a=b",
+			wantHeader: http.Header{"Cookie": {"session=key"}},
+		},
+		{
+			name:   "bearer",
+			scheme: &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "bearer"},
+			cred: &auth.Credential{
+				Type: auth.CredentialHTTP,
+				HTTP: &auth.HTTPAuth{Scheme: "bearer", Credentials: auth.HTTPCredentials{Token: "token"}},
+			},
+			wantURL:    "https://example.com/data?a=b",
+			wantHeader: http.Header{"Authorization": {"Bearer token"}},
+		},
+		{
+			name:   "basic",
+			scheme: &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "basic"},
+			cred: &auth.Credential{
+				Type: auth.CredentialHTTP,
+				HTTP: &auth.HTTPAuth{Scheme: "basic", Credentials: auth.HTTPCredentials{Username: "user", Password: "pass"}},
+			},
+			wantURL:    "https://example.com/data?a=b",
+			wantHeader: http.Header{"Authorization": {"Basic dXNlcjpwYXNz"}},
+		},
+		{
+			name:   "OAuth2",
+			scheme: &auth.Scheme{Type: auth.SchemeOAuth2},
+			cred: &auth.Credential{
+				Type:   auth.CredentialOAuth2,
+				OAuth2: &auth.OAuth2Auth{AccessToken: "token"},
+			},
+			wantURL:    "https://example.com/data?a=b",
+			wantHeader: http.Header{"Authorization": {"Bearer token"}},
+		},
+		{
+			name:    "OAuth2 without token",
+			scheme:  &auth.Scheme{Type: auth.SchemeOAuth2},
+			cred:    &auth.Credential{Type: auth.CredentialOAuth2, OAuth2: &auth.OAuth2Auth{ClientID: "client"}},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "https://example.com/data?a=b", nil)
+			err := auth.Apply(req, tc.scheme, tc.cred)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("Apply() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply() failed: %v", err)
+			}
+			if got := req.URL.String(); got != tc.wantURL {
+				t.Errorf("URL = %q, want %q", got, tc.wantURL)
+			}
+			if diff := cmp.Diff(tc.wantHeader, req.Header); diff != "" {
+				t.Errorf("header mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+	client := &http.Client{Transport: &auth.Transport{}}
+
+	scheme := &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "bearer"}
+	cred := &auth.Credential{
+		Type: auth.CredentialHTTP,
+		HTTP: &auth.HTTPAuth{Scheme: "bearer", Credentials: auth.HTTPCredentials{Token: "token"}},
+	}
+	for _, ctx := range []context.Context{t.Context(), auth.WithCredential(t.Context(), scheme, cred)} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if diff := cmp.Diff([]string{"", "Bearer token"}, got); diff != "" {
+		t.Errorf("Authorization headers mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+// SchemeType is the type of an auth scheme.
+type SchemeType string
+
+const (
+	// SchemeAPIKey is an API key sent in a header, a query parameter or a
+	// cookie.
+	SchemeAPIKey SchemeType = "apiKey"
+	// SchemeHTTP is an HTTP authentication scheme, e.g. bearer or basic.
+	SchemeHTTP SchemeType = "http"
+	// SchemeOAuth2 is OAuth2.
+	SchemeOAuth2 SchemeType = "oauth2"
+)
+
+// Locations of API keys.
+const (
+	InHeader = "header"
+	InQuery  = "query"
+	InCookie = "cookie"
+)
+
+// Scheme is an auth scheme, as defined by OpenAPI security schemes.
+type Scheme struct {
+	// Type of the scheme.
+	Type SchemeType `json:"type"`
+	// Description of the scheme.
+	Description string `json:"description,omitempty"`
+
+	// Name of the header, query parameter or cookie of an API key.
+	Name string `json:"name,omitempty"`
+	// In is the location of an API key: InHeader, InQuery or InCookie.
+	In string `json:"in,omitempty"`
+
+	// Scheme is the name of an HTTP authentication scheme, e.g. "bearer" or
+	// "basic".
+	Scheme string `json:"scheme,omitempty"`
+	// BearerFormat is a hint of the format of bearer tokens, e.g. "JWT".
+	BearerFormat string `json:"bearerFormat,omitempty"`
+
+	// Flows are the supported OAuth2 flows.
+	Flows *OAuthFlows `json:"flows,omitempty"`
+}
+
+// OAuthFlows are the OAuth2 flows supported by a scheme.
+type OAuthFlows struct {
+	// AuthorizationCode is the authorization code flow, in which the user
+	// authorizes the client.
+	AuthorizationCode *OAuthFlow `json:"authorizationCode,omitempty"`
+	// ClientCredentials is the client credentials flow, in which the client
+	// authenticates as itself.
+	ClientCredentials *OAuthFlow `json:"clientCredentials,omitempty"`
+}
+
+// OAuthFlow is the configuration of an OAuth2 flow.
+type OAuthFlow struct {
+	// AuthorizationURL is the URL the user is redirected to, to authorize
+	// the client. Only used by the authorization code flow.
+	AuthorizationURL string `json:"authorizationUrl,omitempty"`
+	// TokenURL is the URL of the token endpoint.
+	TokenURL string `json:"tokenUrl,omitempty"`
+	// RefreshURL is the URL to refresh tokens. If empty, TokenURL is used.
+	RefreshURL string `json:"refreshUrl,omitempty"`
+	// Scopes maps the available scopes to their description. All of them
+	// are requested.
+	Scopes map[string]string `json:"scopes,omitempty"`
+}
@@ -0,0 +1,86 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CredentialService stores the credentials of the users, e.g. the OAuth2
+// tokens obtained once they authorized an agent.
+type CredentialService interface {
+	// Load returns the credential stored under the key, or nil if there is
+	// none.
+	Load(ctx context.Context, req *LoadRequest) (*Credential, error)
+	// Save stores the credential under the key, replacing the previous one.
+	Save(ctx context.Context, req *SaveRequest) error
+}
+
+// LoadRequest represents a request to load a credential.
+type LoadRequest struct {
+	AppName, UserID, Key string
+}
+
+// SaveRequest represents a request to save a credential.
+type SaveRequest struct {
+	AppName, UserID, Key string
+	Credential           *Credential
+}
+
+// InMemoryCredentialService returns a CredentialService keeping the
+// credentials in memory. The credentials are lost when the process exits.
+func InMemoryCredentialService() CredentialService {
+	return &inMemoryService{credentials: make(map[credentialKey]*Credential)}
+}
+
+type credentialKey struct {
+	appName, userID, key string
+}
+
+type inMemoryService struct {
+	mu          sync.RWMutex
+	credentials map[credentialKey]*Credential
+}
+
+func (s *inMemoryService) Load(ctx context.Context, req *LoadRequest) (*Credential, error) {
+	if err := validate(req.AppName, req.UserID, req.Key); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.credentials[credentialKey{req.AppName, req.UserID, req.Key}], nil
+}
+
+func (s *inMemoryService) Save(ctx context.Context, req *SaveRequest) error {
+	if err := validate(req.AppName, req.UserID, req.Key); err != nil {
+		return err
+	}
+	if req.Credential == nil {
+		return fmt.Errorf("credential is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credentials[credentialKey{req.AppName, req.UserID, req.Key}] = req.Credential
+	return nil
+}
+
+func validate(appName, userID, key string) error {
+	if appName == "" || userID == "" || key == "" {
+		return fmt.Errorf("app name, user ID and key are required, got app name %q, user ID %q and key %q", appName, userID, key)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/googlellm"
+	"google.golang.org/adk/model"
+)
+
+// BuiltIn is an executor using the code execution tool of Gemini 2 models.
+// The code is written and run by the model, so Execute is never called by the
+// agent.
+type BuiltIn struct{}
+
+// ErrBuiltInExecution is returned by BuiltIn.Execute.
+var ErrBuiltInExecution = errors.New("code is executed by the model")
+
+// Execute implements Executor. It always fails with ErrBuiltInExecution.
+func (BuiltIn) Execute(ctx context.Context, input *Input) (*Result, error) {
+	return nil, ErrBuiltInExecution
+}
+
+// ProcessRequest adds the code execution tool to the LLM request.
+func (BuiltIn) ProcessRequest(req *model.LLMRequest) error {
+	if !googlellm.IsGemini2OrAbove(req.Model) {
+		return fmt.Errorf("gemini code execution tool is not supported for model %q", req.Model)
+	}
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	req.Config.Tools = append(req.Config.Tools, &genai.Tool{CodeExecution: &genai.ToolCodeExecution{}})
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codeexecutor defines the interface for executors running code
+// written by an LLM agent, and provides the built-in implementations:
+//   - [BuiltIn] uses the code execution tool of Gemini models, the code
+//     is run by the model itself.
+//   - [NewLocal] runs the code in a subprocess of the current process.
+//   - [NewContainer] runs the code in a Docker container.
+//
+// Executors are set in the CodeExecutor field of llmagent.Config. Unless the
+// model runs the code itself, the agent extracts the first fenced code block
+// of each model response, e.g.
+//
+//	```python
+//	print(1 + 1)
+//	```
+//
+// executes it, and sends the result back to the model, until the model
+// responds without code.
+package codeexecutor
+
+import (
+	"context"
+)
+
+// Executor runs code.
+type Executor interface {
+	// Execute runs the code of the input. Failures of the code itself, e.g. a
+	// syntax error or a non-zero exit status, are reported in Result.Stderr;
+	// an error is returned if the code could not be run at all.
+	Execute(ctx context.Context, input *Input) (*Result, error)
+}
+
+// Input is the input of a code execution.
+type Input struct {
+	// Code to run.
+	Code string
+	// InputFiles are made available to the code, in its working directory.
+	InputFiles []*File
+	// ExecutionID identifies the session the code is run for. Stateful
+	// executors can use it to share state between executions.
+	ExecutionID string
+}
+
+// Result is the result of a code execution.
+type Result struct {
+	// Stdout is the standard output of the code.
+	Stdout string
+	// Stderr is the standard error of the code. The execution failed if it
+	// isn't empty.
+	Stderr string
+	// OutputFiles are the files created by the code.
+	OutputFiles []*File
+}
+
+// File is a file used or created by the code.
+type File struct {
+	// Name of the file, relative to the working directory of the code.
+	Name string
+	// Content of the file.
+	Content []byte
+	// MIMEType of the content, e.g. "text/csv".
+	MIMEType string
+}
@@ -0,0 +1,180 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/model"
+)
+
+func TestBuiltIn_ProcessRequest(t *testing.T) {
+	testCases := []struct {
+		name    string
+		model   string
+		want    []*genai.Tool
+		wantErr bool
+	}{
+		{
+			name:  "gemini 2",
+			model: "gemini-2.5-flash",
+			want:  []*genai.Tool{{CodeExecution: &genai.ToolCodeExecution{}}},
+		},
+		{
+			name:    "gemini 1.5",
+			model:   "gemini-1.5-pro",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &model.LLMRequest{Model: tc.model}
+			err := codeexecutor.BuiltIn{}.ProcessRequest(req)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ProcessRequest() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.want, req.Config.Tools); diff != "" {
+				t.Errorf("request tools mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLocal_Execute(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	input := []*codeexecutor.File{{Name: "data.csv", Content: []byte("a,b\n1,2\n"), MIMEType: "text/csv"}}
+
+	testCases := []struct {
+		name  string
+		cfg   codeexecutor.LocalConfig
+		input *codeexecutor.Input
+		want  *codeexecutor.Result
+	}{
+		{
+			name:  "stdout",
+			cfg:   codeexecutor.LocalConfig{Command: []string{"sh"}},
+			input: &codeexecutor.Input{Code: "echo hello"},
+			want:  &codeexecutor.Result{Stdout: "hello\n"},
+		},
+		{
+			name:  "stderr",
+			cfg:   codeexecutor.LocalConfig{Command: []string{"sh"}},
+			input: &codeexecutor.Input{Code: "echo oops >&2; exit 1"},
+			want:  &codeexecutor.Result{Stderr: "oops\n"},
+		},
+		{
+			name:  "exit status",
+			cfg:   codeexecutor.LocalConfig{Command: []string{"sh"}},
+			input: &codeexecutor.Input{Code: "exit 3"},
+			want:  &codeexecutor.Result{Stderr: "exit status 3"},
+		},
+		{
+			name:  "files",
+			cfg:   codeexecutor.LocalConfig{Command: []string{"sh"}},
+			input: &codeexecutor.Input{Code: "wc -l < data.csv; cp data.csv out.csv", InputFiles: input},
+			want: &codeexecutor.Result{
+				Stdout:      "2\n",
+				OutputFiles: []*codeexecutor.File{{Name: "out.csv", Content: []byte("a,b\n1,2\n"), MIMEType: "text/csv; charset=utf-8"}},
+			},
+		},
+		{
+			name:  "timeout",
+			cfg:   codeexecutor.LocalConfig{Command: []string{"sh"}, Timeout: 100 * time.Millisecond},
+			input: &codeexecutor.Input{Code: "sleep 10"},
+			want:  &codeexecutor.Result{Stderr: "code execution timed out after 100ms"},
+		},
+		{
+			name:  "memory limit",
+			cfg:   codeexecutor.LocalConfig{Command: []string{"sh"}, MemoryLimit: 512 << 20},
+			input: &codeexecutor.Input{Code: "ulimit -v"},
+			want:  &codeexecutor.Result{Stdout: "524288\n"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := codeexecutor.NewLocal(tc.cfg)
+			if err != nil {
+				t.Fatalf("NewLocal() error = %v", err)
+			}
+			got, err := e.Execute(t.Context(), tc.input)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Execute() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLocal_Execute_InvalidFileName(t *testing.T) {
+	e, err := codeexecutor.NewLocal(codeexecutor.LocalConfig{Command: []string{"sh"}})
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	_, err = e.Execute(t.Context(), &codeexecutor.Input{
+		Code:       "true",
+		InputFiles: []*codeexecutor.File{{Name: "../escape.csv"}},
+	})
+	if err == nil {
+		t.Errorf("Execute() error = nil, want error")
+	}
+}
+
+func TestContainer_Execute(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	// The fake Docker CLI prints its arguments.
+	docker := filepath.Join(t.TempDir(), "docker")
+	if err := os.WriteFile(docker, []byte("#!/bin/sh\necho \"$@\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	e, err := codeexecutor.NewContainer(codeexecutor.ContainerConfig{
+		DockerPath:  docker,
+		MemoryLimit: 1 << 20,
+	})
+	if err != nil {
+		t.Fatalf("NewContainer() error = %v", err)
+	}
+	got, err := e.Execute(t.Context(), &codeexecutor.Input{Code: "print(1)"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	args := strings.Fields(got.Stdout)
+	if len(args) < 4 || !slices.Equal(args[:3], []string{"run", "--rm", "-i"}) {
+		t.Fatalf("docker args = %v, want run --rm -i ...", args)
+	}
+	for _, want := range []string{"--network none", "-w /workspace", "--memory 1048576b", codeexecutor.DefaultImage + " python3 -"} {
+		if !strings.Contains(got.Stdout, want) {
+			t.Errorf("docker args = %q, want %q", got.Stdout, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultImage is the image of the containers if the config doesn't set one.
+const DefaultImage = "python:3.12-slim"
+
+// ContainerConfig is the configuration of the container executor.
+type ContainerConfig struct {
+	// Image of the container. If empty, DefaultImage is used.
+	Image string
+	// Command runs the code read from its standard input in the container.
+	// If empty, "python3 -" is used.
+	Command []string
+	// Timeout is the maximum duration of an execution. The container is
+	// killed once it expires. If 0, DefaultTimeout is used.
+	Timeout time.Duration
+	// MemoryLimit is the maximum memory of the container, in bytes.
+	// If 0, the memory is not limited.
+	MemoryLimit int64
+	// Network is the network the container is connected to, e.g. "bridge".
+	// If empty, the container has no network access.
+	Network string
+	// DockerPath is the path of the Docker CLI. If empty, "docker" is looked
+	// up in PATH.
+	DockerPath string
+}
+
+// NewContainer returns an executor running the code in a new Docker
+// container for every execution. The input files are mounted in the working
+// directory of the container, /workspace.
+func NewContainer(cfg ContainerConfig) (Executor, error) {
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative")
+	}
+	if cfg.MemoryLimit < 0 {
+		return nil, fmt.Errorf("memory limit must not be negative")
+	}
+	e := &containerExecutor{
+		image:       cfg.Image,
+		command:     cfg.Command,
+		timeout:     cfg.Timeout,
+		memoryLimit: cfg.MemoryLimit,
+		network:     cfg.Network,
+		docker:      cfg.DockerPath,
+	}
+	if e.image == "" {
+		e.image = DefaultImage
+	}
+	if len(e.command) == 0 {
+		e.command = []string{"python3", "-"}
+	}
+	if e.timeout == 0 {
+		e.timeout = DefaultTimeout
+	}
+	if e.network == "" {
+		e.network = "none"
+	}
+	if e.docker == "" {
+		e.docker = "docker"
+	}
+	return e, nil
+}
+
+type containerExecutor struct {
+	image       string
+	command     []string
+	timeout     time.Duration
+	memoryLimit int64
+	network     string
+	docker      string
+}
+
+// Execute implements Executor.
+func (e *containerExecutor) Execute(ctx context.Context, input *Input) (*Result, error) {
+	name := "adk-code-" + uuid.NewString()
+	return runCommand(ctx, input, e.timeout, func(ctx context.Context, dir string) *exec.Cmd {
+		args := []string{"run", "--rm", "-i", "--name", name, "--network", e.network,
+			"-v", dir + ":/workspace", "-w", "/workspace"}
+		if e.memoryLimit > 0 {
+			args = append(args, "--memory", fmt.Sprintf("%db", e.memoryLimit))
+		}
+		args = append(args, e.image)
+		args = append(args, e.command...)
+		cmd := exec.CommandContext(ctx, e.docker, args...)
+		// Killing the CLI doesn't stop the container.
+		cmd.Cancel = func() error {
+			_ = exec.Command(e.docker, "kill", name).Run()
+			return cmd.Process.Kill()
+		}
+		return cmd
+	})
+}
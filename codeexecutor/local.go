@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// LocalConfig is the configuration of the local executor.
+type LocalConfig struct {
+	// Command runs the code read from its standard input. It is run in a
+	// temporary working directory containing the input files. If empty,
+	// "python3 -" is used.
+	Command []string
+	// Timeout is the maximum duration of an execution. The process is killed
+	// once it expires. If 0, DefaultTimeout is used.
+	Timeout time.Duration
+	// MemoryLimit is the maximum virtual memory of the process, in bytes,
+	// enforced with "ulimit -v". If 0, the memory is not limited.
+	// It isn't supported on Windows.
+	MemoryLimit int64
+}
+
+// NewLocal returns an executor running the code in a subprocess of the
+// current process.
+//
+// The code has the same permissions as the current process, e.g. it can read
+// and write any file the process can access. Use it only for trusted models
+// and inputs, or use NewContainer for a sandboxed execution.
+func NewLocal(cfg LocalConfig) (Executor, error) {
+	if cfg.Timeout < 0 {
+		return nil, fmt.Errorf("timeout must not be negative")
+	}
+	if cfg.MemoryLimit < 0 {
+		return nil, fmt.Errorf("memory limit must not be negative")
+	}
+	if cfg.MemoryLimit > 0 && runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("memory limit is not supported on windows")
+	}
+	command := cfg.Command
+	if len(command) == 0 {
+		command = []string{"python3", "-"}
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &localExecutor{command: command, timeout: timeout, memoryLimit: cfg.MemoryLimit}, nil
+}
+
+type localExecutor struct {
+	command     []string
+	timeout     time.Duration
+	memoryLimit int64
+}
+
+// Execute implements Executor.
+func (e *localExecutor) Execute(ctx context.Context, input *Input) (*Result, error) {
+	return runCommand(ctx, input, e.timeout, func(ctx context.Context, dir string) *exec.Cmd {
+		args := e.command
+		if e.memoryLimit > 0 {
+			kb := (e.memoryLimit + 1023) / 1024
+			args = append([]string{"sh", "-c", fmt.Sprintf(`ulimit -v %d && exec "$@"`, kb), "sh"}, args...)
+		}
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = dir
+		return cmd
+	})
+}
@@ -0,0 +1,143 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codeexecutor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the maximum duration of an execution if the executor
+// config doesn't set one.
+const DefaultTimeout = 30 * time.Second
+
+// workspace is the working directory of an execution.
+type workspace struct {
+	dir    string
+	inputs map[string]bool
+}
+
+// newWorkspace creates a temporary directory containing the files.
+func newWorkspace(files []*File) (*workspace, error) {
+	dir, err := os.MkdirTemp("", "adk-code-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	w := &workspace{dir: dir, inputs: make(map[string]bool)}
+	for _, f := range files {
+		if !filepath.IsLocal(f.Name) {
+			w.close()
+			return nil, fmt.Errorf("invalid input file name %q", f.Name)
+		}
+		path := filepath.Join(dir, f.Name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			w.close()
+			return nil, fmt.Errorf("failed to write input file %q: %w", f.Name, err)
+		}
+		if err := os.WriteFile(path, f.Content, 0o644); err != nil {
+			w.close()
+			return nil, fmt.Errorf("failed to write input file %q: %w", f.Name, err)
+		}
+		w.inputs[filepath.ToSlash(f.Name)] = true
+	}
+	return w, nil
+}
+
+// outputFiles returns the files of the workspace which are not input files.
+func (w *workspace) outputFiles() ([]*File, error) {
+	var files []*File
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(w.dir, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if w.inputs[name] || !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		mimeType := mime.TypeByExtension(filepath.Ext(name))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		files = append(files, &File{Name: name, Content: content, MIMEType: mimeType})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output files: %w", err)
+	}
+	return files, nil
+}
+
+func (w *workspace) close() {
+	_ = os.RemoveAll(w.dir)
+}
+
+// runCommand runs the command built by newCmd with the code as its standard
+// input, in a new workspace containing the input files.
+func runCommand(ctx context.Context, input *Input, timeout time.Duration, newCmd func(ctx context.Context, dir string) *exec.Cmd) (*Result, error) {
+	w, err := newWorkspace(input.InputFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer w.close()
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := newCmd(runCtx, w.dir)
+	cmd.Stdin = strings.NewReader(input.Code)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait for the children of a killed process to close the output.
+	cmd.WaitDelay = time.Second
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	result := &Result{Stdout: stdout.String(), Stderr: stderr.String()}
+	var exitErr *exec.ExitError
+	switch {
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		result.Stderr += fmt.Sprintf("code execution timed out after %v", timeout)
+		return result, nil
+	case errors.As(err, &exitErr):
+		if result.Stderr == "" {
+			result.Stderr = exitErr.Error()
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to run code: %w", err)
+	}
+	result.OutputFiles, err = w.outputFiles()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
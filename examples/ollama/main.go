@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package provides an ADK agent running fully offline against a local model
+// server, e.g. `ollama serve` with `ollama pull qwen3`.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/cmd/launcher"
+	"google.golang.org/adk/cmd/launcher/full"
+	"google.golang.org/adk/model/ollama"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func main() {
+	ctx := context.Background()
+
+	modelName := os.Getenv("OLLAMA_MODEL")
+	if modelName == "" {
+		modelName = "qwen3"
+	}
+	model, err := ollama.NewModel(ctx, modelName, &ollama.ClientConfig{
+		BaseURL: os.Getenv("OLLAMA_HOST"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create model: %v", err)
+	}
+
+	type Input struct {
+		City string `json:"city"`
+	}
+	type Output struct {
+		Weather string `json:"weather"`
+	}
+	handler := func(ctx tool.Context, input Input) (Output, error) {
+		return Output{Weather: "It is sunny in " + input.City + "."}, nil
+	}
+	weatherTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "Returns the current weather in a city.",
+	}, handler)
+	if err != nil {
+		log.Fatalf("Failed to create tool: %v", err)
+	}
+
+	a, err := llmagent.New(llmagent.Config{
+		Name:        "weather_agent",
+		Model:       model,
+		Description: "Agent to answer questions about the weather in a city.",
+		Instruction: "Answer questions about the current weather in a city using the get_weather tool.",
+		Tools: []tool.Tool{
+			weatherTool,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create agent: %v", err)
+	}
+
+	config := &launcher.Config{
+		AgentLoader: agent.NewSingleLoader(a),
+	}
+
+	l := full.NewLauncher()
+	if err = l.Execute(ctx, config, os.Args[1:]); err != nil {
+		log.Fatalf("Run failed: %v\n\n%s", err, l.CommandLineSyntax())
+	}
+}
@@ -22,6 +22,7 @@ require (
 	github.com/modelcontextprotocol/go-sdk v0.7.0
 	google.golang.org/grpc v1.76.0
 	google.golang.org/protobuf v1.36.10
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/gorm v1.31.0
 )
 
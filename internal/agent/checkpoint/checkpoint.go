@@ -0,0 +1,72 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checkpoint provides the helpers the workflow agents use to
+// checkpoint their progress in the session state, so that a run which stopped,
+// e.g. because a sub-agent is waiting for a long-running tool, can be resumed.
+//
+// The agents validate the checkpoints they restore, since the agent tree may
+// have changed since the checkpoint was written.
+package checkpoint
+
+import (
+	"iter"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/session"
+)
+
+// Event returns an event setting the checkpoint under key to value, or
+// clearing it if value is nil.
+//
+// State deltas can't delete keys, so a cleared checkpoint stays in the state
+// with the nil value, which means there is no checkpoint.
+func Event(ctx agent.InvocationContext, key string, value any) *session.Event {
+	event := session.NewEvent(ctx.InvocationID())
+	event.Author = ctx.Agent().Name()
+	event.Branch = ctx.Branch()
+	event.Actions.StateDelta[key] = value
+	return event
+}
+
+// Paused reports whether the event is waiting for the result of a
+// long-running tool.
+func Paused(event *session.Event) bool {
+	return event != nil && len(event.LongRunningToolIDs) > 0
+}
+
+// AnyPaused reports whether one of the events is waiting for the result of a
+// long-running tool.
+func AnyPaused(events iter.Seq[*session.Event]) bool {
+	for event := range events {
+		if Paused(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Int converts a number of a checkpoint. Values stored as JSON are decoded as
+// float64.
+func Int(v any) (int, bool) {
+	switch v := v.(type) {
+	case int:
+		return v, true
+	case uint:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
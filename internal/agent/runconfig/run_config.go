@@ -14,7 +14,13 @@
 
 package runconfig
 
-import "context"
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/adk/agent"
+)
 
 type StreamingMode string
 
@@ -25,7 +31,79 @@ const (
 )
 
 type RunConfig struct {
-	StreamingMode StreamingMode
+	StreamingMode          StreamingMode
+	MaxConcurrentToolCalls int
+	// Limits are shared by all the agents of the invocation.
+	Limits *Limits
+}
+
+// Limits enforces the limits of agent.RunConfig for an invocation.
+// It is safe for concurrent use. A nil *Limits enforces no limits.
+type Limits struct {
+	cfg       agent.RunConfig
+	deadline  time.Time
+	llmCalls  atomic.Int64
+	toolCalls atomic.Int64
+	// exceeded is the first exceeded limit.
+	exceeded atomic.Pointer[agent.LimitExceededError]
+}
+
+// NewLimits returns the limits of cfg, starting the MaxDuration timer now.
+func NewLimits(cfg agent.RunConfig) *Limits {
+	l := &Limits{cfg: cfg}
+	if cfg.MaxDuration > 0 {
+		l.deadline = time.Now().Add(cfg.MaxDuration)
+	}
+	return l
+}
+
+// Check returns an *agent.LimitExceededError if a limit was exceeded, by
+// any agent of the invocation, or if MaxDuration elapsed.
+func (l *Limits) Check() error {
+	if l == nil {
+		return nil
+	}
+	if err := l.exceeded.Load(); err != nil {
+		return err
+	}
+	if l.deadline.IsZero() || time.Now().Before(l.deadline) {
+		return nil
+	}
+	return l.exceed(agent.LimitDuration, l.cfg.MaxDuration)
+}
+
+func (l *Limits) exceed(limit agent.Limit, value any) error {
+	l.exceeded.CompareAndSwap(nil, &agent.LimitExceededError{Limit: limit, Value: value})
+	return l.exceeded.Load()
+}
+
+// AddLLMCall counts a model call. It returns an *agent.LimitExceededError
+// if the call would exceed MaxLLMCalls, or if Check does.
+func (l *Limits) AddLLMCall() error {
+	if l == nil {
+		return nil
+	}
+	if err := l.Check(); err != nil {
+		return err
+	}
+	if n := l.llmCalls.Add(1); l.cfg.MaxLLMCalls > 0 && n > int64(l.cfg.MaxLLMCalls) {
+		return l.exceed(agent.LimitLLMCalls, l.cfg.MaxLLMCalls)
+	}
+	return nil
+}
+
+// AddToolCalls counts n function calls, like AddLLMCall for MaxToolCalls.
+func (l *Limits) AddToolCalls(n int) error {
+	if l == nil {
+		return nil
+	}
+	if err := l.Check(); err != nil {
+		return err
+	}
+	if total := l.toolCalls.Add(int64(n)); l.cfg.MaxToolCalls > 0 && total > int64(l.cfg.MaxToolCalls) {
+		return l.exceed(agent.LimitToolCalls, l.cfg.MaxToolCalls)
+	}
+	return nil
 }
 
 func ToContext(ctx context.Context, cfg *RunConfig) context.Context {
@@ -40,6 +118,14 @@ func FromContext(ctx context.Context) *RunConfig {
 	return m
 }
 
+// LimitsFromContext returns the limits of the invocation, or nil.
+func LimitsFromContext(ctx context.Context) *Limits {
+	if cfg := FromContext(ctx); cfg != nil {
+		return cfg.Limits
+	}
+	return nil
+}
+
 type ctxKey int
 
 const runConfigCtxKey ctxKey = 0
@@ -25,9 +25,10 @@ import (
 )
 
 type InvocationContextParams struct {
-	Artifacts agent.Artifacts
-	Memory    agent.Memory
-	Session   session.Session
+	Artifacts   agent.Artifacts
+	Memory      agent.Memory
+	Credentials agent.Credentials
+	Session     session.Session
 
 	Branch string
 	Agent  agent.Agent
@@ -72,6 +73,10 @@ func (c *InvocationContext) Memory() agent.Memory {
 	return c.params.Memory
 }
 
+func (c *InvocationContext) Credentials() agent.Credentials {
+	return c.params.Credentials
+}
+
 func (c *InvocationContext) Session() session.Session {
 	return c.params.Session
 }
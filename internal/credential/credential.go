@@ -0,0 +1,52 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package credential
+
+import (
+	"context"
+
+	"google.golang.org/adk/auth"
+)
+
+// RequestKey returns the key under which the credential of the tool is kept
+// while the credential stored under key is requested from the client. The
+// request sent to the client has no client secret, which is restored from
+// this credential when the client responds.
+func RequestKey(key string) string {
+	return key + "_request"
+}
+
+type Credentials struct {
+	Service auth.CredentialService
+	UserID  string
+	AppName string
+}
+
+func (c *Credentials) Load(ctx context.Context, key string) (*auth.Credential, error) {
+	return c.Service.Load(ctx, &auth.LoadRequest{
+		AppName: c.AppName,
+		UserID:  c.UserID,
+		Key:     key,
+	})
+}
+
+func (c *Credentials) Save(ctx context.Context, key string, cred *auth.Credential) error {
+	return c.Service.Save(ctx, &auth.SaveRequest{
+		AppName:    c.AppName,
+		UserID:     c.UserID,
+		Key:        key,
+		Credential: cred,
+	})
+}
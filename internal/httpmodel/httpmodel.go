@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package httpmodel implements the HTTP calls of the model backends talking
+// to JSON APIs.
+package httpmodel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Request is a POST request to a model API.
+type Request struct {
+	// Provider names the API in errors, e.g. "openai".
+	Provider string
+	// URL is the URL of the endpoint.
+	URL string
+	// Body is sent encoded as JSON.
+	Body any
+	// DefaultHeader holds the headers which the headers of Config override,
+	// e.g. the headers configured for the model.
+	DefaultHeader http.Header
+	// Config is the config of the model request. Its HTTP headers are sent.
+	Config *genai.GenerateContentConfig
+	// Header holds the headers overriding the others, e.g. authentication.
+	Header http.Header
+	// ParseError parses the body of an error response into err and reports
+	// whether the body has the error format of the API. If it doesn't, or if
+	// ParseError is nil, the body is the message of the error.
+	ParseError func(data []byte, err *APIError) bool
+}
+
+// Post sends the request and returns the body of a successful response.
+// Responses with a non-2xx status are returned as *APIError.
+func Post(ctx context.Context, client *http.Client, req *Request) (io.ReadCloser, error) {
+	data, err := json.Marshal(req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.DefaultHeader {
+		httpReq.Header[k] = v
+	}
+	if req.Config != nil && req.Config.HTTPOptions != nil {
+		for k, v := range req.Config.HTTPOptions.Headers {
+			httpReq.Header[k] = v
+		}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range req.Header {
+		httpReq.Header[k] = v
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call model: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, newAPIError(req, resp)
+	}
+	return resp.Body, nil
+}
+
+func newAPIError(req *Request, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	apiErr := &APIError{Provider: req.Provider, StatusCode: resp.StatusCode}
+	if req.ParseError != nil && req.ParseError(data, apiErr) {
+		return apiErr
+	}
+	apiErr.Message = strings.TrimSpace(string(data))
+	return apiErr
+}
+
+// APIError is returned when the API responds with an error.
+type APIError struct {
+	// Provider names the API, e.g. "openai".
+	Provider string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Type is the error type reported by the API, if any, e.g.
+	// "invalid_request_error".
+	Type string
+	// Code is the error code reported by the API, if any.
+	Code string
+	// Message is the human-readable error message.
+	Message string
+}
+
+// HTTPStatusCode returns the HTTP status code of the response. It allows
+// model.IsRetryable to classify the error.
+func (e *APIError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	status := fmt.Sprintf("status %d", e.StatusCode)
+	if e.Type != "" {
+		status += fmt.Sprintf(" (%s)", e.Type)
+	}
+	if e.Provider != "" {
+		return fmt.Sprintf("%s: %s: %s", e.Provider, status, msg)
+	}
+	return fmt.Sprintf("%s: %s", status, msg)
+}
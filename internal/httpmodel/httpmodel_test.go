@@ -0,0 +1,137 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httpmodel_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/httpmodel"
+)
+
+func TestPost(t *testing.T) {
+	var gotHeader http.Header
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	body, err := httpmodel.Post(t.Context(), server.Client(), &httpmodel.Request{
+		URL:           server.URL,
+		Body:          map[string]any{"model": "m"},
+		DefaultHeader: http.Header{"X-Default": {"default"}, "X-Config": {"default"}},
+		Config: &genai.GenerateContentConfig{
+			HTTPOptions: &genai.HTTPOptions{Headers: http.Header{"X-Config": {"config"}, "X-Auth": {"config"}}},
+		},
+		Header: http.Header{"X-Auth": {"key"}},
+	})
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ok" {
+		t.Errorf("Post() body = %q, want %q", data, "ok")
+	}
+	if diff := cmp.Diff(map[string]any{"model": "m"}, gotBody); diff != "" {
+		t.Errorf("request body mismatch (-want +got):\n%s", diff)
+	}
+	for k, want := range map[string]string{
+		"X-Default":    "default",
+		"X-Config":     "config",
+		"X-Auth":       "key",
+		"Content-Type": "application/json",
+	} {
+		if got := gotHeader.Get(k); got != want {
+			t.Errorf("header %s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestPost_Error(t *testing.T) {
+	parseError := func(data []byte, err *httpmodel.APIError) bool {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &body) != nil || body.Error == "" {
+			return false
+		}
+		err.Message = body.Error
+		return true
+	}
+	tests := []struct {
+		name    string
+		body    string
+		want    *httpmodel.APIError
+		wantMsg string
+	}{
+		{
+			name:    "API error format",
+			body:    `{"error": "model not found"}`,
+			want:    &httpmodel.APIError{Provider: "test", StatusCode: http.StatusNotFound, Message: "model not found"},
+			wantMsg: "test: status 404: model not found",
+		},
+		{
+			name:    "other body",
+			body:    "not found\n",
+			want:    &httpmodel.APIError{Provider: "test", StatusCode: http.StatusNotFound, Message: "not found"},
+			wantMsg: "test: status 404: not found",
+		},
+		{
+			name:    "empty body",
+			want:    &httpmodel.APIError{Provider: "test", StatusCode: http.StatusNotFound},
+			wantMsg: "test: status 404: Not Found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			_, err := httpmodel.Post(t.Context(), server.Client(), &httpmodel.Request{
+				Provider:   "test",
+				URL:        server.URL,
+				ParseError: parseError,
+			})
+			apiErr, ok := err.(*httpmodel.APIError)
+			if !ok {
+				t.Fatalf("Post() error = %v, want *APIError", err)
+			}
+			if diff := cmp.Diff(tt.want, apiErr); diff != "" {
+				t.Errorf("Post() error mismatch (-want +got):\n%s", diff)
+			}
+			if got := apiErr.Error(); got != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", got, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -18,7 +18,9 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/tool"
 )
 
@@ -45,10 +47,20 @@ type State struct {
 	DisallowTransferToParent bool
 	DisallowTransferToPeers  bool
 
-	InputSchema  *genai.Schema
-	OutputSchema *genai.Schema
+	InputSchema            *genai.Schema
+	OutputSchema           *genai.Schema
+	MaxOutputSchemaRetries int
+
+	MaxContinuations  int
+	ErrorOnTruncation bool
 
 	OutputKey string
+
+	TokenBudget *TokenBudget
+
+	Planner planner.Planner
+
+	CodeExecutor codeexecutor.Executor
 }
 
 type InstructionProvider func(ctx agent.ReadonlyContext) (string, error)
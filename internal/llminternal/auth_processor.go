@@ -0,0 +1,164 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/internal/credential"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// authPreprocessor stores the credentials provided by the client in response
+// to the credential requests of the tools, so the tools find them once their
+// function calls are resumed.
+//
+// See adk-python src/google/adk/auth/auth_preprocessor.py.
+func authPreprocessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
+	responses := authResponses(ctx)
+	if len(responses) == 0 {
+		return nil
+	}
+	if ctx.Credentials() == nil {
+		return fmt.Errorf("credential service is not set")
+	}
+	for _, resp := range responses {
+		cfg, err := parseAuthConfig(resp.Response)
+		if err != nil {
+			return fmt.Errorf("invalid response to credential request %q: %w", resp.ID, err)
+		}
+		if err := restoreClientSecret(ctx, cfg); err != nil {
+			return err
+		}
+		cred, err := auth.Exchange(ctx, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to exchange credential: %w", err)
+		}
+		if err := ctx.Credentials().Save(ctx, cfg.Key(), cred); err != nil {
+			return fmt.Errorf("failed to save credential: %w", err)
+		}
+	}
+	return nil
+}
+
+// authResponses returns the responses to credential requests sent by the
+// user in the last event of the session.
+func authResponses(ctx agent.InvocationContext) []*genai.FunctionResponse {
+	events := ctx.Session().Events()
+	if events.Len() == 0 {
+		return nil
+	}
+	last := events.At(events.Len() - 1)
+	if last.Author != "user" {
+		return nil
+	}
+	var responses []*genai.FunctionResponse
+	for _, resp := range listFunctionResponsesFromEvent(last) {
+		if resp.Name == auth.RequestCredentialFunctionName {
+			responses = append(responses, resp)
+		}
+	}
+	return responses
+}
+
+// restoreClientSecret sets the OAuth2 client secret of the config completed
+// by the client, which isn't sent to the client, from the credential of the
+// tool kept by RequestCredential.
+func restoreClientSecret(ctx agent.InvocationContext, cfg *auth.Config) error {
+	raw, err := ctx.Credentials().Load(ctx, credential.RequestKey(cfg.Key()))
+	if err != nil {
+		return fmt.Errorf("failed to load credential: %w", err)
+	}
+	if raw == nil || raw.OAuth2 == nil {
+		return nil
+	}
+	for _, cred := range []*auth.Credential{cfg.RawCredential, cfg.ExchangedCredential} {
+		if cred != nil && cred.OAuth2 != nil {
+			cred.OAuth2.ClientSecret = raw.OAuth2.ClientSecret
+		}
+	}
+	return nil
+}
+
+func parseAuthConfig(response map[string]any) (*auth.Config, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	var cfg auth.Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Scheme == nil {
+		return nil, fmt.Errorf("auth scheme is required")
+	}
+	return &cfg, nil
+}
+
+// generateAuthEvent returns the event requesting from the client the
+// credentials requested by the tools of the function response event, or nil
+// if the tools didn't request any.
+//
+// The event calls auth.RequestCredentialFunctionName once per credential.
+// These calls are long-running, so the invocation ends until the client
+// responds.
+func generateAuthEvent(ctx agent.InvocationContext, fnResponseEvent *session.Event) (*session.Event, error) {
+	configs := fnResponseEvent.Actions.RequestedAuthConfigs
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	content := &genai.Content{Role: fnResponseEvent.Content.Role}
+	for _, id := range slices.Sorted(maps.Keys(configs)) {
+		args, err := toArgs(auth.RequestCredentialArgs{FunctionCallID: id, Config: configs[id]})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build credential request: %w", err)
+		}
+		content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			Name: auth.RequestCredentialFunctionName,
+			Args: args,
+		}})
+	}
+	utils.PopulateClientFunctionCallID(content)
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Content = content
+	for _, call := range utils.FunctionCalls(content) {
+		ev.LongRunningToolIDs = append(ev.LongRunningToolIDs, call.ID)
+	}
+	return ev, nil
+}
+
+func toArgs(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var args map[string]any
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
@@ -22,10 +22,12 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"sync"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
 	icontext "google.golang.org/adk/internal/context"
@@ -36,10 +38,15 @@ import (
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 var ErrModelNotConfigured = errors.New("model not configured; ensure Model is set in llmagent.Config")
 
+// ErrNoFinalResponse is returned when the model stream ends with a partial
+// response instead of a final one, e.g. because the connection dropped.
+var ErrNoFinalResponse = errors.New("model stream ended without a final response")
+
 type BeforeModelCallback func(ctx agent.CallbackContext, llmRequest *model.LLMRequest) (*model.LLMResponse, error)
 
 type AfterModelCallback func(ctx agent.CallbackContext, llmResponse *model.LLMResponse, llmResponseError error) (*model.LLMResponse, error)
@@ -65,26 +72,53 @@ type Flow struct {
 	OnToolErrorCallbacks  []OnToolErrorCallback
 }
 
+// Names of the default processors. The planning and code execution
+// processors have both a request and a response processor.
+const (
+	BasicProcessor         = "basic"
+	AuthProcessor          = "auth"
+	InstructionsProcessor  = "instructions"
+	IdentityProcessor      = "identity"
+	ContentsProcessor      = "contents"
+	PlanningProcessor      = "planning"
+	CodeExecutionProcessor = "code_execution"
+	OutputSchemaProcessor  = "output_schema"
+	AgentTransferProcessor = "agent_transfer"
+	DisplayNameProcessor   = "display_name"
+)
+
+// RequestProcessor is a named request processor.
+type RequestProcessor struct {
+	Name    string
+	Process func(ctx agent.InvocationContext, req *model.LLMRequest) error
+}
+
+// ResponseProcessor is a named response processor.
+type ResponseProcessor struct {
+	Name    string
+	Process func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error
+}
+
 var (
-	DefaultRequestProcessors = []func(ctx agent.InvocationContext, req *model.LLMRequest) error{
-		basicRequestProcessor,
-		authPreprocessor,
-		instructionsRequestProcessor,
-		identityRequestProcessor,
-		ContentsRequestProcessor,
+	DefaultRequestProcessors = []RequestProcessor{
+		{BasicProcessor, basicRequestProcessor},
+		{AuthProcessor, authPreprocessor},
+		{InstructionsProcessor, instructionsRequestProcessor},
+		{IdentityProcessor, identityRequestProcessor},
+		{ContentsProcessor, ContentsRequestProcessor},
 		// Some implementations of NL Planning mark planning contents as thoughts in the post processor.
-		// Since these need to be unmarked, NL Planning should be after contentsRequestProcessor.
-		nlPlanningRequestProcessor,
+		// Since these are removed from the contents, NL Planning should be after contentsRequestProcessor.
+		{PlanningProcessor, nlPlanningRequestProcessor},
 		// Code execution should be after contentsRequestProcessor as it mutates the contents
 		// to optimize data files.
-		codeExecutionRequestProcessor,
-		outputSchemaRequestProcessor,
-		AgentTransferRequestProcessor,
-		removeDisplayNameIfExists,
+		{CodeExecutionProcessor, codeExecutionRequestProcessor},
+		{OutputSchemaProcessor, outputSchemaRequestProcessor},
+		{AgentTransferProcessor, AgentTransferRequestProcessor},
+		{DisplayNameProcessor, removeDisplayNameIfExists},
 	}
-	DefaultResponseProcessors = []func(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error{
-		nlPlanningResponseProcessor,
-		codeExecutionResponseProcessor,
+	DefaultResponseProcessors = []ResponseProcessor{
+		{PlanningProcessor, nlPlanningResponseProcessor},
+		{CodeExecutionProcessor, codeExecutionResponseProcessor},
 	}
 )
 
@@ -94,6 +128,14 @@ func (f *Flow) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error]
 			var lastEvent *session.Event
 			for ev, err := range f.runOneStep(ctx) {
 				if err != nil {
+					// End the invocation with an event the client can show.
+					var limitErr *agent.LimitExceededError
+					if errors.As(err, &limitErr) {
+						ctx.EndInvocation()
+						if !yield(limitExceededEvent(ctx, limitErr), nil) {
+							return
+						}
+					}
 					yield(nil, err)
 					return
 				}
@@ -107,21 +149,35 @@ func (f *Flow) Run(ctx agent.InvocationContext) iter.Seq2[*session.Event, error]
 				return
 			}
 			if lastEvent.LLMResponse.Partial {
-				// We may have reached max token limit during streaming mode.
-				// TODO: handle Partial response in model level. CL 781377328
-				yield(nil, fmt.Errorf("TODO: last event is not final"))
+				yield(nil, fmt.Errorf("agent %q: %w", ctx.Agent().Name(), ErrNoFinalResponse))
 				return
 			}
 		}
 	}
 }
 
+// limitExceededEvent returns the event ending an invocation which exceeded a
+// limit of its run config.
+func limitExceededEvent(ctx agent.InvocationContext, err *agent.LimitExceededError) *session.Event {
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.ErrorCode = agent.LimitExceededErrorCode
+	ev.ErrorMessage = err.Error()
+	ev.TurnComplete = true
+	return ev
+}
+
 func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
 	return func(yield func(*session.Event, error) bool) {
 		if f.Model == nil {
 			yield(nil, fmt.Errorf("agent %q: %w", ctx.Agent().Name(), ErrModelNotConfigured))
 			return
 		}
+		if err := runconfig.LimitsFromContext(ctx).Check(); err != nil {
+			yield(nil, err)
+			return
+		}
 
 		req := &model.LLMRequest{
 			Model: f.Model.Name(),
@@ -135,11 +191,37 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 		if ctx.Ended() {
 			return
 		}
+		// Resume the function calls waiting for the credentials or
+		// confirmations provided by the user, if any, before calling the LLM
+		// with their responses.
+		resumed, err := f.resumeFunctionCalls(ctx, req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if len(resumed) > 0 {
+			for _, ev := range resumed {
+				if !yield(ev, nil) {
+					return
+				}
+			}
+			return
+		}
+		warning, err := f.enforceTokenBudget(ctx, req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if warning != nil {
+			if !yield(warning, nil) {
+				return
+			}
+		}
 		spans := telemetry.StartTrace(ctx, "call_llm")
 		// Create event to pass to callback state delta
 		stateDelta := make(map[string]any)
 		// Calls the LLM.
-		for resp, err := range f.callLLM(ctx, req, stateDelta) {
+		for resp, err := range f.callLLMWithOutputValidation(ctx, req, stateDelta) {
 			if err != nil {
 				yield(nil, err)
 				return
@@ -155,16 +237,10 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 				continue
 			}
 
-			// TODO: temporarily convert
-			tools := make(map[string]tool.Tool)
-			for k, v := range req.Tools {
-				tool, ok := v.(tool.Tool)
-				if !ok {
-					if !yield(nil, fmt.Errorf("unexpected tool type %T for tool %v", v, k)) {
-						return
-					}
-				}
-				tools[k] = tool
+			tools, err := requestTools(req)
+			if err != nil {
+				yield(nil, err)
+				return
 			}
 
 			// Build the event and yield.
@@ -173,11 +249,23 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 			if !yield(modelResponseEvent, nil) {
 				return
 			}
-			// TODO: generate and yield an auth event if needed.
+
+			// Execute the code written by the model, if any.
+			codeEv, err := f.executeCode(ctx, resp)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if codeEv != nil {
+				if !yield(codeEv, nil) {
+					return
+				}
+				continue
+			}
 
 			// Handle function calls.
 
-			ev, err := f.handleFunctionCalls(ctx, tools, resp)
+			ev, err := f.handleFunctionCalls(ctx, tools, resp, nil)
 			if err != nil {
 				yield(nil, err)
 				return
@@ -189,9 +277,29 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 			if !yield(ev, nil) {
 				return
 			}
+			// Request the credentials and confirmations requested by the
+			// tools from the client. The invocation ends until the client
+			// provides them.
+			suspended, err := suspendEvents(ctx, utils.FunctionCalls(resp.Content), ev)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if len(suspended) > 0 {
+				for _, ev := range suspended {
+					if !yield(ev, nil) {
+						return
+					}
+				}
+				return
+			}
 
 			// If the model response is structured, yield it as a final model response event.
-			outputSchemaResponse, err := retrieveStructuredModelResponse(ev)
+			var outputSchema *genai.Schema
+			if llmAgent := asLLMAgent(ctx.Agent()); llmAgent != nil {
+				outputSchema = llmAgent.internal().OutputSchema
+			}
+			outputSchemaResponse, err := retrieveStructuredModelResponse(ev, outputSchema)
 			if err != nil {
 				yield(nil, err)
 				return
@@ -223,6 +331,20 @@ func (f *Flow) runOneStep(ctx agent.InvocationContext) iter.Seq2[*session.Event,
 	}
 }
 
+// requestTools returns the tools of the request by name.
+func requestTools(req *model.LLMRequest) (map[string]tool.Tool, error) {
+	// TODO: temporarily convert
+	tools := make(map[string]tool.Tool)
+	for k, v := range req.Tools {
+		tool, ok := v.(tool.Tool)
+		if !ok {
+			return nil, fmt.Errorf("unexpected tool type %T for tool %v", v, k)
+		}
+		tools[k] = tool
+	}
+	return tools, nil
+}
+
 func (f *Flow) preprocess(ctx agent.InvocationContext, req *model.LLMRequest) error {
 	llmAgent, ok := ctx.Agent().(Agent)
 	if !ok {
@@ -296,6 +418,11 @@ func (f *Flow) callLLM(ctx agent.InvocationContext, req *model.LLMRequest, state
 		// TODO: RunLive mode when invocation_context.run_config.support_cfc is true.
 		useStream := runconfig.FromContext(ctx).StreamingMode == runconfig.StreamingModeSSE
 
+		if err := runconfig.LimitsFromContext(ctx).AddLLMCall(); err != nil {
+			yield(nil, err)
+			return
+		}
+
 		for resp, err := range f.Model.GenerateContent(ctx, req, useStream) {
 			if err != nil {
 				cbResp, cbErr := f.runOnModelErrorCallbacks(ctx, req, stateDelta, err)
@@ -466,66 +593,58 @@ Suggested fixes:
   - Check for typos in function name`, toolName, joinedTools)
 }
 
-// handleFunctionCalls calls the functions and returns the function response event.
+// handleFunctionCalls runs the function calls of the response and returns
+// the merged function response event. The function calls with a
+// confirmation are resumed after the user responded to their confirmation
+// request.
+//
+// The function calls run concurrently, up to the MaxConcurrentToolCalls of
+// the run config at a time, except the calls of tools implementing
+// tool.Sequential, which run alone. The responses are merged in the order of
+// the function calls.
+//
+// Long-running tools returning an empty result get no function response: the
+// client sends it later, which resumes the invocation. The returned event is
+// nil if no function call has a response.
 //
 // TODO: accept filters to include/exclude function calls.
-// TODO: check feasibility of running tool.Run concurrently.
-func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse) (*session.Event, error) {
-	var fnResponseEvents []*session.Event
-
+func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, resp *model.LLMResponse, confirmations map[string]*toolconfirmation.ToolConfirmation) (*session.Event, error) {
 	fnCalls := utils.FunctionCalls(resp.Content)
-	toolNames := slices.Collect(maps.Keys(toolsDict))
-	var result map[string]any
-	for _, fnCall := range fnCalls {
-		toolCtx := toolinternal.NewToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)})
-
-		spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
-		curTool, found := toolsDict[fnCall.Name]
-		if !found {
-			err := newToolNotFoundError(fnCall.Name, toolNames)
-			result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
-			if err != nil {
-				result = map[string]any{"error": err.Error()}
-			}
-		} else if funcTool, ok := curTool.(toolinternal.FunctionTool); !ok {
-			err := newToolNotFoundError(fnCall.Name, toolNames)
-			result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
-			if err != nil {
-				result = map[string]any{"error": err.Error()}
-			}
-		} else {
-			result = f.callTool(toolCtx, funcTool, fnCall.Args)
-		}
-
-		// TODO: handle long-running tool.
-		ev := session.NewEvent(ctx.InvocationID())
-		ev.LLMResponse = model.LLMResponse{
-			Content: &genai.Content{
-				Role: "user",
-				Parts: []*genai.Part{
-					{
-						FunctionResponse: &genai.FunctionResponse{
-							ID:       fnCall.ID,
-							Name:     fnCall.Name,
-							Response: result,
-						},
-					},
-				},
-			},
-		}
-		ev.Author = ctx.Agent().Name()
-		ev.Branch = ctx.Branch()
-		ev.Actions = *toolCtx.Actions()
-
-		traceTool := curTool
-		if traceTool == nil {
-			traceTool = &fakeTool{name: fnCall.Name}
+	if err := runconfig.LimitsFromContext(ctx).AddToolCalls(len(fnCalls)); err != nil {
+		return nil, err
+	}
+	fnResponseEvents := make([]*session.Event, len(fnCalls))
+	if len(fnCalls) == 1 {
+		fnResponseEvents[0] = f.handleFunctionCall(ctx, toolsDict, fnCalls[0], confirmations[fnCalls[0].ID])
+	} else {
+		var limit chan struct{}
+		if cfg := runconfig.FromContext(ctx); cfg != nil && cfg.MaxConcurrentToolCalls > 0 {
+			limit = make(chan struct{}, cfg.MaxConcurrentToolCalls)
+		}
+		// Sequential calls hold the write lock, so they run alone.
+		var mu sync.RWMutex
+		var wg sync.WaitGroup
+		for i, fnCall := range fnCalls {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if limit != nil {
+					limit <- struct{}{}
+					defer func() { <-limit }()
+				}
+				if isSequential(toolsDict[fnCall.Name]) {
+					mu.Lock()
+					defer mu.Unlock()
+				} else {
+					mu.RLock()
+					defer mu.RUnlock()
+				}
+				fnResponseEvents[i] = f.handleFunctionCall(ctx, toolsDict, fnCall, confirmations[fnCall.ID])
+			}()
 		}
-		telemetry.TraceToolCall(spans, traceTool, fnCall.Args, ev)
-
-		fnResponseEvents = append(fnResponseEvents, ev)
+		wg.Wait()
 	}
-	mergedEvent, err := mergeParallelFunctionResponseEvents(fnResponseEvents)
+	mergedEvent, err := mergeParallelFunctionResponseEvents(slices.DeleteFunc(fnResponseEvents, func(ev *session.Event) bool { return ev == nil }))
 	if err != nil {
 		return mergedEvent, err
 	}
@@ -535,6 +654,72 @@ func (f *Flow) handleFunctionCalls(ctx agent.InvocationContext, toolsDict map[st
 	return mergedEvent, nil
 }
 
+// handleFunctionCall runs the function call and returns its function
+// response event, or nil if a long-running tool returned no result.
+func (f *Flow) handleFunctionCall(ctx agent.InvocationContext, toolsDict map[string]tool.Tool, fnCall *genai.FunctionCall, confirmation *toolconfirmation.ToolConfirmation) *session.Event {
+	toolCtx := toolinternal.NewConfirmedToolContext(ctx, fnCall.ID, &session.EventActions{StateDelta: make(map[string]any)}, confirmation)
+
+	var result map[string]any
+	spans := telemetry.StartTrace(ctx, "execute_tool "+fnCall.Name)
+	curTool, found := toolsDict[fnCall.Name]
+	if !found {
+		err := newToolNotFoundError(fnCall.Name, slices.Collect(maps.Keys(toolsDict)))
+		result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
+		if err != nil {
+			result = map[string]any{"error": err.Error()}
+		}
+	} else if funcTool, ok := curTool.(toolinternal.FunctionTool); !ok {
+		err := newToolNotFoundError(fnCall.Name, slices.Collect(maps.Keys(toolsDict)))
+		result, err = f.runOnToolErrorCallbacks(toolCtx, &fakeTool{name: fnCall.Name}, fnCall.Args, err)
+		if err != nil {
+			result = map[string]any{"error": err.Error()}
+		}
+	} else {
+		result = f.callTool(toolCtx, funcTool, fnCall.Args)
+	}
+
+	if curTool != nil && curTool.IsLongRunning() && len(result) == 0 {
+		// There's no function response to trace, end the spans anyway.
+		for _, span := range spans {
+			span.End()
+		}
+		return nil
+	}
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.LLMResponse = model.LLMResponse{
+		Content: &genai.Content{
+			Role: "user",
+			Parts: []*genai.Part{
+				{
+					FunctionResponse: &genai.FunctionResponse{
+						ID:       fnCall.ID,
+						Name:     fnCall.Name,
+						Response: result,
+					},
+				},
+			},
+		},
+	}
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Actions = *toolCtx.Actions()
+
+	traceTool := curTool
+	if traceTool == nil {
+		traceTool = &fakeTool{name: fnCall.Name}
+	}
+	telemetry.TraceToolCall(spans, traceTool, fnCall.Args, ev)
+	return ev
+}
+
+// isSequential reports whether the function calls of the tool must run
+// alone.
+func isSequential(t tool.Tool) bool {
+	s, ok := t.(tool.Sequential)
+	return ok && s.Sequential()
+}
+
 func (f *Flow) runOnToolErrorCallbacks(toolCtx tool.Context, tool tool.Tool, fArgs map[string]any, err error) (map[string]any, error) {
 	pluginManager := pluginManagerFromContext(toolCtx)
 	if pluginManager != nil {
@@ -688,6 +873,26 @@ func mergeEventActions(base, other *session.EventActions) *session.EventActions
 	if other.StateDelta != nil {
 		base.StateDelta = deepMergeMap(base.StateDelta, other.StateDelta)
 	}
+	for name, version := range other.ArtifactDelta {
+		if base.ArtifactDelta == nil {
+			base.ArtifactDelta = make(map[string]int64)
+		}
+		// Concurrent function calls may save the same artifact; keep the
+		// latest version.
+		base.ArtifactDelta[name] = max(base.ArtifactDelta[name], version)
+	}
+	if other.RequestedAuthConfigs != nil {
+		if base.RequestedAuthConfigs == nil {
+			base.RequestedAuthConfigs = make(map[string]*auth.Config)
+		}
+		maps.Copy(base.RequestedAuthConfigs, other.RequestedAuthConfigs)
+	}
+	if other.RequestedToolConfirmations != nil {
+		if base.RequestedToolConfirmations == nil {
+			base.RequestedToolConfirmations = make(map[string]*toolconfirmation.ToolConfirmation)
+		}
+		maps.Copy(base.RequestedToolConfirmations, other.RequestedToolConfirmations)
+	}
 	return base
 }
 
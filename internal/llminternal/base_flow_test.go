@@ -16,11 +16,15 @@ package llminternal
 
 import (
 	"errors"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/agent/runconfig"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/model"
@@ -507,6 +511,18 @@ func TestMergeEventActions(t *testing.T) {
 				StateDelta:        map[string]any{"key1": "value1"},
 			},
 		},
+		{
+			name: "artifact delta merged - latest version wins",
+			base: &session.EventActions{
+				ArtifactDelta: map[string]int64{"report": 2, "a": 1},
+			},
+			other: &session.EventActions{
+				ArtifactDelta: map[string]int64{"report": 1, "b": 1},
+			},
+			want: &session.EventActions{
+				ArtifactDelta: map[string]int64{"report": 2, "a": 1, "b": 1},
+			},
+		},
 		{
 			name: "skip summarization merging - any true wins",
 			base: &session.EventActions{
@@ -575,3 +591,78 @@ func TestMergeEventActions(t *testing.T) {
 		})
 	}
 }
+
+type sequentialMockTool struct {
+	mockFunctionTool
+}
+
+func (m *sequentialMockTool) Sequential() bool {
+	return true
+}
+
+func TestHandleFunctionCalls_Concurrency(t *testing.T) {
+	a, err := agent.New(agent.Config{Name: "test_agent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		limit      int
+		sequential bool
+		wantMax    int32
+	}{
+		{name: "no limit", wantMax: 3},
+		{name: "limit", limit: 2, wantMax: 2},
+		{name: "sequential tools", sequential: true, wantMax: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			tools := make(map[string]tool.Tool)
+			var parts []*genai.Part
+			for _, name := range []string{"a", "b", "c"} {
+				mock := mockFunctionTool{
+					name: name,
+					runFunc: func(ctx tool.Context, args map[string]any) (map[string]any, error) {
+						n := running.Add(1)
+						defer running.Add(-1)
+						for m := maxRunning.Load(); n > m && !maxRunning.CompareAndSwap(m, n); m = maxRunning.Load() {
+						}
+						time.Sleep(20 * time.Millisecond)
+						ctx.Actions().StateDelta["last"] = name
+						return map[string]any{"name": name}, nil
+					},
+				}
+				if tt.sequential {
+					tools[name] = &sequentialMockTool{mock}
+				} else {
+					tools[name] = &mock
+				}
+				parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{ID: "id_" + name, Name: name}})
+			}
+			ctx := icontext.NewInvocationContext(runconfig.ToContext(t.Context(), &runconfig.RunConfig{MaxConcurrentToolCalls: tt.limit}), icontext.InvocationContextParams{
+				Agent: a,
+			})
+
+			ev, err := (&Flow{}).handleFunctionCalls(ctx, tools, &model.LLMResponse{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := maxRunning.Load(); got != tt.wantMax {
+				t.Errorf("max concurrent calls = %d, want %d", got, tt.wantMax)
+			}
+			// The responses and state deltas are merged in the order of the calls.
+			var got []string
+			for _, part := range ev.Content.Parts {
+				got = append(got, part.FunctionResponse.ID)
+			}
+			if diff := cmp.Diff([]string{"id_a", "id_b", "id_c"}, got); diff != "" {
+				t.Errorf("function responses mismatch (-want +got):\n%s", diff)
+			}
+			if got := ev.Actions.StateDelta["last"]; got != "c" {
+				t.Errorf("merged state delta last = %v, want c", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,286 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+const (
+	// maxCodeExecutionErrors is the number of consecutive failed executions
+	// after which the code of the model responses is no longer executed in
+	// the invocation.
+	maxCodeExecutionErrors = 2
+
+	codeResultStartDelimiter = "```tool_output\n"
+	codeResultEndDelimiter   = "\n```"
+
+	// dataFileMIMEType is the MIME type of the user files made available to
+	// the code.
+	dataFileMIMEType = "text/csv"
+)
+
+// codeBlockRegex matches the first fenced code block of a response, in the
+// prefix and code groups.
+var codeBlockRegex = regexp.MustCompile("(?s)^(?P<prefix>.*?)(?:```tool_code\n|```python\n)(?P<code>.*?)\n```")
+
+// codeExecutionRequestProcessor adds the built-in code execution tool to the
+// request, or, for other executors, replaces the user data files by their
+// names and converts the code execution parts of the contents to text.
+func codeExecutionRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
+	executor := agentCodeExecutor(ctx)
+	if executor == nil {
+		return nil
+	}
+	if builtIn, ok := executor.(codeexecutor.BuiltIn); ok {
+		return builtIn.ProcessRequest(req)
+	}
+	for i, c := range req.Contents {
+		req.Contents[i] = convertCodeExecutionParts(c)
+	}
+	return nil
+}
+
+// codeExecutionResponseProcessor truncates the response after its first code
+// block, converted to an executable code part. The code is executed by
+// Flow.executeCode once the response is yielded.
+func codeExecutionResponseProcessor(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+	executor := agentCodeExecutor(ctx)
+	if executor == nil || resp.Content == nil || resp.Partial {
+		return nil
+	}
+	if _, ok := executor.(codeexecutor.BuiltIn); ok {
+		return nil
+	}
+	if consecutiveCodeExecutionErrors(ctx) >= maxCodeExecutionErrors {
+		return nil
+	}
+	extractCode(resp.Content)
+	return nil
+}
+
+// executeCode executes the code of a response ending with an executable code
+// part, and returns the event with the result. The output files are saved as
+// artifacts. It returns nil if there is no code to execute.
+func (f *Flow) executeCode(ctx agent.InvocationContext, resp *model.LLMResponse) (*session.Event, error) {
+	executor := agentCodeExecutor(ctx)
+	if executor == nil || resp.Content == nil || len(resp.Content.Parts) == 0 {
+		return nil, nil
+	}
+	if _, ok := executor.(codeexecutor.BuiltIn); ok {
+		return nil, nil
+	}
+	code := resp.Content.Parts[len(resp.Content.Parts)-1].ExecutableCode
+	if code == nil {
+		return nil, nil
+	}
+
+	result, err := executor.Execute(ctx, &codeexecutor.Input{
+		Code:        code.Code,
+		InputFiles:  dataFiles(ctx.Session().Events()),
+		ExecutionID: ctx.Session().ID(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute code: %w", err)
+	}
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	var saved []string
+	if result.Stderr == "" && ctx.Artifacts() != nil {
+		for _, file := range result.OutputFiles {
+			resp, err := ctx.Artifacts().Save(ctx, file.Name, genai.NewPartFromBytes(file.Content, file.MIMEType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to save output file %q: %w", file.Name, err)
+			}
+			if ev.Actions.ArtifactDelta == nil {
+				ev.Actions.ArtifactDelta = make(map[string]int64)
+			}
+			ev.Actions.ArtifactDelta[file.Name] = resp.Version
+			saved = append(saved, file.Name)
+		}
+	}
+	ev.Content = genai.NewContentFromParts([]*genai.Part{codeExecutionResultPart(result, saved)}, genai.RoleModel)
+	return ev, nil
+}
+
+func agentCodeExecutor(ctx agent.InvocationContext) codeexecutor.Executor {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return nil
+	}
+	return llmAgent.internal().CodeExecutor
+}
+
+// extractCode truncates the content after its first code block, converted to
+// an executable code part, and returns the code. Content already ending with
+// an executable code part is left unchanged. If there is no code block, it
+// returns an empty string.
+func extractCode(c *genai.Content) string {
+	for i, p := range c.Parts {
+		if p.ExecutableCode == nil {
+			continue
+		}
+		if i == len(c.Parts)-1 || c.Parts[i+1].CodeExecutionResult == nil {
+			c.Parts = c.Parts[:i+1]
+			return p.ExecutableCode.Code
+		}
+	}
+
+	var texts []string
+	for _, p := range c.Parts {
+		if p.Text != "" && !p.Thought {
+			texts = append(texts, p.Text)
+		}
+	}
+	match := codeBlockRegex.FindStringSubmatch(strings.Join(texts, "\n"))
+	if match == nil {
+		return ""
+	}
+	prefix, code := match[codeBlockRegex.SubexpIndex("prefix")], match[codeBlockRegex.SubexpIndex("code")]
+	if code == "" {
+		return ""
+	}
+	var parts []*genai.Part
+	if prefix != "" {
+		parts = append(parts, genai.NewPartFromText(prefix))
+	}
+	c.Parts = append(parts, genai.NewPartFromExecutableCode(code, genai.LanguagePython))
+	return code
+}
+
+// codeExecutionResultPart returns the part reporting the result to the model.
+func codeExecutionResultPart(result *codeexecutor.Result, savedFiles []string) *genai.Part {
+	if result.Stderr != "" {
+		return genai.NewPartFromCodeExecutionResult(genai.OutcomeFailed, result.Stderr)
+	}
+	var output []string
+	if result.Stdout != "" || len(savedFiles) == 0 {
+		output = append(output, "Code execution result:\n"+result.Stdout+"\n")
+	}
+	if len(savedFiles) > 0 {
+		names := make([]string, len(savedFiles))
+		for i, name := range savedFiles {
+			names[i] = "`" + name + "`"
+		}
+		output = append(output, "Saved artifacts:\n"+strings.Join(names, ","))
+	}
+	return genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, strings.Join(output, "\n\n"))
+}
+
+// convertCodeExecutionParts returns the content with its code execution
+// parts converted to text, the way models without code execution support
+// wrote them. The content is copied if it changes.
+func convertCodeExecutionParts(c *genai.Content) *genai.Content {
+	if c == nil {
+		return c
+	}
+	// A single code execution result is the output of an execution, sent
+	// back to the model as user input.
+	if len(c.Parts) == 1 && c.Parts[0].CodeExecutionResult != nil {
+		return genai.NewContentFromText(codeResultStartDelimiter+c.Parts[0].CodeExecutionResult.Output+codeResultEndDelimiter, genai.RoleUser)
+	}
+	var parts []*genai.Part
+	for i, p := range c.Parts {
+		var converted *genai.Part
+		switch {
+		case p.ExecutableCode != nil:
+			converted = genai.NewPartFromText("```tool_code\n" + p.ExecutableCode.Code + "\n```")
+		case p.InlineData != nil && p.InlineData.MIMEType == dataFileMIMEType && c.Role == genai.RoleUser:
+			converted = genai.NewPartFromText(fmt.Sprintf("\nAvailable file: `%s`\n", dataFileName(p.InlineData)))
+		default:
+			continue
+		}
+		if parts == nil {
+			parts = append([]*genai.Part(nil), c.Parts...)
+		}
+		parts[i] = converted
+	}
+	if parts == nil {
+		return c
+	}
+	return &genai.Content{Role: c.Role, Parts: parts}
+}
+
+// dataFiles returns the data files sent by the user in the events.
+func dataFiles(events session.Events) []*codeexecutor.File {
+	var files []*codeexecutor.File
+	seen := make(map[string]bool)
+	for ev := range events.All() {
+		if ev.Author != "user" || ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			if p.InlineData == nil || p.InlineData.MIMEType != dataFileMIMEType {
+				continue
+			}
+			name := dataFileName(p.InlineData)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			files = append(files, &codeexecutor.File{Name: name, Content: p.InlineData.Data, MIMEType: p.InlineData.MIMEType})
+		}
+	}
+	return files
+}
+
+// dataFileName returns the display name of the file, or a name derived from
+// its content.
+func dataFileName(b *genai.Blob) string {
+	if b.DisplayName != "" {
+		return b.DisplayName
+	}
+	sum := sha256.Sum256(b.Data)
+	return "data_" + hex.EncodeToString(sum[:4]) + ".csv"
+}
+
+// consecutiveCodeExecutionErrors returns the number of failed code
+// executions of the agent since its last successful one in the invocation.
+func consecutiveCodeExecutionErrors(ctx agent.InvocationContext) int {
+	events := ctx.Session().Events()
+	count := 0
+	for i := events.Len() - 1; i >= 0; i-- {
+		ev := events.At(i)
+		if ev.InvocationID != ctx.InvocationID() {
+			break
+		}
+		if ev.Author != ctx.Agent().Name() || ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			if p.CodeExecutionResult == nil {
+				continue
+			}
+			if p.CodeExecutionResult.Outcome == genai.OutcomeOK {
+				return count
+			}
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+func TestExtractCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  *genai.Content
+		wantCode string
+		want     *genai.Content
+	}{
+		{
+			name:    "no code",
+			content: genai.NewContentFromText("Paris.", genai.RoleModel),
+			want:    genai.NewContentFromText("Paris.", genai.RoleModel),
+		},
+		{
+			name:     "python block",
+			content:  genai.NewContentFromText("Computing:\n```python\nprint(2)\n```\nThe answer is", genai.RoleModel),
+			wantCode: "print(2)",
+			want: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Computing:\n"),
+				genai.NewPartFromExecutableCode("print(2)", genai.LanguagePython),
+			}, genai.RoleModel),
+		},
+		{
+			name: "first tool_code block across parts",
+			content: genai.NewContentFromParts([]*genai.Part{
+				{Text: "thinking", Thought: true},
+				genai.NewPartFromText("```tool_code"),
+				genai.NewPartFromText("a()\n```\n```python\nb()\n```"),
+			}, genai.RoleModel),
+			wantCode: "a()",
+			want:     genai.NewContentFromExecutableCode("a()", genai.LanguagePython, genai.RoleModel),
+		},
+		{
+			name:    "empty block",
+			content: genai.NewContentFromText("```python\n\n```", genai.RoleModel),
+			want:    genai.NewContentFromText("```python\n\n```", genai.RoleModel),
+		},
+		{
+			name: "executable code without result",
+			content: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromExecutableCode("a()", genai.LanguagePython),
+				genai.NewPartFromText("ignored"),
+			}, genai.RoleModel),
+			wantCode: "a()",
+			want:     genai.NewContentFromExecutableCode("a()", genai.LanguagePython, genai.RoleModel),
+		},
+		{
+			name: "executed code",
+			content: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromExecutableCode("a()", genai.LanguagePython),
+				genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "1"),
+			}, genai.RoleModel),
+			want: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromExecutableCode("a()", genai.LanguagePython),
+				genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "1"),
+			}, genai.RoleModel),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := extractCode(tc.content); got != tc.wantCode {
+				t.Errorf("extractCode() = %q, want %q", got, tc.wantCode)
+			}
+			if diff := cmp.Diff(tc.want, tc.content); diff != "" {
+				t.Errorf("content mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestConvertCodeExecutionParts(t *testing.T) {
+	csv := &genai.Part{InlineData: &genai.Blob{Data: []byte("a,b\n"), MIMEType: "text/csv", DisplayName: "data.csv"}}
+
+	testCases := []struct {
+		name    string
+		content *genai.Content
+		want    *genai.Content
+	}{
+		{
+			name:    "text",
+			content: genai.NewContentFromText("hi", genai.RoleModel),
+			want:    genai.NewContentFromText("hi", genai.RoleModel),
+		},
+		{
+			name: "executable code",
+			content: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Computing:"),
+				genai.NewPartFromExecutableCode("print(2)", genai.LanguagePython),
+			}, genai.RoleModel),
+			want: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Computing:"),
+				genai.NewPartFromText("```tool_code\nprint(2)\n```"),
+			}, genai.RoleModel),
+		},
+		{
+			name:    "execution result",
+			content: genai.NewContentFromCodeExecutionResult(genai.OutcomeOK, "2", genai.RoleModel),
+			want:    genai.NewContentFromText("```tool_output\n2\n```", genai.RoleUser),
+		},
+		{
+			name:    "data file",
+			content: genai.NewContentFromParts([]*genai.Part{genai.NewPartFromText("Sum b."), csv}, genai.RoleUser),
+			want: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Sum b."),
+				genai.NewPartFromText("\nAvailable file: `data.csv`\n"),
+			}, genai.RoleUser),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			original := clone(tc.content)
+			got := convertCodeExecutionParts(tc.content)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("convertCodeExecutionParts() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(original, tc.content); diff != "" {
+				t.Errorf("convertCodeExecutionParts() modified its input (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestDataFiles(t *testing.T) {
+	csv := func(name, data string) *genai.Part {
+		return &genai.Part{InlineData: &genai.Blob{Data: []byte(data), MIMEType: "text/csv", DisplayName: name}}
+	}
+	events := []*session.Event{
+		{Author: "user", LLMResponse: model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{
+			genai.NewPartFromText("Sum b."), csv("data.csv", "a,b\n"), csv("", "c,d\n"),
+		}, genai.RoleUser)}},
+		// Files of the model and duplicates are ignored.
+		{Author: "agent", LLMResponse: model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{csv("model.csv", "e\n")}, genai.RoleModel)}},
+		{Author: "user", LLMResponse: model.LLMResponse{Content: genai.NewContentFromParts([]*genai.Part{csv("data.csv", "a,b\n")}, genai.RoleUser)}},
+	}
+	want := []*codeexecutor.File{
+		{Name: "data.csv", Content: []byte("a,b\n"), MIMEType: "text/csv"},
+		{Name: dataFileName(csv("", "c,d\n").InlineData), Content: []byte("c,d\n"), MIMEType: "text/csv"},
+	}
+	if diff := cmp.Diff(want, dataFiles(testEvents(events))); diff != "" {
+		t.Errorf("dataFiles() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+type testEvents []*session.Event
+
+func (e testEvents) All() iter.Seq[*session.Event] { return slices.Values(e) }
+func (e testEvents) Len() int                      { return len(e) }
+func (e testEvents) At(i int) *session.Event       { return e[i] }
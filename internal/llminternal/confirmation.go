@@ -0,0 +1,91 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
+)
+
+// generateConfirmationEvent returns the event requesting from the client the
+// confirmations requested by the tools of the function response event, or
+// nil if the tools didn't request any.
+//
+// The event calls toolconfirmation.FunctionCallName once per confirmation.
+// These calls are long-running, so the invocation ends until the client
+// responds.
+func generateConfirmationEvent(ctx agent.InvocationContext, fnCalls []*genai.FunctionCall, fnResponseEvent *session.Event) (*session.Event, error) {
+	confirmations := fnResponseEvent.Actions.RequestedToolConfirmations
+	if len(confirmations) == 0 {
+		return nil, nil
+	}
+	content := &genai.Content{Role: fnResponseEvent.Content.Role}
+	for _, call := range fnCalls {
+		confirmation, ok := confirmations[call.ID]
+		if !ok {
+			continue
+		}
+		args, err := toArgs(toolconfirmation.RequestArgs{OriginalFunctionCall: call, ToolConfirmation: confirmation})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build confirmation request: %w", err)
+		}
+		content.Parts = append(content.Parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			Name: toolconfirmation.FunctionCallName,
+			Args: args,
+		}})
+	}
+	if len(content.Parts) == 0 {
+		return nil, fmt.Errorf("no function call for the requested confirmations %v", slices.Sorted(maps.Keys(confirmations)))
+	}
+	utils.PopulateClientFunctionCallID(content)
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Content = content
+	for _, call := range utils.FunctionCalls(content) {
+		ev.LongRunningToolIDs = append(ev.LongRunningToolIDs, call.ID)
+	}
+	return ev, nil
+}
+
+// parseToolConfirmation parses the response of the client to a confirmation
+// request. The confirmation is either the response itself, or encoded as
+// JSON in its "response" field.
+func parseToolConfirmation(response map[string]any) (*toolconfirmation.ToolConfirmation, error) {
+	var data []byte
+	if encoded, ok := response["response"].(string); ok {
+		data = []byte(encoded)
+	} else {
+		var err error
+		if data, err = json.Marshal(response); err != nil {
+			return nil, err
+		}
+	}
+	var confirmation toolconfirmation.ToolConfirmation
+	if err := json.Unmarshal(data, &confirmation); err != nil {
+		return nil, err
+	}
+	return &confirmation, nil
+}
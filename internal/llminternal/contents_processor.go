@@ -25,9 +25,11 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 // ContentRequestProcessor populates the LLMRequest's Contents based on
@@ -61,6 +63,8 @@ func ContentsRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest
 // buildContentsDefault returns the contents for the LLM request by applying
 // filtering, rearrangement, and content processing to the given events.
 func buildContentsDefault(agentName, invocationBranch string, events []*session.Event) ([]*genai.Content, error) {
+	events = applyCompactions(events)
+
 	// parse the events, leaving the contents and the function calls and responses from the current agent.
 	var filtered []*session.Event
 	for _, ev := range events {
@@ -79,7 +83,7 @@ func buildContentsDefault(agentName, invocationBranch string, events []*session.
 		if !eventBelongsToBranch(invocationBranch, ev) {
 			continue
 		}
-		if isAuthEvent(ev) {
+		if isAuthEvent(ev) || isConfirmationEvent(ev) {
 			continue
 		}
 		if isOtherAgentReply(agentName, ev) {
@@ -122,6 +126,63 @@ func buildContentsDefault(agentName, invocationBranch string, events []*session.
 	return contents, nil
 }
 
+// applyCompactions replaces the events summarized by a compaction with its
+// summary, placed where the first summarized event was. Compactions covered
+// by a later, larger one are ignored.
+func applyCompactions(events []*session.Event) []*session.Event {
+	var compactions []*session.EventCompaction
+	for _, ev := range events {
+		if c := ev.Actions.Compaction; c != nil && c.CompactedContent != nil {
+			compactions = append(compactions, c)
+		}
+	}
+	if len(compactions) == 0 {
+		return events
+	}
+
+	// Keep the compactions not covered by another one, the later wins on ties.
+	var active []*session.EventCompaction
+	for i, c := range compactions {
+		covered := false
+		for j, other := range compactions {
+			if i == j || other.StartTimestamp.After(c.StartTimestamp) || other.EndTimestamp.Before(c.EndTimestamp) {
+				continue
+			}
+			if j > i || !other.StartTimestamp.Equal(c.StartTimestamp) || !other.EndTimestamp.Equal(c.EndTimestamp) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			active = append(active, c)
+		}
+	}
+
+	var result []*session.Event
+	inserted := make(map[*session.EventCompaction]bool)
+	for _, ev := range events {
+		if ev.Actions.Compaction != nil {
+			continue
+		}
+		i := slices.IndexFunc(active, func(c *session.EventCompaction) bool {
+			return !ev.Timestamp.Before(c.StartTimestamp) && !ev.Timestamp.After(c.EndTimestamp)
+		})
+		if i < 0 {
+			result = append(result, ev)
+			continue
+		}
+		if c := active[i]; !inserted[c] {
+			inserted[c] = true
+			summary := session.NewEvent(ev.InvocationID)
+			summary.Timestamp = c.EndTimestamp
+			summary.Author = "user"
+			summary.Content = c.CompactedContent
+			result = append(result, summary)
+		}
+	}
+	return result
+}
+
 func eventBelongsToBranch(invocationBranch string, event *session.Event) bool {
 	if invocationBranch == "" || event.Branch == "" {
 		return true
@@ -502,20 +563,25 @@ func stringify(v any) string {
 	return string(s)
 }
 
-// requestEUCFunctionCallName is a special function to handle credential
-// request.
-const requestEUCFunctionCallName = "adk_request_credential"
-
 func isAuthEvent(ev *session.Event) bool {
+	return hasFunction(ev, auth.RequestCredentialFunctionName)
+}
+
+func isConfirmationEvent(ev *session.Event) bool {
+	return hasFunction(ev, toolconfirmation.FunctionCallName)
+}
+
+// hasFunction reports whether the event calls or responds to the function.
+func hasFunction(ev *session.Event, name string) bool {
 	c := utils.Content(ev)
 	if c == nil {
 		return false
 	}
 	for _, p := range c.Parts {
-		if p.FunctionCall != nil && p.FunctionCall.Name == requestEUCFunctionCallName {
+		if p.FunctionCall != nil && p.FunctionCall.Name == name {
 			return true
 		}
-		if p.FunctionResponse != nil && p.FunctionResponse.Name == requestEUCFunctionCallName {
+		if p.FunctionResponse != nil && p.FunctionResponse.Name == name {
 			return true
 		}
 	}
@@ -373,6 +373,40 @@ func TestContentsRequestProcessor(t *testing.T) {
 			},
 			want: nil,
 		},
+		{
+			name: "Compaction",
+			events: []*session.Event{
+				textEventAt(0, "user", "q1", "user"),
+				textEventAt(1, "testAgent", "a1", "model"),
+				textEventAt(2, "user", "q2", "user"),
+				textEventAt(3, "testAgent", "a2", "model"),
+				compactionEventAt(4, 0, 1, "summary 1"),
+				textEventAt(5, "user", "q3", "user"),
+			},
+			want: []*genai.Content{
+				genai.NewContentFromText("summary 1", "model"),
+				genai.NewContentFromText("q2", "user"),
+				genai.NewContentFromText("a2", "model"),
+				genai.NewContentFromText("q3", "user"),
+			},
+		},
+		{
+			name: "LatestCompactionCoversPrevious",
+			events: []*session.Event{
+				textEventAt(0, "user", "q1", "user"),
+				textEventAt(1, "testAgent", "a1", "model"),
+				textEventAt(2, "user", "q2", "user"),
+				compactionEventAt(3, 0, 1, "summary 1"),
+				textEventAt(4, "testAgent", "a2", "model"),
+				compactionEventAt(5, 0, 2, "summary 2"),
+				textEventAt(6, "user", "q3", "user"),
+			},
+			want: []*genai.Content{
+				genai.NewContentFromText("summary 2", "model"),
+				genai.NewContentFromText("a2", "model"),
+				genai.NewContentFromText("q3", "user"),
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -952,3 +986,25 @@ var (
 	_ session.Session = (*fakeSession)(nil)
 	_ session.Events  = (*fakeSession)(nil)
 )
+
+var testTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func textEventAt(sec int, author, text string, role genai.Role) *session.Event {
+	return &session.Event{
+		Timestamp:   testTime.Add(time.Duration(sec) * time.Second),
+		Author:      author,
+		LLMResponse: model.LLMResponse{Content: genai.NewContentFromText(text, role)},
+	}
+}
+
+func compactionEventAt(sec, startSec, endSec int, summary string) *session.Event {
+	return &session.Event{
+		Timestamp: testTime.Add(time.Duration(sec) * time.Second),
+		Author:    "user",
+		Actions: session.EventActions{Compaction: &session.EventCompaction{
+			StartTimestamp:   testTime.Add(time.Duration(startSec) * time.Second),
+			EndTimestamp:     testTime.Add(time.Duration(endSec) * time.Second),
+			CompactedContent: genai.NewContentFromText(summary, "model"),
+		}},
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+	"iter"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+const continuationPrompt = "Your response was cut off because it reached the output token limit. " +
+	"Continue exactly where it stopped, without repeating anything."
+
+// OutputTruncatedError is returned when the final response of the model is
+// truncated because it reached the output token limit, after all
+// continuations and if the agent opted in with ErrorOnTruncation.
+type OutputTruncatedError struct {
+	// Response is the truncated response, with the continuations stitched
+	// into it.
+	Response *model.LLMResponse
+	// Continuations is the number of continuations of the response.
+	Continuations int
+}
+
+func (e *OutputTruncatedError) Error() string {
+	return fmt.Sprintf("model response truncated at the output token limit after %d continuations", e.Continuations)
+}
+
+// callLLMWithContinuation calls the model like callLLM. Final responses
+// truncated because they reached the output token limit are continued: the
+// model is asked to continue, up to MaxContinuations times, and the final
+// responses are stitched into one. Once the continuations are exhausted, the
+// truncated response is yielded as the final response, or an
+// *OutputTruncatedError is returned if the agent sets ErrorOnTruncation.
+//
+// Partial responses are yielded as they come, so streaming clients see the
+// continuations after the truncated text. The truncated final responses are
+// not yielded while continued.
+func (f *Flow) callLLMWithContinuation(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
+	maxContinuations, errorOnTruncation := 0, false
+	if llmAgent := asLLMAgent(ctx.Agent()); llmAgent != nil {
+		maxContinuations = llmAgent.internal().MaxContinuations
+		errorOnTruncation = llmAgent.internal().ErrorOnTruncation
+	}
+	return func(yield func(*model.LLMResponse, error) bool) {
+		var truncated []*model.LLMResponse
+		for {
+			var last *model.LLMResponse
+			for resp, err := range f.callLLM(ctx, req, stateDelta) {
+				if err == nil && isTruncated(resp) {
+					last = resp
+					break
+				}
+				if err == nil && len(truncated) > 0 && !resp.Partial {
+					resp = stitchResponses(append(truncated, resp))
+					truncated = nil
+				}
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if last == nil {
+				return
+			}
+			truncated = append(truncated, last)
+			if len(truncated) > maxContinuations {
+				if !errorOnTruncation {
+					if len(truncated) > 1 {
+						last = stitchResponses(truncated)
+					}
+					yield(last, nil)
+					return
+				}
+				yield(nil, fmt.Errorf("agent %q: %w", ctx.Agent().Name(), &OutputTruncatedError{
+					Response:      stitchResponses(truncated),
+					Continuations: len(truncated) - 1,
+				}))
+				return
+			}
+			req.Contents = append(req.Contents, last.Content, genai.NewContentFromText(continuationPrompt, genai.RoleUser))
+		}
+	}
+}
+
+// isTruncated reports whether resp is a final response truncated at the
+// output token limit. Truncated function calls are not continued.
+func isTruncated(resp *model.LLMResponse) bool {
+	if resp == nil || resp.Partial || resp.FinishReason != genai.FinishReasonMaxTokens || resp.Content == nil {
+		return false
+	}
+	for _, part := range resp.Content.Parts {
+		if part.FunctionCall != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// stitchResponses returns the last response with the contents of all the
+// responses, merging adjacent text parts.
+func stitchResponses(responses []*model.LLMResponse) *model.LLMResponse {
+	stitched := *responses[len(responses)-1]
+	content := &genai.Content{Role: genai.RoleModel}
+	for _, resp := range responses {
+		if resp.Content == nil {
+			continue
+		}
+		for _, part := range resp.Content.Parts {
+			if n := len(content.Parts); n > 0 && isText(part) && isText(content.Parts[n-1]) && content.Parts[n-1].Thought == part.Thought {
+				merged := *content.Parts[n-1]
+				merged.Text += part.Text
+				content.Parts[n-1] = &merged
+				continue
+			}
+			content.Parts = append(content.Parts, part)
+		}
+	}
+	stitched.Content = content
+	return &stitched
+}
+
+func isText(part *genai.Part) bool {
+	return part != nil && part.Text != "" && part.InlineData == nil && part.FileData == nil &&
+		part.FunctionCall == nil && part.FunctionResponse == nil && part.ExecutableCode == nil && part.CodeExecutionResult == nil
+}
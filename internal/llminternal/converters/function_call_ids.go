@@ -0,0 +1,69 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// FunctionCallIDs assigns IDs to function calls that don't have one and
+// matches function responses without ID to them by name, in call order.
+//
+// ADK removes client-generated function call IDs before sending contents to
+// the model, but some model APIs require every function result to reference
+// the ID of its call.
+type FunctionCallIDs struct {
+	prefix  string
+	next    int
+	pending map[string][]string
+}
+
+// NewFunctionCallIDs returns FunctionCallIDs generating IDs with the given
+// prefix, e.g. "call_".
+func NewFunctionCallIDs(prefix string) *FunctionCallIDs {
+	return &FunctionCallIDs{prefix: prefix, pending: make(map[string][]string)}
+}
+
+// ForCall returns the ID of the function call.
+func (c *FunctionCallIDs) ForCall(fc *genai.FunctionCall) string {
+	id := fc.ID
+	if id == "" {
+		c.next++
+		id = fmt.Sprintf("%s%d", c.prefix, c.next)
+	}
+	c.pending[fc.Name] = append(c.pending[fc.Name], id)
+	return id
+}
+
+// ForResponse returns the ID of the function call the response belongs to.
+func (c *FunctionCallIDs) ForResponse(fr *genai.FunctionResponse) string {
+	queue := c.pending[fr.Name]
+	if fr.ID != "" {
+		for i, id := range queue {
+			if id == fr.ID {
+				c.pending[fr.Name] = append(queue[:i:i], queue[i+1:]...)
+				break
+			}
+		}
+		return fr.ID
+	}
+	if len(queue) == 0 {
+		return ""
+	}
+	c.pending[fr.Name] = queue[1:]
+	return queue[0]
+}
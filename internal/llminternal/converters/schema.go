@@ -0,0 +1,128 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converters
+
+import (
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// Schema2JSONSchema converts the OpenAPI-style genai.Schema into a plain
+// JSON Schema object, as expected by non-Gemini model APIs.
+func Schema2JSONSchema(s *genai.Schema) map[string]any {
+	if s == nil {
+		return nil
+	}
+	out := make(map[string]any)
+	if s.Type != "" && s.Type != genai.TypeUnspecified {
+		typ := strings.ToLower(string(s.Type))
+		if s.Nullable != nil && *s.Nullable {
+			out["type"] = []string{typ, "null"}
+		} else {
+			out["type"] = typ
+		}
+	}
+	if s.Title != "" {
+		out["title"] = s.Title
+	}
+	if s.Description != "" {
+		out["description"] = s.Description
+	}
+	if s.Format != "" {
+		out["format"] = s.Format
+	}
+	if s.Pattern != "" {
+		out["pattern"] = s.Pattern
+	}
+	if len(s.Enum) > 0 {
+		out["enum"] = s.Enum
+	}
+	if s.Default != nil {
+		out["default"] = s.Default
+	}
+	if s.Example != nil {
+		out["examples"] = []any{s.Example}
+	}
+	if s.Items != nil {
+		out["items"] = Schema2JSONSchema(s.Items)
+	}
+	if len(s.Properties) > 0 {
+		props := make(map[string]any, len(s.Properties))
+		for name, p := range s.Properties {
+			props[name] = Schema2JSONSchema(p)
+		}
+		out["properties"] = props
+	}
+	if len(s.Required) > 0 {
+		out["required"] = s.Required
+	}
+	if len(s.AnyOf) > 0 {
+		anyOf := make([]any, 0, len(s.AnyOf))
+		for _, sub := range s.AnyOf {
+			anyOf = append(anyOf, Schema2JSONSchema(sub))
+		}
+		out["anyOf"] = anyOf
+	}
+	for key, v := range map[string]*int64{
+		"minItems":      s.MinItems,
+		"maxItems":      s.MaxItems,
+		"minLength":     s.MinLength,
+		"maxLength":     s.MaxLength,
+		"minProperties": s.MinProperties,
+		"maxProperties": s.MaxProperties,
+	} {
+		if v != nil {
+			out[key] = *v
+		}
+	}
+	if s.Minimum != nil {
+		out["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		out["maximum"] = *s.Maximum
+	}
+	return out
+}
+
+// FunctionParameters returns the JSON Schema describing the parameters of the
+// function declaration. ParametersJsonSchema takes precedence over Parameters.
+// An empty object schema is returned if the function takes no parameters.
+func FunctionParameters(decl *genai.FunctionDeclaration) any {
+	switch {
+	case decl.ParametersJsonSchema != nil:
+		return decl.ParametersJsonSchema
+	case decl.Parameters != nil:
+		return Schema2JSONSchema(decl.Parameters)
+	default:
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+}
+
+// ResponseJSONSchema returns the JSON Schema of the expected model output
+// configured in cfg, or nil if no schema is configured.
+// ResponseJsonSchema takes precedence over ResponseSchema.
+func ResponseJSONSchema(cfg *genai.GenerateContentConfig) any {
+	switch {
+	case cfg == nil:
+		return nil
+	case cfg.ResponseJsonSchema != nil:
+		return cfg.ResponseJsonSchema
+	case cfg.ResponseSchema != nil:
+		return Schema2JSONSchema(cfg.ResponseSchema)
+	default:
+		return nil
+	}
+}
@@ -15,6 +15,7 @@
 package llminternal
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 	"slices"
@@ -156,6 +157,15 @@ func replaceMatch(ctx agent.InvocationContext, match string) (string, error) {
 		return "", nil
 	}
 
+	// Structured values, such as outputs of agents with an output schema,
+	// are injected as JSON.
+	switch value.(type) {
+	case map[string]any, []any:
+		if data, err := json.Marshal(value); err == nil {
+			return string(data), nil
+		}
+	}
+
 	return fmt.Sprintf("%v", value), nil
 }
 
@@ -111,6 +111,12 @@ func TestInjectSessionState(t *testing.T) {
 			state:    map[string]any{"test_key": nil},
 			want:     "Value: ",
 		},
+		{
+			name:     "structured state value is injected as JSON",
+			template: "Plan: {plan}",
+			state:    map[string]any{"plan": map[string]any{"steps": []any{"a", "b"}}},
+			want:     `Plan: {"steps":["a","b"]}`,
+		},
 		// Corresponds to: test_inject_session_state_with_optional_missing_artifact_returns_empty
 		{
 			name:     "optional missing artifact",
@@ -0,0 +1,97 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+)
+
+// nlPlanningRequestProcessor appends the planning instruction of the agent's
+// planner to the request, and removes the thoughts from the contents.
+func nlPlanningRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
+	p := agentPlanner(ctx)
+	if p == nil {
+		return nil
+	}
+	instruction, err := p.BuildPlanningInstruction(icontext.NewReadonlyContext(ctx), req)
+	if err != nil {
+		return fmt.Errorf("failed to build planning instruction: %w", err)
+	}
+	if instruction != "" {
+		utils.AppendInstructions(req, instruction)
+	}
+	req.Contents = removeThoughts(req.Contents)
+	return nil
+}
+
+// nlPlanningResponseProcessor lets the agent's planner process the parts of
+// the response, e.g. to mark the planning parts as thoughts.
+func nlPlanningResponseProcessor(ctx agent.InvocationContext, req *model.LLMRequest, resp *model.LLMResponse) error {
+	if resp == nil || resp.Content == nil || len(resp.Content.Parts) == 0 {
+		return nil
+	}
+	p := agentPlanner(ctx)
+	if p == nil {
+		return nil
+	}
+	parts, err := p.ProcessPlanningResponse(icontext.NewReadonlyContext(ctx), resp.Content.Parts)
+	if err != nil {
+		return fmt.Errorf("failed to process planning response: %w", err)
+	}
+	if parts != nil {
+		resp.Content.Parts = parts
+	}
+	return nil
+}
+
+func agentPlanner(ctx agent.InvocationContext) planner.Planner {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return nil
+	}
+	return llmAgent.internal().Planner
+}
+
+// removeThoughts returns the contents without their thought parts. Contents
+// left without parts are dropped.
+func removeThoughts(contents []*genai.Content) []*genai.Content {
+	var result []*genai.Content
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		var parts []*genai.Part
+		for _, p := range c.Parts {
+			if p != nil && !p.Thought {
+				parts = append(parts, p)
+			}
+		}
+		switch {
+		case len(parts) == len(c.Parts):
+			result = append(result, c)
+		case len(parts) > 0:
+			result = append(result, &genai.Content{Role: c.Role, Parts: parts})
+		}
+	}
+	return result
+}
@@ -16,7 +16,10 @@ package llminternal
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"strings"
 
 	"google.golang.org/genai"
 
@@ -37,6 +40,10 @@ const (
 		"final answer in the specified schema format."
 )
 
+// ErrInvalidOutput is returned when the final response of the model doesn't
+// match the output schema of the agent, after all re-prompts.
+var ErrInvalidOutput = errors.New("output does not match the output schema")
+
 // outputSchemaRequestProcessor adds the set_model_response tool to handle structured output.
 func outputSchemaRequestProcessor(ctx agent.InvocationContext, req *model.LLMRequest) error {
 	llmAgent := asLLMAgent(ctx.Agent())
@@ -76,13 +83,18 @@ func createFinalModelResponseEvent(invocationContext agent.InvocationContext, re
 }
 
 // retrieveStructuredModelResponse checks if function response contains set_model_response tool and extract JSON.
-func retrieveStructuredModelResponse(ev *session.Event) (string, error) {
-	if ev == nil || ev.LLMResponse.Content == nil {
+// Only a response matching the schema is extracted: a failed call of the tool
+// is answered with an error instead.
+func retrieveStructuredModelResponse(ev *session.Event, schema *genai.Schema) (string, error) {
+	if ev == nil || schema == nil || ev.LLMResponse.Content == nil {
 		return "", nil
 	}
 
 	for _, part := range ev.LLMResponse.Content.Parts {
-		if part.FunctionResponse != nil && part.FunctionResponse.Name == "set_model_response" {
+		if part.FunctionResponse != nil && part.FunctionResponse.Name == setModelResponseToolName {
+			if err := utils.ValidateMapOnSchema(part.FunctionResponse.Response, schema, false); err != nil {
+				return "", nil
+			}
 			bytes, err := json.Marshal(part.FunctionResponse.Response)
 			if err != nil {
 				return "", fmt.Errorf("failed to marshal set_model_response: %w", err)
@@ -103,13 +115,15 @@ func needOutputSchemaProcessor(state *State) bool {
 	return hasTools && !canUseOutputSchemaWithTools
 }
 
+const setModelResponseToolName = "set_model_response"
+
 // setModelResponseTool implements tool.Tool and toolinternal.FunctionTool.
 type setModelResponseTool struct {
 	schema *genai.Schema
 }
 
 func (t *setModelResponseTool) Name() string {
-	return "set_model_response"
+	return setModelResponseToolName
 }
 
 func (t *setModelResponseTool) Description() string {
@@ -122,9 +136,9 @@ func (t *setModelResponseTool) IsLongRunning() bool {
 
 func (t *setModelResponseTool) Declaration() *genai.FunctionDeclaration {
 	return &genai.FunctionDeclaration{
-		Name:                 t.Name(),
-		Description:          t.Description(),
-		ParametersJsonSchema: t.schema,
+		Name:        t.Name(),
+		Description: t.Description(),
+		Parameters:  t.schema,
 	}
 }
 
@@ -138,3 +152,111 @@ func (t *setModelResponseTool) Run(ctx tool.Context, args any) (map[string]any,
 	}
 	return m, nil
 }
+
+// callLLMWithOutputValidation calls the model like callLLMWithContinuation.
+// If the agent has an output schema, final text responses and the arguments
+// of set_model_response calls are validated against it. On failure, the
+// model is re-prompted with the validation error, up to
+// MaxOutputSchemaRetries times, and ErrInvalidOutput is returned once the
+// retries are exhausted.
+//
+// The invalid responses and the re-prompts are not yielded, so they are not
+// part of the session.
+func (f *Flow) callLLMWithOutputValidation(ctx agent.InvocationContext, req *model.LLMRequest, stateDelta map[string]any) iter.Seq2[*model.LLMResponse, error] {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return f.callLLMWithContinuation(ctx, req, stateDelta)
+	}
+	state := llmAgent.internal()
+	if state.OutputSchema == nil {
+		return f.callLLMWithContinuation(ctx, req, stateDelta)
+	}
+	// With the set_model_response tool, the tool validates the output. A
+	// final text response is still accepted if it matches the schema.
+	feedback := "Respond again with only a JSON object matching the schema."
+	if needOutputSchemaProcessor(state) {
+		feedback = "Call the set_model_response tool with your final answer in the required format."
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		for attempt := 0; ; attempt++ {
+			var invalid *model.LLMResponse
+			var validationErr error
+			for resp, err := range f.callLLMWithContinuation(ctx, req, stateDelta) {
+				if err == nil {
+					if validationErr = validateOutput(resp, state.OutputSchema); validationErr != nil {
+						invalid = resp
+						break
+					}
+				}
+				if !yield(resp, err) {
+					return
+				}
+			}
+			if invalid == nil {
+				return
+			}
+			if attempt >= state.MaxOutputSchemaRetries {
+				yield(nil, fmt.Errorf("agent %q: %w: %v", ctx.Agent().Name(), ErrInvalidOutput, validationErr))
+				return
+			}
+			req.Contents = append(req.Contents, reprompt(invalid, validationErr, feedback)...)
+		}
+	}
+}
+
+// reprompt returns the contents re-prompting the model after its invalid
+// response. An invalid set_model_response call is answered with the error,
+// like a failed tool call.
+func reprompt(invalid *model.LLMResponse, validationErr error, feedback string) []*genai.Content {
+	msg := fmt.Sprintf("Your response does not match the required output schema: %v. %s", validationErr, feedback)
+	if call := setModelResponseCall(invalid); call != nil {
+		return []*genai.Content{
+			{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: call}}},
+			{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: &genai.FunctionResponse{
+				ID:       call.ID,
+				Name:     call.Name,
+				Response: map[string]any{"error": msg},
+			}}}},
+		}
+	}
+	return []*genai.Content{invalid.Content, genai.NewContentFromText(msg, genai.RoleUser)}
+}
+
+// setModelResponseCall returns the set_model_response call of the response,
+// or nil.
+func setModelResponseCall(resp *model.LLMResponse) *genai.FunctionCall {
+	for _, call := range utils.FunctionCalls(resp.Content) {
+		if call.Name == setModelResponseToolName {
+			return call
+		}
+	}
+	return nil
+}
+
+// validateOutput validates the text of a final model response, or the
+// arguments of its set_model_response call, against the schema. Partial
+// responses, other function calls and empty responses are not validated.
+func validateOutput(resp *model.LLMResponse, schema *genai.Schema) error {
+	if resp == nil || resp.Partial || resp.Content == nil {
+		return nil
+	}
+	if call := setModelResponseCall(resp); call != nil {
+		return utils.ValidateMapOnSchema(call.Args, schema, false)
+	}
+	var sb strings.Builder
+	for _, part := range resp.Content.Parts {
+		if part.FunctionCall != nil {
+			return nil
+		}
+		if part.Text != "" && !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	text := sb.String()
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	_, err := utils.ValidateOutputSchema(text, schema)
+	return err
+}
@@ -219,6 +219,14 @@ func TestCreateFinalModelResponseEvent(t *testing.T) {
 }
 
 func TestGetStructuredModelResponse(t *testing.T) {
+	schema := &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"result": {Type: genai.TypeNumber},
+		},
+		Required: []string{"result"},
+	}
+
 	t.Run("ExtractsResponse", func(t *testing.T) {
 		event := &session.Event{
 			LLMResponse: model.LLMResponse{
@@ -237,7 +245,7 @@ func TestGetStructuredModelResponse(t *testing.T) {
 			},
 		}
 
-		got, err := retrieveStructuredModelResponse(event)
+		got, err := retrieveStructuredModelResponse(event, schema)
 		if err != nil {
 			t.Fatalf("GetStructuredModelResponse error: %v", err)
 		}
@@ -270,7 +278,7 @@ func TestGetStructuredModelResponse(t *testing.T) {
 			},
 		}
 
-		got, err := retrieveStructuredModelResponse(event)
+		got, err := retrieveStructuredModelResponse(event, schema)
 		if err != nil {
 			t.Fatal("Expected nil for tool name mismatch, got error")
 		}
@@ -279,8 +287,33 @@ func TestGetStructuredModelResponse(t *testing.T) {
 		}
 	})
 
+	t.Run("NoResponseWhenToolFailed", func(t *testing.T) {
+		event := &session.Event{
+			LLMResponse: model.LLMResponse{
+				Content: &genai.Content{
+					Parts: []*genai.Part{
+						{
+							FunctionResponse: &genai.FunctionResponse{
+								Name:     "set_model_response",
+								Response: map[string]any{"error": "invalid output schema"},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		got, err := retrieveStructuredModelResponse(event, schema)
+		if err != nil {
+			t.Fatalf("GetStructuredModelResponse error: %v", err)
+		}
+		if got != "" {
+			t.Errorf("Expected empty string for failed tool call, got %q", got)
+		}
+	})
+
 	t.Run("NilEvent", func(t *testing.T) {
-		got, err := retrieveStructuredModelResponse(nil)
+		got, err := retrieveStructuredModelResponse(nil, schema)
 		if err != nil {
 			t.Fatal("Expected nil for nil event, got error")
 		}
@@ -0,0 +1,149 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"fmt"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
+)
+
+// suspendEvents returns the events requesting from the client the
+// credentials and confirmations requested by the tools of the function
+// response event. Their function calls are long-running, so the invocation
+// ends until the client responds.
+func suspendEvents(ctx agent.InvocationContext, fnCalls []*genai.FunctionCall, fnResponseEvent *session.Event) ([]*session.Event, error) {
+	var events []*session.Event
+	authEv, err := generateAuthEvent(ctx, fnResponseEvent)
+	if err != nil {
+		return nil, err
+	}
+	if authEv != nil {
+		events = append(events, authEv)
+	}
+	confirmationEv, err := generateConfirmationEvent(ctx, fnCalls, fnResponseEvent)
+	if err != nil {
+		return nil, err
+	}
+	if confirmationEv != nil {
+		events = append(events, confirmationEv)
+	}
+	return events, nil
+}
+
+// resumeFunctionCalls runs again the function calls suspended until the
+// client provides the credentials or confirmations they requested, once the
+// user responded to these requests in the last event of the session. It
+// returns the function response event, followed by the events suspending
+// the function calls again if their tools request it, or no events if there
+// is nothing to resume.
+//
+// The credentials are stored by authPreprocessor before. The confirmed
+// function calls are run with the arguments of the confirmation, if any.
+func (f *Flow) resumeFunctionCalls(ctx agent.InvocationContext, req *model.LLMRequest) ([]*session.Event, error) {
+	events := ctx.Session().Events()
+	if events.Len() == 0 {
+		return nil, nil
+	}
+	last := events.At(events.Len() - 1)
+	if last.Author != "user" {
+		return nil, nil
+	}
+	authCallIDs := make(map[string]bool)
+	confirmationsByRequest := make(map[string]*toolconfirmation.ToolConfirmation)
+	for _, resp := range listFunctionResponsesFromEvent(last) {
+		switch resp.Name {
+		case auth.RequestCredentialFunctionName:
+			authCallIDs[resp.ID] = true
+		case toolconfirmation.FunctionCallName:
+			confirmation, err := parseToolConfirmation(resp.Response)
+			if err != nil {
+				return nil, fmt.Errorf("invalid response to confirmation request %q: %w", resp.ID, err)
+			}
+			confirmationsByRequest[resp.ID] = confirmation
+		}
+	}
+	if len(authCallIDs) == 0 && len(confirmationsByRequest) == 0 {
+		return nil, nil
+	}
+
+	// Find the function calls which requested the credentials and
+	// confirmations.
+	callIDs := make(map[string]bool)
+	confirmations := make(map[string]*toolconfirmation.ToolConfirmation)
+	for i := events.Len() - 1; i >= 0; i-- {
+		ev := events.At(i)
+		if ev.Author != ctx.Agent().Name() {
+			continue
+		}
+		for _, call := range listFunctionCallsFromEvent(ev) {
+			switch {
+			case call.Name == auth.RequestCredentialFunctionName && authCallIDs[call.ID]:
+				if id, ok := call.Args["functionCallId"].(string); ok {
+					callIDs[id] = true
+				}
+			case call.Name == toolconfirmation.FunctionCallName && confirmationsByRequest[call.ID] != nil:
+				original, _ := call.Args["originalFunctionCall"].(map[string]any)
+				if id, ok := original["id"].(string); ok {
+					callIDs[id] = true
+					confirmations[id] = confirmationsByRequest[call.ID]
+				}
+			}
+		}
+	}
+	if len(callIDs) == 0 {
+		return nil, nil
+	}
+
+	for i := events.Len() - 1; i >= 0; i-- {
+		var calls []*genai.FunctionCall
+		var parts []*genai.Part
+		for _, call := range listFunctionCallsFromEvent(events.At(i)) {
+			if !callIDs[call.ID] {
+				continue
+			}
+			if c := confirmations[call.ID]; c != nil && c.Args != nil {
+				call = &genai.FunctionCall{ID: call.ID, Name: call.Name, Args: c.Args}
+			}
+			calls = append(calls, call)
+			parts = append(parts, &genai.Part{FunctionCall: call})
+		}
+		if len(calls) == 0 {
+			continue
+		}
+		tools, err := requestTools(req)
+		if err != nil {
+			return nil, err
+		}
+		ev, err := f.handleFunctionCalls(ctx, tools, &model.LLMResponse{
+			Content: &genai.Content{Role: genai.RoleModel, Parts: parts},
+		}, confirmations)
+		if err != nil || ev == nil {
+			return nil, err
+		}
+		suspended, err := suspendEvents(ctx, calls, ev)
+		if err != nil {
+			return nil, err
+		}
+		return append([]*session.Event{ev}, suspended...), nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,238 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// TokenBudgetMetadataKey is the key of the CustomMetadata entry of the
+// warning event emitted when a request is trimmed to fit the token budget.
+const TokenBudgetMetadataKey = "adk_token_budget"
+
+// ErrTokenBudgetExceeded is returned when a request doesn't fit the token
+// budget even after trimming the conversation history.
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// tokenCountMargin is the fraction of the budget above which the tokens of a
+// request are counted by the model. Below it, the estimate is trusted, to
+// avoid a call to the model before each step.
+const tokenCountMargin = 0.8
+
+// TokenBudget limits the number of input tokens of the requests sent to the
+// model.
+type TokenBudget struct {
+	MaxTokens                 int
+	MaxFunctionResponseTokens int
+}
+
+// enforceTokenBudget trims req to fit the token budget of the agent, if any.
+//
+// When the request is over budget, the following is trimmed in order, until
+// it fits:
+//  1. inline and file data of the history, oldest first;
+//  2. function responses larger than MaxFunctionResponseTokens, oldest first;
+//  3. the oldest turns of the history.
+//
+// The latest content is never dropped, and turns are dropped as a whole so
+// function calls stay paired with their responses.
+//
+// It returns a warning event describing the trimming, or nil if the request
+// was left untouched. The warning is partial, so it is streamed to the client
+// but not saved in the session, where it would repeat at every step of a long
+// session.
+func (f *Flow) enforceTokenBudget(ctx agent.InvocationContext, req *model.LLMRequest) (*session.Event, error) {
+	llmAgent := asLLMAgent(ctx.Agent())
+	if llmAgent == nil {
+		return nil, nil
+	}
+	budget := llmAgent.internal().TokenBudget
+	if budget == nil || budget.MaxTokens <= 0 {
+		return nil, nil
+	}
+
+	estimated := model.EstimateTokens(req)
+	if float64(estimated) < tokenCountMargin*float64(budget.MaxTokens) {
+		return nil, nil
+	}
+	total, err := model.CountTokens(ctx, f.Model, req)
+	if err != nil {
+		// Counting is best effort, fall back to the estimate.
+		total = estimated
+	}
+	if total <= budget.MaxTokens {
+		return nil, nil
+	}
+
+	t := &trimmer{
+		maxTokens:                 budget.MaxTokens,
+		maxFunctionResponseTokens: budget.MaxFunctionResponseTokens,
+		tokens:                    total,
+		// Per-content costs are estimated, scale them to the actual count.
+		scale: float64(total) / float64(max(estimated, 1)),
+	}
+	if t.maxFunctionResponseTokens <= 0 {
+		t.maxFunctionResponseTokens = budget.MaxTokens / 4
+	}
+	t.trim(req)
+
+	if t.tokens > budget.MaxTokens {
+		return nil, fmt.Errorf("agent %q: %w: request has about %d tokens after trimming the history, the budget is %d",
+			ctx.Agent().Name(), ErrTokenBudgetExceeded, t.tokens, budget.MaxTokens)
+	}
+
+	ev := session.NewEvent(ctx.InvocationID())
+	ev.Author = ctx.Agent().Name()
+	ev.Branch = ctx.Branch()
+	ev.Partial = true
+	ev.CustomMetadata = map[string]any{
+		TokenBudgetMetadataKey: map[string]any{
+			"max_tokens":                   budget.MaxTokens,
+			"tokens_before":                total,
+			"tokens_after":                 t.tokens,
+			"dropped_contents":             t.droppedContents,
+			"truncated_function_responses": t.truncatedFunctionResponses,
+			"omitted_blobs":                t.omittedBlobs,
+		},
+	}
+	return ev, nil
+}
+
+type trimmer struct {
+	maxTokens                 int
+	maxFunctionResponseTokens int
+	// tokens is the running count of the request.
+	tokens int
+	scale  float64
+
+	droppedContents            int
+	truncatedFunctionResponses int
+	omittedBlobs               int
+}
+
+func (t *trimmer) over() bool {
+	return t.tokens > t.maxTokens
+}
+
+func (t *trimmer) cost(c *genai.Content) int {
+	return int(math.Ceil(float64(model.EstimateContentTokens(c)) * t.scale))
+}
+
+func (t *trimmer) trim(req *model.LLMRequest) {
+	contents := req.Contents
+	if len(contents) == 0 {
+		return
+	}
+	last := len(contents) - 1
+
+	for i := 0; i < last && t.over(); i++ {
+		contents[i] = t.replaceParts(contents[i], t.omitBlob)
+	}
+	for i := 0; i <= last && t.over(); i++ {
+		contents[i] = t.replaceParts(contents[i], t.truncateFunctionResponse)
+	}
+
+	// Only cut the history right before a user turn.
+	cut, removed, cutRemoved := 0, 0, 0
+	for i := 1; i <= last && t.tokens-cutRemoved > t.maxTokens; i++ {
+		removed += t.cost(contents[i-1])
+		if startsUserTurn(contents[i]) {
+			cut, cutRemoved = i, removed
+		}
+	}
+	t.tokens -= cutRemoved
+	t.droppedContents = cut
+	req.Contents = contents[cut:]
+}
+
+// replaceParts returns a copy of c with each part replaced by fn, and updates
+// the running count.
+func (t *trimmer) replaceParts(c *genai.Content, fn func(*genai.Part) *genai.Part) *genai.Content {
+	if c == nil {
+		return nil
+	}
+	changed := false
+	parts := make([]*genai.Part, len(c.Parts))
+	for i, p := range c.Parts {
+		parts[i] = fn(p)
+		changed = changed || parts[i] != p
+	}
+	if !changed {
+		return c
+	}
+	trimmed := &genai.Content{Role: c.Role, Parts: parts}
+	t.tokens -= t.cost(c) - t.cost(trimmed)
+	return trimmed
+}
+
+func (t *trimmer) omitBlob(p *genai.Part) *genai.Part {
+	var mimeType string
+	switch {
+	case p == nil:
+		return p
+	case p.InlineData != nil:
+		mimeType = p.InlineData.MIMEType
+	case p.FileData != nil:
+		mimeType = p.FileData.MIMEType
+	default:
+		return p
+	}
+	t.omittedBlobs++
+	return genai.NewPartFromText(fmt.Sprintf("[%s data omitted to fit the context window]", mimeType))
+}
+
+func (t *trimmer) truncateFunctionResponse(p *genai.Part) *genai.Part {
+	if p == nil || p.FunctionResponse == nil {
+		return p
+	}
+	data, err := json.Marshal(p.FunctionResponse.Response)
+	if err != nil {
+		return p
+	}
+	// Keep about four bytes per token, like model.EstimateTokens.
+	limit := int(float64(t.maxFunctionResponseTokens*4) / t.scale)
+	if len(data) <= limit {
+		return p
+	}
+	t.truncatedFunctionResponses++
+	fr := *p.FunctionResponse
+	fr.Response = map[string]any{
+		"result":    strings.ToValidUTF8(string(data[:limit]), "") + "...",
+		"truncated": true,
+	}
+	return &genai.Part{FunctionResponse: &fr}
+}
+
+func startsUserTurn(c *genai.Content) bool {
+	if c == nil || c.Role != genai.RoleUser {
+		return false
+	}
+	for _, p := range c.Parts {
+		if p != nil && p.FunctionResponse != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llminternal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestTrimmer(t *testing.T) {
+	blob := genai.NewContentFromParts([]*genai.Part{
+		genai.NewPartFromBytes([]byte("png"), "image/png"),
+		genai.NewPartFromText("describe"),
+	}, genai.RoleUser)
+
+	tests := []struct {
+		name                      string
+		contents                  []*genai.Content
+		maxTokens                 int
+		maxFunctionResponseTokens int
+		want                      []*genai.Content
+		wantDropped               int
+		wantTruncated             int
+		wantOmitted               int
+		wantOver                  bool
+	}{
+		{
+			name: "omits blobs of history",
+			contents: []*genai.Content{
+				blob,
+				genai.NewContentFromText("a cat", genai.RoleModel),
+				genai.NewContentFromText("thanks", genai.RoleUser),
+			},
+			maxTokens: 60,
+			want: []*genai.Content{
+				genai.NewContentFromParts([]*genai.Part{
+					genai.NewPartFromText("[image/png data omitted to fit the context window]"),
+					genai.NewPartFromText("describe"),
+				}, genai.RoleUser),
+				genai.NewContentFromText("a cat", genai.RoleModel),
+				genai.NewContentFromText("thanks", genai.RoleUser),
+			},
+			wantOmitted: 1,
+		},
+		{
+			name: "keeps blobs of latest content",
+			contents: []*genai.Content{
+				genai.NewContentFromText("hi", genai.RoleUser),
+				genai.NewContentFromText("hello", genai.RoleModel),
+				blob,
+			},
+			maxTokens: 60,
+			want: []*genai.Content{
+				blob,
+			},
+			wantDropped: 2,
+			wantOver:    true,
+		},
+		{
+			name: "truncates large function responses",
+			contents: []*genai.Content{
+				genai.NewContentFromText("weather?", genai.RoleUser),
+				genai.NewContentFromFunctionCall("get_weather", nil, genai.RoleModel),
+				genai.NewContentFromFunctionResponse("get_weather", map[string]any{"result": strings.Repeat("x", 400)}, genai.RoleUser),
+			},
+			maxTokens:                 60,
+			maxFunctionResponseTokens: 10,
+			want: []*genai.Content{
+				genai.NewContentFromText("weather?", genai.RoleUser),
+				genai.NewContentFromFunctionCall("get_weather", nil, genai.RoleModel),
+				genai.NewContentFromFunctionResponse("get_weather", map[string]any{
+					"result":    `{"result":"` + strings.Repeat("x", 29) + "...",
+					"truncated": true,
+				}, genai.RoleUser),
+			},
+			wantTruncated: 1,
+		},
+		{
+			name: "drops oldest turns",
+			contents: []*genai.Content{
+				genai.NewContentFromText(strings.Repeat("a", 400), genai.RoleUser),
+				genai.NewContentFromText("ok", genai.RoleModel),
+				genai.NewContentFromText("q2", genai.RoleUser),
+				genai.NewContentFromText("ok2", genai.RoleModel),
+				genai.NewContentFromText("q3", genai.RoleUser),
+			},
+			maxTokens: 30,
+			want: []*genai.Content{
+				genai.NewContentFromText("q2", genai.RoleUser),
+				genai.NewContentFromText("ok2", genai.RoleModel),
+				genai.NewContentFromText("q3", genai.RoleUser),
+			},
+			wantDropped: 2,
+		},
+		{
+			name: "keeps function calls paired",
+			contents: []*genai.Content{
+				genai.NewContentFromText(strings.Repeat("a", 400), genai.RoleUser),
+				genai.NewContentFromFunctionCall("get_weather", nil, genai.RoleModel),
+				genai.NewContentFromFunctionResponse("get_weather", map[string]any{"result": "sunny"}, genai.RoleUser),
+				genai.NewContentFromText("sunny", genai.RoleModel),
+				genai.NewContentFromText("q2", genai.RoleUser),
+			},
+			maxTokens: 40,
+			want: []*genai.Content{
+				genai.NewContentFromText("q2", genai.RoleUser),
+			},
+			wantDropped: 4,
+		},
+		{
+			name: "over budget",
+			contents: []*genai.Content{
+				genai.NewContentFromText(strings.Repeat("a", 400), genai.RoleUser),
+			},
+			maxTokens: 30,
+			want: []*genai.Content{
+				genai.NewContentFromText(strings.Repeat("a", 400), genai.RoleUser),
+			},
+			wantOver: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &model.LLMRequest{Contents: tt.contents}
+			tr := &trimmer{
+				maxTokens:                 tt.maxTokens,
+				maxFunctionResponseTokens: tt.maxFunctionResponseTokens,
+				tokens:                    model.EstimateTokens(req),
+				scale:                     1,
+			}
+			if tr.maxFunctionResponseTokens == 0 {
+				tr.maxFunctionResponseTokens = tt.maxTokens
+			}
+			tr.trim(req)
+
+			if diff := cmp.Diff(tt.want, req.Contents); diff != "" {
+				t.Errorf("trim() contents mismatch (-want +got):\n%s", diff)
+			}
+			if got, want := tr.tokens, model.EstimateTokens(req); got != want {
+				t.Errorf("trim() tokens = %d, want %d", got, want)
+			}
+			if got := tr.over(); got != tt.wantOver {
+				t.Errorf("trim() over = %v, want %v", got, tt.wantOver)
+			}
+			if tr.droppedContents != tt.wantDropped || tr.truncatedFunctionResponses != tt.wantTruncated || tr.omittedBlobs != tt.wantOmitted {
+				t.Errorf("trim() dropped, truncated, omitted = %d, %d, %d, want %d, %d, %d",
+					tr.droppedContents, tr.truncatedFunctionResponses, tr.omittedBlobs,
+					tt.wantDropped, tt.wantTruncated, tt.wantOmitted)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sse implements a minimal reader of server-sent event streams, as
+// returned by the streaming endpoints of HTTP model APIs.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"iter"
+)
+
+// maxLineSize bounds the size of a single line of the stream.
+const maxLineSize = 4 << 20
+
+// Event is a single server-sent event.
+type Event struct {
+	// Name is the value of the "event" field, empty if not set.
+	Name string
+	// Data is the value of the "data" fields, joined with newlines.
+	Data []byte
+}
+
+// Events returns an iterator over the events read from r.
+// Comments and fields other than "event" and "data" are ignored.
+func Events(r io.Reader) iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64<<10), maxLineSize)
+
+		var (
+			ev      Event
+			hasData bool
+		)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				// Blank line dispatches the event.
+				if hasData {
+					if !yield(ev, nil) {
+						return
+					}
+				}
+				ev, hasData = Event{}, false
+				continue
+			}
+			if line[0] == ':' {
+				continue // comment
+			}
+			field, value, _ := bytes.Cut(line, []byte(":"))
+			value = bytes.TrimPrefix(value, []byte(" "))
+			switch string(field) {
+			case "event":
+				ev.Name = string(value)
+			case "data":
+				if hasData {
+					ev.Data = append(ev.Data, '\n')
+				}
+				ev.Data = append(ev.Data, value...)
+				hasData = true
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(Event{}, err)
+			return
+		}
+		// Dispatch the last event even if the stream didn't end with a blank line.
+		if hasData {
+			yield(ev, nil)
+		}
+	}
+}
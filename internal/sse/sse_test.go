@@ -0,0 +1,76 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEvents(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Event
+	}{
+		{
+			name:  "data only",
+			input: "data: {\"a\":1}\n\ndata: [DONE]\n\n",
+			want: []Event{
+				{Data: []byte(`{"a":1}`)},
+				{Data: []byte(`[DONE]`)},
+			},
+		},
+		{
+			name:  "named events with comments",
+			input: ": ping\nevent: message_start\ndata: {}\n\nevent: message_stop\ndata: {}\n\n",
+			want: []Event{
+				{Name: "message_start", Data: []byte(`{}`)},
+				{Name: "message_stop", Data: []byte(`{}`)},
+			},
+		},
+		{
+			name:  "multiline data",
+			input: "data: a\ndata: b\n\n",
+			want:  []Event{{Data: []byte("a\nb")}},
+		},
+		{
+			name:  "no trailing blank line",
+			input: "data: last",
+			want:  []Event{{Data: []byte("last")}},
+		},
+		{
+			name:  "crlf line endings",
+			input: "data: x\r\n\r\n",
+			want:  []Event{{Data: []byte("x")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Event
+			for ev, err := range Events(strings.NewReader(tt.input)) {
+				if err != nil {
+					t.Fatalf("Events() error = %v", err)
+				}
+				got = append(got, ev)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Events() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -65,6 +65,7 @@ const (
 	genAiRequestTopP      = "gen_ai.request.top_p"
 	genAiRequestMaxTokens = "gen_ai.request.max_tokens"
 
+	genAiResponseModelName               = "gen_ai.response.model"
 	genAiResponseFinishReason            = "gen_ai.response.finish_reason"
 	genAiResponsePromptTokenCount        = "gen_ai.response.prompt_token_count"
 	genAiResponseCandidatesTokenCount    = "gen_ai.response.candidates_token_count"
@@ -110,9 +111,9 @@ func RegisterTelemetry() {
 // That means that the spans are NOT recording/exporting
 // If the local tracer is not set, we'll set up tracer with all registered span processors.
 func getTracers() []trace.Tracer {
-	if localTracer.tp == nil {
-		RegisterTelemetry()
-	}
+	// RegisterTelemetry is a no-op once the local tracer is set up, and
+	// synchronizes the access to it for concurrent tool calls.
+	RegisterTelemetry()
 	return []trace.Tracer{
 		localTracer.tp.Tracer(systemName),
 		otel.GetTracerProvider().Tracer(systemName),
@@ -222,6 +223,10 @@ func TraceLLMCall(spans []trace.Span, agentCtx agent.InvocationContext, llmReque
 		if llmRequest.Config.MaxOutputTokens != 0 {
 			attributes = append(attributes, attribute.Int(genAiRequestMaxTokens, int(llmRequest.Config.MaxOutputTokens)))
 		}
+		// Set by model.NewFallback and model.NewRouter.
+		if name, ok := event.CustomMetadata[model.ModelNameMetadataKey].(string); ok {
+			attributes = append(attributes, attribute.String(genAiResponseModelName, name))
+		}
 		if event.FinishReason != "" {
 			attributes = append(attributes, attribute.String(genAiResponseFinishReason, string(event.FinishReason)))
 		}
@@ -23,10 +23,13 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/auth"
 	contextinternal "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/credential"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 type internalArtifacts struct {
@@ -50,6 +53,12 @@ func (ia *internalArtifacts) Save(ctx context.Context, name string, data *genai.
 }
 
 func NewToolContext(ctx agent.InvocationContext, functionCallID string, actions *session.EventActions) tool.Context {
+	return NewConfirmedToolContext(ctx, functionCallID, actions, nil)
+}
+
+// NewConfirmedToolContext is like NewToolContext, for a function call run
+// again after the user responded to its confirmation request.
+func NewConfirmedToolContext(ctx agent.InvocationContext, functionCallID string, actions *session.EventActions, confirmation *toolconfirmation.ToolConfirmation) tool.Context {
 	if functionCallID == "" {
 		functionCallID = uuid.NewString()
 	}
@@ -66,6 +75,7 @@ func NewToolContext(ctx agent.InvocationContext, functionCallID string, actions
 		invocationContext: ctx,
 		functionCallID:    functionCallID,
 		eventActions:      actions,
+		toolConfirmation:  confirmation,
 		artifacts: &internalArtifacts{
 			Artifacts:    ctx.Artifacts(),
 			eventActions: actions,
@@ -78,6 +88,7 @@ type toolContext struct {
 	invocationContext agent.InvocationContext
 	functionCallID    string
 	eventActions      *session.EventActions
+	toolConfirmation  *toolconfirmation.ToolConfirmation
 	artifacts         *internalArtifacts
 }
 
@@ -103,3 +114,82 @@ func (c *toolContext) SearchMemory(ctx context.Context, query string) (*memory.S
 	}
 	return c.invocationContext.Memory().Search(ctx, query)
 }
+
+func (c *toolContext) Credential(cfg *auth.Config) (*auth.Credential, error) {
+	if cfg == nil || cfg.Scheme == nil {
+		return nil, fmt.Errorf("auth scheme is required")
+	}
+	if credentials := c.invocationContext.Credentials(); credentials != nil {
+		stored, err := credentials.Load(c, cfg.Key())
+		if err != nil {
+			return nil, fmt.Errorf("failed to load credential: %w", err)
+		}
+		cred, err := auth.Refresh(c, cfg.Scheme, stored)
+		if err != nil {
+			return nil, err
+		}
+		if cred != nil {
+			if cred != stored {
+				if err := credentials.Save(c, cfg.Key(), cred); err != nil {
+					return nil, fmt.Errorf("failed to save credential: %w", err)
+				}
+			}
+			return cred, nil
+		}
+	}
+	cred, err := auth.Resolve(c, cfg)
+	if err != nil || cred == nil {
+		return nil, err
+	}
+	// Tokens of the client credentials flow are reused until they expire.
+	if credentials := c.invocationContext.Credentials(); credentials != nil && cred.Type == auth.CredentialOAuth2 {
+		if err := credentials.Save(c, cfg.Key(), cred); err != nil {
+			return nil, fmt.Errorf("failed to save credential: %w", err)
+		}
+	}
+	return cred, nil
+}
+
+func (c *toolContext) RequestCredential(cfg *auth.Config) error {
+	req, err := auth.NewRequest(cfg)
+	if err != nil {
+		return err
+	}
+	// The request is sent to the client, the client secret isn't. The
+	// credential of the tool is kept on the server, and the secret restored
+	// from it when the client responds.
+	if raw := cfg.RawCredential; raw != nil && raw.OAuth2 != nil && raw.OAuth2.ClientSecret != "" {
+		credentials := c.invocationContext.Credentials()
+		if credentials == nil {
+			return fmt.Errorf("credential service is not set")
+		}
+		if err := credentials.Save(c, credential.RequestKey(req.CredentialKey), raw); err != nil {
+			return fmt.Errorf("failed to save credential: %w", err)
+		}
+	}
+	for _, cred := range []*auth.Credential{req.RawCredential, req.ExchangedCredential} {
+		if cred != nil && cred.OAuth2 != nil {
+			cred.OAuth2.ClientSecret = ""
+		}
+	}
+	if c.eventActions.RequestedAuthConfigs == nil {
+		c.eventActions.RequestedAuthConfigs = make(map[string]*auth.Config)
+	}
+	c.eventActions.RequestedAuthConfigs[c.functionCallID] = req
+	return nil
+}
+
+func (c *toolContext) RequestConfirmation(hint string, payload any) error {
+	if c.eventActions.RequestedToolConfirmations == nil {
+		c.eventActions.RequestedToolConfirmations = make(map[string]*toolconfirmation.ToolConfirmation)
+	}
+	c.eventActions.RequestedToolConfirmations[c.functionCallID] = &toolconfirmation.ToolConfirmation{
+		Hint:    hint,
+		Payload: payload,
+	}
+	return nil
+}
+
+func (c *toolContext) ToolConfirmation() *toolconfirmation.ToolConfirmation {
+	return c.toolConfirmation
+}
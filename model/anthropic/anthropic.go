@@ -0,0 +1,237 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package anthropic implements the [model.LLM] interface for the Anthropic
+// Messages API and compatible endpoints.
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"google.golang.org/adk/internal/httpmodel"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/sse"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+const (
+	// DefaultBaseURL is the base URL of the Anthropic API.
+	DefaultBaseURL = "https://api.anthropic.com"
+	// DefaultAPIVersion is the value of the "anthropic-version" header sent
+	// if ClientConfig.APIVersion is empty.
+	DefaultAPIVersion = "2023-06-01"
+	// DefaultMaxTokens is the maximum number of tokens to generate if the
+	// request doesn't set MaxOutputTokens. The Messages API requires it.
+	DefaultMaxTokens = 4096
+)
+
+// ClientConfig is the configuration of the Messages API client.
+type ClientConfig struct {
+	// BaseURL of the API. The "/v1/messages" path is appended to it.
+	// If empty, DefaultBaseURL is used.
+	BaseURL string
+	// APIKey is sent in the "x-api-key" header.
+	APIKey string
+	// APIVersion is sent in the "anthropic-version" header.
+	// If empty, DefaultAPIVersion is used.
+	APIVersion string
+	// Headers are additional HTTP headers sent with every request,
+	// e.g. "anthropic-beta".
+	Headers http.Header
+	// HTTPClient is used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+type anthropicModel struct {
+	name               string
+	baseURL            string
+	apiKey             string
+	apiVersion         string
+	headers            http.Header
+	httpClient         *http.Client
+	versionHeaderValue string
+}
+
+// NewModel returns [model.LLM], backed by the Anthropic Messages API.
+//
+// The modelName is sent as is in the "model" field of every request
+// (e.g., "claude-sonnet-4-5").
+// A nil cfg is equivalent to an empty ClientConfig.
+func NewModel(ctx context.Context, modelName string, cfg *ClientConfig) (model.LLM, error) {
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	if cfg == nil {
+		cfg = &ClientConfig{}
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = DefaultAPIVersion
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &anthropicModel{
+		name:       modelName,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     cfg.APIKey,
+		apiVersion: apiVersion,
+		headers:    cfg.Headers.Clone(),
+		httpClient: httpClient,
+		versionHeaderValue: fmt.Sprintf("google-adk/%s gl-go/%s", version.Version,
+			strings.TrimPrefix(runtime.Version(), "go")),
+	}, nil
+}
+
+func (m *anthropicModel) Name() string {
+	return m.name
+}
+
+// GenerateContent calls the underlying model.
+func (m *anthropicModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	llminternal.MaybeAppendUserContent(req)
+
+	if stream {
+		return m.generateStream(ctx, req)
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		resp, err := m.generate(ctx, req)
+		yield(resp, err)
+	}
+}
+
+// generate calls the model synchronously.
+func (m *anthropicModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	body, err := m.do(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var resp messagesResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return toLLMResponse(&resp)
+}
+
+// generateStream returns a stream of responses from the model.
+//
+// Text and thinking deltas are yielded as partial responses. Once the message
+// is complete, a final response aggregating all content blocks, the stop
+// reason and usage is yielded.
+func (m *anthropicModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		body, err := m.do(ctx, req, true)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer body.Close()
+
+		aggregator := &streamAggregator{}
+		for ev, err := range sse.Events(body) {
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to read stream: %w", err))
+				return
+			}
+			var se streamEvent
+			if err := json.Unmarshal(ev.Data, &se); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream event %q: %w", ev.Name, err))
+				return
+			}
+			if se.Type == "error" && se.Error != nil {
+				yield(nil, &APIError{Provider: provider, StatusCode: http.StatusOK, Type: se.Error.Type, Message: se.Error.Message})
+				return
+			}
+			partial, err := aggregator.add(&se)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if partial != nil {
+				if !yield(partial, nil) {
+					return // Consumer stopped
+				}
+			}
+			if se.Type == "message_stop" {
+				break
+			}
+		}
+		final, err := aggregator.close()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		yield(final, nil)
+	}
+}
+
+// do sends the request and returns the body of a successful response.
+func (m *anthropicModel) do(ctx context.Context, req *model.LLMRequest, stream bool) (io.ReadCloser, error) {
+	msgReq, err := m.toMessagesRequest(req, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert request: %w", err)
+	}
+	header := http.Header{}
+	header.Set("User-Agent", m.versionHeaderValue)
+	header.Set("anthropic-version", m.apiVersion)
+	if stream {
+		header.Set("Accept", "text/event-stream")
+	}
+	if m.apiKey != "" {
+		header.Set("x-api-key", m.apiKey)
+	}
+	return httpmodel.Post(ctx, m.httpClient, &httpmodel.Request{
+		Provider:      provider,
+		URL:           m.baseURL + "/v1/messages",
+		Body:          msgReq,
+		DefaultHeader: m.headers,
+		Config:        req.Config,
+		Header:        header,
+		ParseError:    parseError,
+	})
+}
+
+// APIError is returned when the API responds with an error.
+type APIError = httpmodel.APIError
+
+const provider = "anthropic"
+
+func parseError(data []byte, apiErr *APIError) bool {
+	var body struct {
+		Error *apiErrorBody `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.Error == nil {
+		return false
+	}
+	apiErr.Type = body.Error.Type
+	apiErr.Message = body.Error.Message
+	return true
+}
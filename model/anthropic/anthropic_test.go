@@ -0,0 +1,328 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// newTestServer returns a server responding with respBody and storing the
+// decoded request body in gotReq.
+func newTestServer(t *testing.T, status int, contentType, respBody string, gotReq *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got, want := r.Header.Get("x-api-key"), "test-key"; got != want {
+			t.Errorf("x-api-key header = %q, want %q", got, want)
+		}
+		if got, want := r.Header.Get("anthropic-version"), DefaultAPIVersion; got != want {
+			t.Errorf("anthropic-version header = %q, want %q", got, want)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotReq != nil {
+			if err := json.Unmarshal(data, gotReq); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, respBody)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestModel(t *testing.T, srv *httptest.Server) model.LLM {
+	t.Helper()
+	m, err := NewModel(t.Context(), "test-model", &ClientConfig{
+		BaseURL: srv.URL,
+		APIKey:  "test-key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestModel_Generate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *model.LLMRequest
+		respBody string
+		wantReq  map[string]any
+		want     *model.LLMResponse
+	}{
+		{
+			name: "text",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromParts([]*genai.Part{
+						genai.NewPartFromText("What is in the image?"),
+						genai.NewPartFromBytes([]byte("png"), "image/png"),
+					}, genai.RoleUser),
+				},
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("Be brief.", genai.RoleUser),
+					Temperature:       new(float32),
+				},
+			},
+			respBody: `{
+				"id": "msg_1",
+				"role": "assistant",
+				"content": [{"type": "text", "text": "A cat."}],
+				"stop_reason": "end_turn",
+				"usage": {"input_tokens": 10, "output_tokens": 3, "cache_read_input_tokens": 5}
+			}`,
+			wantReq: map[string]any{
+				"model":  "test-model",
+				"system": "Be brief.",
+				"messages": []any{
+					map[string]any{"role": "user", "content": []any{
+						map[string]any{"type": "text", "text": "What is in the image?"},
+						map[string]any{"type": "image", "source": map[string]any{"type": "base64", "media_type": "image/png", "data": "cG5n"}},
+					}},
+				},
+				"max_tokens":  float64(DefaultMaxTokens),
+				"temperature": float64(0),
+			},
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText("A cat.", genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount:        15,
+					CandidatesTokenCount:    3,
+					TotalTokenCount:         18,
+					CachedContentTokenCount: 5,
+				},
+			},
+		},
+		{
+			name: "tools",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromText("Weather in Paris?", genai.RoleUser),
+					genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+					genai.NewContentFromFunctionResponse("get_weather", map[string]any{"result": "sunny"}, genai.RoleUser),
+				},
+				Config: &genai.GenerateContentConfig{
+					MaxOutputTokens: 100,
+					Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+						Name:        "get_weather",
+						Description: "Returns the weather.",
+						Parameters: &genai.Schema{
+							Type:       genai.TypeObject,
+							Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+						},
+					}}}},
+					ToolConfig: &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{
+						Mode: genai.FunctionCallingConfigModeAny,
+					}},
+				},
+			},
+			respBody: `{
+				"content": [
+					{"type": "thinking", "thinking": "Check Rome too.", "signature": "sig"},
+					{"type": "tool_use", "id": "toolu_abc", "name": "get_weather", "input": {"city": "Rome"}}
+				],
+				"stop_reason": "tool_use"
+			}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": []any{
+						map[string]any{"type": "text", "text": "Weather in Paris?"},
+					}},
+					map[string]any{"role": "assistant", "content": []any{
+						map[string]any{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": map[string]any{"city": "Paris"}},
+					}},
+					map[string]any{"role": "user", "content": []any{
+						map[string]any{"type": "tool_result", "tool_use_id": "toolu_1", "content": `{"result":"sunny"}`},
+					}},
+				},
+				"max_tokens": float64(100),
+				"tools": []any{map[string]any{
+					"name":        "get_weather",
+					"description": "Returns the weather.",
+					"input_schema": map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"city": map[string]any{"type": "string"}},
+					},
+				}},
+				"tool_choice": map[string]any{"type": "any"},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromParts([]*genai.Part{
+					{Text: "Check Rome too.", Thought: true, ThoughtSignature: []byte("sig")},
+					{FunctionCall: &genai.FunctionCall{
+						ID:   "toolu_abc",
+						Name: "get_weather",
+						Args: map[string]any{"city": "Rome"},
+					}},
+				}, genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "max_tokens",
+			req:  &model.LLMRequest{Contents: genai.Text("Tell me a story.")},
+			respBody: `{
+				"content": [{"type": "text", "text": "Once upon"}],
+				"stop_reason": "max_tokens"
+			}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": []any{
+						map[string]any{"type": "text", "text": "Tell me a story."},
+					}},
+				},
+				"max_tokens": float64(DefaultMaxTokens),
+			},
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText("Once upon", genai.RoleModel),
+				FinishReason: genai.FinishReasonMaxTokens,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReq map[string]any
+			srv := newTestServer(t, http.StatusOK, "application/json", tt.respBody, &gotReq)
+
+			var got []*model.LLMResponse
+			for resp, err := range newTestModel(t, srv).GenerateContent(t.Context(), tt.req, false) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				got = append(got, resp)
+			}
+
+			if diff := cmp.Diff(tt.wantReq, gotReq); diff != "" {
+				t.Errorf("request mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]*model.LLMResponse{tt.want}, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	respBody := `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","role":"assistant","content":[],"usage":{"input_tokens":5,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"check."}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":7}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+	var gotReq map[string]any
+	srv := newTestServer(t, http.StatusOK, "text/event-stream", respBody, &gotReq)
+
+	var got []*model.LLMResponse
+	for resp, err := range newTestModel(t, srv).GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Weather in Paris?")}, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = append(got, resp)
+	}
+
+	if gotReq["stream"] != true {
+		t.Errorf("request stream = %v, want true", gotReq["stream"])
+	}
+
+	want := []*model.LLMResponse{
+		{Content: genai.NewContentFromText("Let me ", genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("check.", genai.RoleModel), Partial: true},
+		{
+			Content: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Let me check."),
+				{FunctionCall: &genai.FunctionCall{ID: "toolu_1", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+			}, genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     5,
+				CandidatesTokenCount: 7,
+				TotalTokenCount:      12,
+			},
+			TurnComplete: true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModel_GenerateError(t *testing.T) {
+	srv := newTestServer(t, 529, "application/json",
+		`{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`, nil)
+
+	for _, stream := range []bool{false, true} {
+		for _, err := range newTestModel(t, srv).GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("hi")}, stream) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GenerateContent(stream=%v) error = %v, want *APIError", stream, err)
+			}
+			want := &APIError{Provider: "anthropic", StatusCode: 529, Type: "overloaded_error", Message: "Overloaded"}
+			if diff := cmp.Diff(want, apiErr); diff != "" {
+				t.Errorf("GenerateContent(stream=%v) error mismatch (-want +got):\n%s", stream, diff)
+			}
+		}
+	}
+}
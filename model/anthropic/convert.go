@@ -0,0 +1,458 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// toMessagesRequest converts the LLMRequest into a Messages API request.
+func (m *anthropicModel) toMessagesRequest(req *model.LLMRequest, stream bool) (*messagesRequest, error) {
+	cfg := req.Config
+	if cfg == nil {
+		cfg = &genai.GenerateContentConfig{}
+	}
+
+	messages, err := toMessages(req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := toTools(cfg.Tools)
+	if err != nil {
+		return nil, err
+	}
+	system, err := toSystem(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mr := &messagesRequest{
+		Model:         m.name,
+		System:        system,
+		Messages:      messages,
+		MaxTokens:     cfg.MaxOutputTokens,
+		Tools:         tools,
+		ToolChoice:    toToolChoice(cfg.ToolConfig),
+		Temperature:   cfg.Temperature,
+		TopP:          cfg.TopP,
+		StopSequences: cfg.StopSequences,
+		Stream:        stream,
+	}
+	if mr.MaxTokens == 0 {
+		mr.MaxTokens = DefaultMaxTokens
+	}
+	if cfg.TopK != nil {
+		topK := int32(*cfg.TopK)
+		mr.TopK = &topK
+	}
+	if tc := cfg.ThinkingConfig; tc != nil && tc.ThinkingBudget != nil && *tc.ThinkingBudget > 0 {
+		mr.Thinking = &thinking{Type: "enabled", BudgetTokens: *tc.ThinkingBudget}
+	}
+	return mr, nil
+}
+
+// toSystem returns the system prompt. The Messages API has no native support
+// for constrained output, so the response schema, if any, is described in
+// the system prompt.
+func toSystem(cfg *genai.GenerateContentConfig) (string, error) {
+	var texts []string
+	if cfg.SystemInstruction != nil {
+		for _, p := range cfg.SystemInstruction.Parts {
+			if p != nil && p.Text != "" {
+				texts = append(texts, p.Text)
+			}
+		}
+	}
+	if schema := converters.ResponseJSONSchema(cfg); schema != nil {
+		data, err := json.Marshal(schema)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal response schema: %w", err)
+		}
+		texts = append(texts, "Respond only with a JSON value, without any other text or formatting, that matches the following JSON schema:\n"+string(data))
+	}
+	return strings.Join(texts, "\n\n"), nil
+}
+
+// toMessages converts the conversation contents into messages.
+// Consecutive contents of the same role are merged into one message, since
+// the Messages API requires roles to alternate.
+func toMessages(contents []*genai.Content) ([]*message, error) {
+	var messages []*message
+	ids := converters.NewFunctionCallIDs("toolu_")
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		role := "user"
+		if c.Role == genai.RoleModel {
+			role = "assistant"
+		}
+		blocks, err := toBlocks(c, ids)
+		if err != nil {
+			return nil, err
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(messages); n > 0 && messages[n-1].Role == role {
+			// Tool results must come first in the merged message too, e.g.
+			// after the context text of another agent.
+			merged := append(messages[n-1].Content, blocks...)
+			slices.SortStableFunc(merged, func(a, b *block) int {
+				return cmp.Compare(resultOrder(a), resultOrder(b))
+			})
+			messages[n-1].Content = merged
+			continue
+		}
+		messages = append(messages, &message{Role: role, Content: blocks})
+	}
+	return messages, nil
+}
+
+// resultOrder sorts tool results before the other blocks.
+func resultOrder(b *block) int {
+	if b.Type == "tool_result" {
+		return 0
+	}
+	return 1
+}
+
+func toBlocks(c *genai.Content, ids *converters.FunctionCallIDs) ([]*block, error) {
+	var (
+		results []*block
+		blocks  []*block
+	)
+	for _, p := range c.Parts {
+		switch {
+		case p == nil:
+		case p.Thought:
+			// Thinking blocks can only be sent back with their signature.
+			switch {
+			case len(p.ThoughtSignature) == 0:
+			case p.Text == "":
+				blocks = append(blocks, &block{Type: "redacted_thinking", Data: string(p.ThoughtSignature)})
+			default:
+				blocks = append(blocks, &block{Type: "thinking", Thinking: p.Text, Signature: string(p.ThoughtSignature)})
+			}
+		case p.FunctionCall != nil:
+			input := p.FunctionCall.Args
+			if input == nil {
+				input = map[string]any{}
+			}
+			blocks = append(blocks, &block{
+				Type:  "tool_use",
+				ID:    ids.ForCall(p.FunctionCall),
+				Name:  p.FunctionCall.Name,
+				Input: input,
+			})
+		case p.FunctionResponse != nil:
+			// Tool results must come first in the user message following the
+			// tool use.
+			resp, err := json.Marshal(p.FunctionResponse.Response)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response of function %q: %w", p.FunctionResponse.Name, err)
+			}
+			_, hasError := p.FunctionResponse.Response["error"]
+			results = append(results, &block{
+				Type:      "tool_result",
+				ToolUseID: ids.ForResponse(p.FunctionResponse),
+				Content:   string(resp),
+				IsError:   hasError && len(p.FunctionResponse.Response) == 1,
+			})
+		case p.Text != "":
+			blocks = append(blocks, &block{Type: "text", Text: p.Text})
+		case p.InlineData != nil:
+			if !strings.HasPrefix(p.InlineData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported inline data MIME type %q", p.InlineData.MIMEType)
+			}
+			blocks = append(blocks, &block{Type: "image", Source: &imageSource{
+				Type:      "base64",
+				MediaType: p.InlineData.MIMEType,
+				Data:      base64.StdEncoding.EncodeToString(p.InlineData.Data),
+			}})
+		case p.FileData != nil:
+			if !strings.HasPrefix(p.FileData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported file data MIME type %q", p.FileData.MIMEType)
+			}
+			blocks = append(blocks, &block{Type: "image", Source: &imageSource{Type: "url", URL: p.FileData.FileURI}})
+		default:
+			return nil, fmt.Errorf("unsupported part: %+v", p)
+		}
+	}
+	return append(results, blocks...), nil
+}
+
+func toTools(tools []*genai.Tool) ([]*tool, error) {
+	var result []*tool
+	for _, t := range tools {
+		if t == nil {
+			continue
+		}
+		if len(t.FunctionDeclarations) == 0 {
+			return nil, fmt.Errorf("unsupported tool: only function declarations are supported by the Messages API")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			result = append(result, &tool{
+				Name:        decl.Name,
+				Description: decl.Description,
+				InputSchema: converters.FunctionParameters(decl),
+			})
+		}
+	}
+	return result, nil
+}
+
+func toToolChoice(cfg *genai.ToolConfig) *toolChoice {
+	if cfg == nil || cfg.FunctionCallingConfig == nil {
+		return nil
+	}
+	fcc := cfg.FunctionCallingConfig
+	switch fcc.Mode {
+	case genai.FunctionCallingConfigModeAuto:
+		return &toolChoice{Type: "auto"}
+	case genai.FunctionCallingConfigModeNone:
+		return &toolChoice{Type: "none"}
+	case genai.FunctionCallingConfigModeAny:
+		if len(fcc.AllowedFunctionNames) == 1 {
+			return &toolChoice{Type: "tool", Name: fcc.AllowedFunctionNames[0]}
+		}
+		return &toolChoice{Type: "any"}
+	default:
+		return nil
+	}
+}
+
+// toLLMResponse converts a complete Messages API response.
+func toLLMResponse(resp *messagesResponse) (*model.LLMResponse, error) {
+	content, err := toContent(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	return newLLMResponse(content, resp.StopReason, resp.Usage), nil
+}
+
+func newLLMResponse(content *genai.Content, stopReason string, u *usage) *model.LLMResponse {
+	resp := &model.LLMResponse{
+		Content:       content,
+		FinishReason:  toFinishReason(stopReason),
+		UsageMetadata: toUsageMetadata(u),
+	}
+	if content == nil {
+		resp.ErrorCode = string(resp.FinishReason)
+		resp.ErrorMessage = "model returned an empty response"
+		if resp.ErrorCode == "" {
+			resp.ErrorCode = "UNKNOWN_ERROR"
+		}
+	}
+	return resp
+}
+
+// toContent converts the response content blocks.
+// It returns nil if there are no blocks.
+func toContent(blocks []*block) (*genai.Content, error) {
+	var parts []*genai.Part
+	for _, b := range blocks {
+		switch b.Type {
+		case "text":
+			parts = append(parts, genai.NewPartFromText(b.Text))
+		case "thinking":
+			parts = append(parts, &genai.Part{Text: b.Thinking, Thought: true, ThoughtSignature: []byte(b.Signature)})
+		case "redacted_thinking":
+			parts = append(parts, &genai.Part{Thought: true, ThoughtSignature: []byte(b.Data)})
+		case "tool_use":
+			args, err := toArgs(b)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+				ID:   b.ID,
+				Name: b.Name,
+				Args: args,
+			}})
+		}
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	return genai.NewContentFromParts(parts, genai.RoleModel), nil
+}
+
+// toArgs returns the input of the tool_use block as function call arguments.
+func toArgs(b *block) (map[string]any, error) {
+	var data []byte
+	switch input := b.Input.(type) {
+	case nil:
+		return map[string]any{}, nil
+	case json.RawMessage:
+		data = input
+	default:
+		var err error
+		if data, err = json.Marshal(input); err != nil {
+			return nil, err
+		}
+	}
+	args := map[string]any{}
+	if strings.TrimSpace(string(data)) == "" {
+		return args, nil
+	}
+	if err := json.Unmarshal(data, &args); err != nil {
+		return nil, fmt.Errorf("failed to parse input of tool use %q: %w", b.Name, err)
+	}
+	return args, nil
+}
+
+func toFinishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "end_turn", "stop_sequence", "tool_use", "pause_turn":
+		return genai.FinishReasonStop
+	case "max_tokens":
+		return genai.FinishReasonMaxTokens
+	case "refusal":
+		return genai.FinishReasonSafety
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+func toUsageMetadata(u *usage) *genai.GenerateContentResponseUsageMetadata {
+	if u == nil {
+		return nil
+	}
+	// Input tokens don't include the tokens read from or written to the cache.
+	prompt := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:        prompt,
+		CandidatesTokenCount:    u.OutputTokens,
+		TotalTokenCount:         prompt + u.OutputTokens,
+		CachedContentTokenCount: u.CacheReadInputTokens,
+	}
+}
+
+// streamAggregator accumulates stream events into the final response.
+type streamAggregator struct {
+	blocks      []*block
+	partialJSON map[int]*strings.Builder
+	stopReason  string
+	usage       usage
+	hasUsage    bool
+}
+
+// add processes a stream event and returns the partial response to yield,
+// if any.
+func (a *streamAggregator) add(ev *streamEvent) (*model.LLMResponse, error) {
+	switch ev.Type {
+	case "message_start":
+		if ev.Message != nil && ev.Message.Usage != nil {
+			a.usage = *ev.Message.Usage
+			a.hasUsage = true
+		}
+	case "content_block_start":
+		if ev.ContentBlock == nil {
+			return nil, fmt.Errorf("missing content block in %q event", ev.Type)
+		}
+		for len(a.blocks) <= ev.Index {
+			a.blocks = append(a.blocks, nil)
+		}
+		b := *ev.ContentBlock
+		if b.Type == "tool_use" {
+			// The input is streamed as partial JSON.
+			b.Input = nil
+		}
+		a.blocks[ev.Index] = &b
+		if b.Type == "text" && b.Text != "" {
+			return newPartial(&genai.Part{Text: b.Text}), nil
+		}
+	case "content_block_delta":
+		if ev.Index >= len(a.blocks) || a.blocks[ev.Index] == nil || ev.Delta == nil {
+			return nil, fmt.Errorf("unexpected %q event for content block %d", ev.Type, ev.Index)
+		}
+		b := a.blocks[ev.Index]
+		switch ev.Delta.Type {
+		case "text_delta":
+			b.Text += ev.Delta.Text
+			return newPartial(&genai.Part{Text: ev.Delta.Text}), nil
+		case "thinking_delta":
+			b.Thinking += ev.Delta.Thinking
+			return newPartial(&genai.Part{Text: ev.Delta.Thinking, Thought: true}), nil
+		case "signature_delta":
+			b.Signature += ev.Delta.Signature
+		case "input_json_delta":
+			if a.partialJSON == nil {
+				a.partialJSON = make(map[int]*strings.Builder)
+			}
+			if a.partialJSON[ev.Index] == nil {
+				a.partialJSON[ev.Index] = &strings.Builder{}
+			}
+			a.partialJSON[ev.Index].WriteString(ev.Delta.PartialJSON)
+		}
+	case "message_delta":
+		if ev.Delta != nil && ev.Delta.StopReason != "" {
+			a.stopReason = ev.Delta.StopReason
+		}
+		if ev.Usage != nil {
+			// Usage in message_delta events is cumulative.
+			a.usage.OutputTokens = ev.Usage.OutputTokens
+			if ev.Usage.InputTokens > 0 {
+				a.usage.InputTokens = ev.Usage.InputTokens
+			}
+			a.hasUsage = true
+		}
+	}
+	return nil, nil
+}
+
+func newPartial(p *genai.Part) *model.LLMResponse {
+	return &model.LLMResponse{
+		Content: genai.NewContentFromParts([]*genai.Part{p}, genai.RoleModel),
+		Partial: true,
+	}
+}
+
+// close returns the aggregated final response.
+func (a *streamAggregator) close() (*model.LLMResponse, error) {
+	var blocks []*block
+	for i, b := range a.blocks {
+		if b == nil {
+			continue
+		}
+		if sb, ok := a.partialJSON[i]; ok {
+			b.Input = json.RawMessage(sb.String())
+		}
+		blocks = append(blocks, b)
+	}
+	content, err := toContent(blocks)
+	if err != nil {
+		return nil, err
+	}
+	var u *usage
+	if a.hasUsage {
+		u = &a.usage
+	}
+	resp := newLLMResponse(content, a.stopReason, u)
+	resp.TurnComplete = true
+	return resp, nil
+}
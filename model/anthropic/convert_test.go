@@ -0,0 +1,75 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+)
+
+func TestToMessages(t *testing.T) {
+	call := &genai.FunctionCall{ID: "toolu_1", Name: "get_weather", Args: map[string]any{"city": "Paris"}}
+	response := &genai.FunctionResponse{ID: "toolu_1", Name: "get_weather", Response: map[string]any{"temp": 20}}
+
+	tests := []struct {
+		name     string
+		contents []*genai.Content
+		want     []*message
+	}{
+		{
+			name: "merges consecutive contents of the same role",
+			contents: []*genai.Content{
+				genai.NewContentFromText("hi", genai.RoleUser),
+				genai.NewContentFromText("there", genai.RoleUser),
+			},
+			want: []*message{{Role: "user", Content: []*block{
+				{Type: "text", Text: "hi"},
+				{Type: "text", Text: "there"},
+			}}},
+		},
+		{
+			name: "tool results first after context text",
+			contents: []*genai.Content{
+				genai.NewContentFromText("What's the weather?", genai.RoleUser),
+				{Role: genai.RoleModel, Parts: []*genai.Part{{FunctionCall: call}}},
+				// Context from another agent, as converted for multi-agent
+				// sessions, right before the function response.
+				genai.NewContentFromText("For context:", genai.RoleUser),
+				{Role: genai.RoleUser, Parts: []*genai.Part{{FunctionResponse: response}}},
+			},
+			want: []*message{
+				{Role: "user", Content: []*block{{Type: "text", Text: "What's the weather?"}}},
+				{Role: "assistant", Content: []*block{{Type: "tool_use", ID: "toolu_1", Name: "get_weather", Input: map[string]any{"city": "Paris"}}}},
+				{Role: "user", Content: []*block{
+					{Type: "tool_result", ToolUseID: "toolu_1", Content: `{"temp":20}`},
+					{Type: "text", Text: "For context:"},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toMessages(tt.contents)
+			if err != nil {
+				t.Fatalf("toMessages() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("toMessages() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
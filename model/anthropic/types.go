@@ -0,0 +1,151 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package anthropic
+
+// Wire types of the Messages API.
+// Only the subset of fields used by the adapter is declared.
+
+type messagesRequest struct {
+	Model         string      `json:"model"`
+	System        string      `json:"system,omitempty"`
+	Messages      []*message  `json:"messages"`
+	MaxTokens     int32       `json:"max_tokens"`
+	Tools         []*tool     `json:"tools,omitempty"`
+	ToolChoice    *toolChoice `json:"tool_choice,omitempty"`
+	Temperature   *float32    `json:"temperature,omitempty"`
+	TopP          *float32    `json:"top_p,omitempty"`
+	TopK          *int32      `json:"top_k,omitempty"`
+	StopSequences []string    `json:"stop_sequences,omitempty"`
+	Thinking      *thinking   `json:"thinking,omitempty"`
+	Stream        bool        `json:"stream,omitempty"`
+}
+
+type message struct {
+	Role    string   `json:"role"`
+	Content []*block `json:"content"`
+}
+
+// block is a content block of a message. The fields set depend on the Type:
+// "text", "image", "tool_use", "tool_result", "thinking" or
+// "redacted_thinking".
+type block struct {
+	Type string `json:"type"`
+
+	// Text is set for "text" blocks.
+	Text string `json:"text,omitempty"`
+
+	// Source is set for "image" blocks.
+	Source *imageSource `json:"source,omitempty"`
+
+	// ID, Name and Input are set for "tool_use" blocks.
+	ID    string `json:"id,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Input any    `json:"input,omitempty"`
+
+	// ToolUseID, Content and IsError are set for "tool_result" blocks.
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+
+	// Thinking and Signature are set for "thinking" blocks.
+	Thinking  string `json:"thinking,omitempty"`
+	Signature string `json:"signature,omitempty"`
+
+	// Data is set for "redacted_thinking" blocks.
+	Data string `json:"data,omitempty"`
+}
+
+type imageSource struct {
+	// Type is either "base64" or "url".
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type toolChoice struct {
+	// Type is one of "auto", "any", "tool" or "none".
+	Type string `json:"type"`
+	// Name is set if Type is "tool".
+	Name string `json:"name,omitempty"`
+}
+
+type thinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int32  `json:"budget_tokens"`
+}
+
+type messagesResponse struct {
+	ID         string   `json:"id"`
+	Model      string   `json:"model"`
+	Role       string   `json:"role"`
+	Content    []*block `json:"content"`
+	StopReason string   `json:"stop_reason"`
+	Usage      *usage   `json:"usage"`
+}
+
+type usage struct {
+	InputTokens              int32 `json:"input_tokens"`
+	OutputTokens             int32 `json:"output_tokens"`
+	CacheCreationInputTokens int32 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int32 `json:"cache_read_input_tokens"`
+}
+
+// streamEvent is the payload of a server-sent event of a streaming response.
+type streamEvent struct {
+	// Type is the same as the name of the server-sent event, e.g.
+	// "message_start" or "content_block_delta".
+	Type string `json:"type"`
+
+	// Message is set for "message_start" events.
+	Message *messagesResponse `json:"message"`
+
+	// Index and ContentBlock are set for "content_block_*" events.
+	Index        int    `json:"index"`
+	ContentBlock *block `json:"content_block"`
+
+	// Delta is set for "content_block_delta" and "message_delta" events.
+	Delta *streamDelta `json:"delta"`
+
+	// Usage is set for "message_delta" events.
+	Usage *usage `json:"usage"`
+
+	// Error is set for "error" events.
+	Error *apiErrorBody `json:"error"`
+}
+
+type streamDelta struct {
+	// Type is one of "text_delta", "input_json_delta", "thinking_delta" or
+	// "signature_delta" for "content_block_delta" events.
+	Type        string `json:"type"`
+	Text        string `json:"text"`
+	PartialJSON string `json:"partial_json"`
+	Thinking    string `json:"thinking"`
+	Signature   string `json:"signature"`
+
+	// StopReason is set for "message_delta" events.
+	StopReason string `json:"stop_reason"`
+}
+
+type apiErrorBody struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
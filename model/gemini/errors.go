@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// Sentinel errors classifying the failures of Gemini model calls.
+// They can be matched with [errors.Is], e.g. in an OnModelErrorCallback:
+//
+//	if errors.Is(err, gemini.ErrRateLimited) {
+//		...
+//	}
+var (
+	// ErrRateLimited is returned when the request was rejected because too
+	// many requests were sent (HTTP 429).
+	ErrRateLimited = errors.New("rate limited")
+	// ErrQuotaExceeded is returned when the request was rejected because the
+	// project quota was exhausted (HTTP 429 with quota failure details).
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrSafetyBlocked is returned when the prompt was blocked by safety
+	// filters, so the model returned no candidates.
+	ErrSafetyBlocked = errors.New("safety blocked")
+	// ErrInvalidArgument is returned when the request was rejected as
+	// malformed (HTTP 400).
+	ErrInvalidArgument = errors.New("invalid argument")
+	// ErrUnavailable is returned when the request failed because of a
+	// server error (HTTP 5xx).
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// APIError is returned when the Gemini API responds with an error.
+//
+// It wraps the [genai.APIError] returned by the client, so both
+// errors.As(err, &genai.APIError{}) and errors.Is with the sentinel error
+// classifying the failure work.
+type APIError struct {
+	genai.APIError
+	// RetryDelay is the delay before retrying requested by the server,
+	// zero if not set.
+	RetryDelay time.Duration
+
+	kind error
+}
+
+func (e *APIError) Error() string {
+	return e.APIError.Error()
+}
+
+func (e *APIError) Unwrap() []error {
+	if e.kind == nil {
+		return []error{e.APIError}
+	}
+	return []error{e.kind, e.APIError}
+}
+
+// HTTPStatusCode returns the HTTP status code of the response. It allows
+// [model.IsRetryable] to classify the error.
+func (e *APIError) HTTPStatusCode() int {
+	return e.Code
+}
+
+const (
+	retryInfoType    = "type.googleapis.com/google.rpc.RetryInfo"
+	quotaFailureType = "type.googleapis.com/google.rpc.QuotaFailure"
+)
+
+// classifyError converts genai.APIError into APIError. Other errors are
+// returned as is.
+func classifyError(err error) error {
+	var apiErr genai.APIError
+	if err == nil || !errors.As(err, &apiErr) {
+		return err
+	}
+	var already *APIError
+	if errors.As(err, &already) {
+		return err
+	}
+
+	e := &APIError{APIError: apiErr}
+	quotaFailure := false
+	for _, d := range apiErr.Details {
+		switch d["@type"] {
+		case retryInfoType:
+			// The delay is a google.protobuf.Duration in its JSON form, e.g. "30s".
+			if s, ok := d["retryDelay"].(string); ok {
+				if delay, err := time.ParseDuration(s); err == nil {
+					e.RetryDelay = delay
+				}
+			}
+		case quotaFailureType:
+			quotaFailure = true
+		}
+	}
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests || apiErr.Status == "RESOURCE_EXHAUSTED":
+		if quotaFailure {
+			e.kind = ErrQuotaExceeded
+		} else {
+			e.kind = ErrRateLimited
+		}
+	case apiErr.Code == http.StatusBadRequest || apiErr.Status == "INVALID_ARGUMENT" || apiErr.Status == "FAILED_PRECONDITION":
+		e.kind = ErrInvalidArgument
+	case apiErr.Code >= 500:
+		e.kind = ErrUnavailable
+	}
+	return e
+}
+
+// blockedError returns ErrSafetyBlocked if the prompt of the response was
+// blocked, nil otherwise.
+func blockedError(resp *genai.GenerateContentResponse) error {
+	if len(resp.Candidates) > 0 || resp.PromptFeedback == nil || resp.PromptFeedback.BlockReason == "" {
+		return nil
+	}
+	msg := resp.PromptFeedback.BlockReasonMessage
+	if msg == "" {
+		msg = strings.ToLower(strings.ReplaceAll(string(resp.PromptFeedback.BlockReason), "_", " "))
+	}
+	return fmt.Errorf("%w: prompt blocked (%s): %s", ErrSafetyBlocked, resp.PromptFeedback.BlockReason, msg)
+}
@@ -36,6 +36,7 @@ type geminiModel struct {
 	client             *genai.Client
 	name               string
 	versionHeaderValue string
+	retrier            *retrier
 }
 
 // NewModel returns [model.LLM], backed by the Gemini API.
@@ -44,8 +45,21 @@ type geminiModel struct {
 // [genai.Client]. The modelName specifies which Gemini model to target
 // (e.g., "gemini-2.5-flash").
 //
+// Failed calls are not retried. Use NewModelWithRetry to retry transient
+// failures.
+//
 // An error is returned if the [genai.Client] fails to initialize.
 func NewModel(ctx context.Context, modelName string, cfg *genai.ClientConfig) (model.LLM, error) {
+	return NewModelWithRetry(ctx, modelName, cfg, RetryConfig{MaxAttempts: 1})
+}
+
+// NewModelWithRetry is like NewModel, but retries failed calls according to
+// the given RetryConfig. The zero RetryConfig retries transient failures with
+// the default backoff.
+//
+// Errors are only returned, and seen by the OnModelError callbacks of the
+// agent, after the retries are exhausted.
+func NewModelWithRetry(ctx context.Context, modelName string, cfg *genai.ClientConfig, retry RetryConfig) (model.LLM, error) {
 	client, err := genai.NewClient(ctx, cfg)
 	if err != nil {
 		return nil, err
@@ -59,6 +73,7 @@ func NewModel(ctx context.Context, modelName string, cfg *genai.ClientConfig) (m
 		name:               modelName,
 		client:             client,
 		versionHeaderValue: headerValue,
+		retrier:            newRetrier(retry),
 	}, nil
 }
 
@@ -68,7 +83,7 @@ func (m *geminiModel) Name() string {
 
 // GenerateContent calls the underlying model.
 func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
-	m.maybeAppendUserContent(req)
+	llminternal.MaybeAppendUserContent(req)
 	if req.Config == nil {
 		req.Config = &genai.GenerateContentConfig{}
 	}
@@ -90,6 +105,35 @@ func (m *geminiModel) GenerateContent(ctx context.Context, req *model.LLMRequest
 	}
 }
 
+// CountTokens implements [model.TokenCounter] using the CountTokens endpoint.
+//
+// The Gemini API doesn't accept the system instruction and tool declarations
+// in a count request, so their share is estimated with [model.EstimateTokens]
+// unless the client uses the Vertex AI backend.
+func (m *geminiModel) CountTokens(ctx context.Context, req *model.LLMRequest) (int, error) {
+	cfg := &genai.CountTokensConfig{}
+	estimated := 0
+	if req.Config != nil {
+		if m.client.ClientConfig().Backend == genai.BackendVertexAI {
+			cfg.SystemInstruction = req.Config.SystemInstruction
+			cfg.Tools = req.Config.Tools
+		} else {
+			estimated = model.EstimateTokens(&model.LLMRequest{Config: &genai.GenerateContentConfig{
+				SystemInstruction: req.Config.SystemInstruction,
+				Tools:             req.Config.Tools,
+			}})
+		}
+	}
+	if len(req.Contents) == 0 && cfg.SystemInstruction == nil {
+		return estimated, nil
+	}
+	resp, err := m.client.Models.CountTokens(ctx, m.name, req.Contents, cfg)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", classifyError(err))
+	}
+	return int(resp.TotalTokens) + estimated, nil
+}
+
 // addHeaders sets the x-goog-api-client and user-agent headers
 func (m *geminiModel) addHeaders(headers http.Header) {
 	headers.Set("x-goog-api-client", m.versionHeaderValue)
@@ -97,10 +141,23 @@ func (m *geminiModel) addHeaders(headers http.Header) {
 }
 
 // generate calls the model synchronously returning result from the first candidate.
+// Failed calls are retried according to the retry config.
 func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := m.generateOnce(ctx, req)
+		if err == nil || !m.retrier.wait(ctx, attempt, err) {
+			return resp, err
+		}
+	}
+}
+
+func (m *geminiModel) generateOnce(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
 	resp, err := m.client.Models.GenerateContent(ctx, m.name, req.Contents, req.Config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call model: %w", err)
+		return nil, fmt.Errorf("failed to call model: %w", classifyError(err))
+	}
+	if err := blockedError(resp); err != nil {
+		return nil, err
 	}
 	if len(resp.Candidates) == 0 {
 		// shouldn't happen?
@@ -110,34 +167,63 @@ func (m *geminiModel) generate(ctx context.Context, req *model.LLMRequest) (*mod
 }
 
 // generateStream returns a stream of responses from the model.
+//
+// Failed calls are retried according to the retry config, unless a
+// non-partial response was already yielded. Partial responses of a retried
+// call are not yielded if partial responses of a previous attempt were, so
+// consumers never receive the same text twice; the final aggregated response
+// still contains the complete text.
 func (m *geminiModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
-	aggregator := llminternal.NewStreamingResponseAggregator()
-
 	return func(yield func(*model.LLMResponse, error) bool) {
-		for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, req.Contents, req.Config) {
-			if err != nil {
-				yield(nil, err)
-				return
+		yieldedPartial := false
+		for attempt := 1; ; attempt++ {
+			aggregator := llminternal.NewStreamingResponseAggregator()
+			skipPartial := yieldedPartial
+			committed, stopped := false, false
+			var streamErr error
+
+		stream:
+			for resp, err := range m.client.Models.GenerateContentStream(ctx, m.name, req.Contents, req.Config) {
+				if err != nil {
+					streamErr = classifyError(err)
+					break
+				}
+				if err := blockedError(resp); err != nil {
+					streamErr = err
+					break
+				}
+				for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
+					if err != nil {
+						streamErr = err
+						break stream
+					}
+					if llmResponse.Partial {
+						if skipPartial {
+							continue
+						}
+						yieldedPartial = true
+					} else {
+						committed = true
+					}
+					if !yield(llmResponse, nil) {
+						stopped = true
+						break stream
+					}
+				}
 			}
-			for llmResponse, err := range aggregator.ProcessResponse(ctx, resp) {
-				if !yield(llmResponse, err) {
-					return // Consumer stopped
+			if stopped {
+				return // Consumer stopped
+			}
+			if streamErr == nil {
+				if closeResult := aggregator.Close(); closeResult != nil {
+					yield(closeResult, nil)
 				}
+				return
+			}
+			if committed || !m.retrier.wait(ctx, attempt, streamErr) {
+				yield(nil, streamErr)
+				return
 			}
 		}
-		if closeResult := aggregator.Close(); closeResult != nil {
-			yield(closeResult, nil)
-		}
-	}
-}
-
-// maybeAppendUserContent appends a user content, so that model can continue to output.
-func (m *geminiModel) maybeAppendUserContent(req *model.LLMRequest) {
-	if len(req.Contents) == 0 {
-		req.Contents = append(req.Contents, genai.NewContentFromText("Handle the requests as specified in the System Instruction.", "user"))
-	}
-
-	if last := req.Contents[len(req.Contents)-1]; last != nil && last.Role != "user" {
-		req.Contents = append(req.Contents, genai.NewContentFromText("Continue processing previous requests as instructed. Exit or provide a summary if no more outputs are needed.", "user"))
 	}
 }
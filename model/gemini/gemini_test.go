@@ -260,3 +260,26 @@ func (h *headerInterceptor) RoundTrip(req *http.Request) (*http.Response, error)
 	}
 	return h.base.RoundTrip(req)
 }
+
+func TestModel_CountTokens(t *testing.T) {
+	m, calls := newRetryTestModel(t, []testResponse{{http.StatusOK, `{"totalTokens": 12}`}})
+
+	req := &model.LLMRequest{
+		Contents: genai.Text("Capital of France?"),
+		Config: &genai.GenerateContentConfig{
+			SystemInstruction: genai.NewContentFromText("Be brief.", genai.RoleUser),
+		},
+	}
+	got, err := m.(model.TokenCounter).CountTokens(t.Context(), req)
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	// The Gemini API counts the contents, the system instruction is estimated.
+	want := 12 + model.EstimateTokens(&model.LLMRequest{Config: req.Config})
+	if got != want {
+		t.Errorf("CountTokens() = %d, want %d", got, want)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryConfig configures retries of failed model calls.
+// The zero value retries transient errors up to 3 times in total with
+// exponential backoff and jitter.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of calls to the model for a request,
+	// including the first one. If 0, 3 is used. Set it to 1 to disable retries.
+	MaxAttempts int
+	// InitialDelay is the delay before the first retry. If 0, 1s is used.
+	InitialDelay time.Duration
+	// MaxDelay is the maximum delay between two attempts. If 0, 30s is used.
+	// If the server requests a longer delay, the error is returned without
+	// retrying.
+	MaxDelay time.Duration
+	// Multiplier of the delay after every attempt. If 0, 2 is used.
+	Multiplier float64
+	// Jitter is the fraction of the delay randomly added or subtracted, to
+	// avoid synchronized retries of many clients. If 0, 0.2 is used.
+	// A negative value disables jitter.
+	Jitter float64
+	// ShouldRetry reports whether the request should be retried after err.
+	// If nil, requests failed with ErrRateLimited or ErrUnavailable, or with
+	// ErrQuotaExceeded and a retry delay requested by the server, are retried.
+	ShouldRetry func(err error) bool
+}
+
+// retrier decides whether and when to retry failed calls.
+type retrier struct {
+	maxAttempts  int
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	multiplier   float64
+	jitter       float64
+	shouldRetry  func(error) bool
+}
+
+func newRetrier(cfg RetryConfig) *retrier {
+	r := &retrier{
+		maxAttempts:  cfg.MaxAttempts,
+		initialDelay: cfg.InitialDelay,
+		maxDelay:     cfg.MaxDelay,
+		multiplier:   cfg.Multiplier,
+		jitter:       cfg.Jitter,
+		shouldRetry:  cfg.ShouldRetry,
+	}
+	if r.maxAttempts <= 0 {
+		r.maxAttempts = 3
+	}
+	if r.initialDelay <= 0 {
+		r.initialDelay = time.Second
+	}
+	if r.maxDelay <= 0 {
+		r.maxDelay = 30 * time.Second
+	}
+	if r.multiplier <= 0 {
+		r.multiplier = 2
+	}
+	if r.jitter == 0 {
+		r.jitter = 0.2
+	}
+	if r.shouldRetry == nil {
+		r.shouldRetry = isRetryable
+	}
+	return r
+}
+
+// wait blocks until the next attempt should be made after the given attempt
+// (starting at 1) failed with err. It returns false if the call must not be
+// retried.
+func (r *retrier) wait(ctx context.Context, attempt int, err error) bool {
+	if attempt >= r.maxAttempts || ctx.Err() != nil || !r.shouldRetry(err) {
+		return false
+	}
+	delay, ok := r.delay(attempt, err)
+	if !ok {
+		return false
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// delay returns the delay before the attempt following the given one.
+func (r *retrier) delay(attempt int, err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryDelay > 0 {
+		// Honor the delay requested by the server.
+		return apiErr.RetryDelay, apiErr.RetryDelay <= r.maxDelay
+	}
+	delay := float64(r.initialDelay) * math.Pow(r.multiplier, float64(attempt-1))
+	if r.jitter > 0 {
+		delay *= 1 + r.jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(min(delay, float64(r.maxDelay))), true
+}
+
+func isRetryable(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.Is(err, ErrQuotaExceeded) && errors.As(err, &apiErr) && apiErr.RetryDelay > 0
+}
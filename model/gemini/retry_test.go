@@ -0,0 +1,233 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gemini
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+const okBody = `{"candidates": [{"content": {"role": "model", "parts": [{"text": "Paris"}]}, "finishReason": "STOP"}]}`
+
+// newRetryTestModel returns a model calling a server that responds to the
+// n-th request with responses[n], or with the last response once exhausted.
+func newRetryTestModel(t *testing.T, responses []testResponse) (model.LLM, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1)) - 1
+		resp := responses[min(n, len(responses)-1)]
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(resp.status)
+		io.WriteString(w, resp.body)
+	}))
+	t.Cleanup(srv.Close)
+
+	m, err := NewModelWithRetry(t.Context(), "gemini-2.0-flash", &genai.ClientConfig{
+		APIKey:      "fakekey",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	}, RetryConfig{
+		InitialDelay: time.Millisecond,
+		MaxDelay:     100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, &calls
+}
+
+func TestNewModel_NoRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, `{"error": {"code": 503, "status": "UNAVAILABLE", "message": "overloaded"}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	m, err := NewModel(t.Context(), "gemini-2.0-flash", &genai.ClientConfig{
+		APIKey:      "fakekey",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, err := range m.GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("hi")}, false) {
+		if !errors.Is(err, ErrUnavailable) {
+			t.Errorf("GenerateContent() error = %v, want %v", err, ErrUnavailable)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+}
+
+type testResponse struct {
+	status int
+	body   string
+}
+
+func TestModel_GenerateRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []testResponse
+		wantText  string
+		wantErr   error
+		wantCalls int32
+	}{
+		{
+			name: "retries rate limited",
+			responses: []testResponse{
+				{http.StatusTooManyRequests, `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "slow down"}}`},
+				{http.StatusOK, okBody},
+			},
+			wantText:  "Paris",
+			wantCalls: 2,
+		},
+		{
+			name: "honors retry delay of quota failure",
+			responses: []testResponse{
+				{http.StatusTooManyRequests, `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "quota", "details": [
+					{"@type": "type.googleapis.com/google.rpc.QuotaFailure"},
+					{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "0.01s"}
+				]}}`},
+				{http.StatusOK, okBody},
+			},
+			wantText:  "Paris",
+			wantCalls: 2,
+		},
+		{
+			name: "quota exceeded without retry delay",
+			responses: []testResponse{
+				{http.StatusTooManyRequests, `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "quota", "details": [
+					{"@type": "type.googleapis.com/google.rpc.QuotaFailure"}
+				]}}`},
+			},
+			wantErr:   ErrQuotaExceeded,
+			wantCalls: 1,
+		},
+		{
+			name: "retry delay longer than max delay",
+			responses: []testResponse{
+				{http.StatusTooManyRequests, `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "message": "slow down", "details": [
+					{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "60s"}
+				]}}`},
+			},
+			wantErr:   ErrRateLimited,
+			wantCalls: 1,
+		},
+		{
+			name: "gives up after max attempts",
+			responses: []testResponse{
+				{http.StatusServiceUnavailable, `{"error": {"code": 503, "status": "UNAVAILABLE", "message": "overloaded"}}`},
+			},
+			wantErr:   ErrUnavailable,
+			wantCalls: 3,
+		},
+		{
+			name: "invalid argument is not retried",
+			responses: []testResponse{
+				{http.StatusBadRequest, `{"error": {"code": 400, "status": "INVALID_ARGUMENT", "message": "bad"}}`},
+			},
+			wantErr:   ErrInvalidArgument,
+			wantCalls: 1,
+		},
+		{
+			name: "safety blocked",
+			responses: []testResponse{
+				{http.StatusOK, `{"promptFeedback": {"blockReason": "SAFETY"}}`},
+			},
+			wantErr:   ErrSafetyBlocked,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, calls := newRetryTestModel(t, tt.responses)
+
+			for resp, err := range m.GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Capital of France?")}, false) {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GenerateContent() error = %v, want %v", err, tt.wantErr)
+				}
+				if err != nil {
+					var apiErr genai.APIError
+					if tt.wantErr != ErrSafetyBlocked && !errors.As(err, &apiErr) {
+						t.Errorf("GenerateContent() error = %v, want it to wrap genai.APIError", err)
+					}
+					continue
+				}
+				if got := resp.Content.Parts[0].Text; got != tt.wantText {
+					t.Errorf("GenerateContent() text = %q, want %q", got, tt.wantText)
+				}
+			}
+			if got := calls.Load(); got != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStreamRetry(t *testing.T) {
+	m, calls := newRetryTestModel(t, []testResponse{
+		{http.StatusOK, strings.Join([]string{
+			`data: {"candidates": [{"content": {"role": "model", "parts": [{"text": "Par"}]}}]}`,
+			`{"error": {"code": 503, "status": "UNAVAILABLE", "message": "overloaded"}}`,
+			"",
+		}, "\n\n")},
+		{http.StatusOK, strings.Join([]string{
+			`data: {"candidates": [{"content": {"role": "model", "parts": [{"text": "Par"}]}}]}`,
+			`data: {"candidates": [{"content": {"role": "model", "parts": [{"text": "is"}]}, "finishReason": "STOP"}]}`,
+			"",
+		}, "\n\n")},
+	})
+
+	type result struct {
+		Text    string
+		Partial bool
+	}
+	var got []result
+	for resp, err := range m.GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Capital of France?")}, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = append(got, result{Text: resp.Content.Parts[0].Text, Partial: resp.Partial})
+	}
+
+	// The partial response of the retried call duplicating "Par" is dropped.
+	want := []result{
+		{Text: "Par", Partial: true},
+		{Text: "Paris"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
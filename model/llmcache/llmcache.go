@@ -0,0 +1,209 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package llmcache provides a [model.LLM] decorator caching the responses of
+// the underlying model, e.g. to avoid paying for identical requests re-sent
+// during evaluations or prompt iteration.
+package llmcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// HitMetadataKey is the key of LLMResponse.CustomMetadata set to true in
+// responses replayed from the cache.
+const HitMetadataKey = "adk_cache_hit"
+
+// Config is the configuration of the caching model.
+type Config struct {
+	// Model is the underlying model. Required.
+	Model model.LLM
+	// Store keeps the cached responses. If nil, a memory store with
+	// DefaultMaxEntries entries is used.
+	Store Store
+	// TTL is how long responses are served from the cache.
+	// If 0, responses never expire.
+	TTL time.Duration
+}
+
+// New returns a model.LLM replaying the responses of cfg.Model for requests
+// it already served.
+//
+// Requests are identified by Key. Streaming and non-streaming requests are
+// cached separately, so the replayed responses, including the partial ones,
+// are the same as the ones returned by the underlying model. Responses are
+// only cached if the call succeeded and the caller consumed all of them.
+func New(cfg Config) (model.LLM, error) {
+	if cfg.Model == nil {
+		return nil, fmt.Errorf("model is required")
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryStore(DefaultMaxEntries)
+	}
+	return &cachingModel{
+		llm:   cfg.Model,
+		store: store,
+		ttl:   cfg.TTL,
+		now:   time.Now,
+	}, nil
+}
+
+type cachingModel struct {
+	llm   model.LLM
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// entry is the cached value.
+type entry struct {
+	CreatedAt time.Time            `json:"created_at"`
+	Responses []*model.LLMResponse `json:"responses"`
+}
+
+func (m *cachingModel) Name() string {
+	return m.llm.Name()
+}
+
+// CountTokens implements [model.TokenCounter] by delegating to the wrapped
+// model.
+func (m *cachingModel) CountTokens(ctx context.Context, req *model.LLMRequest) (int, error) {
+	return model.CountTokens(ctx, m.llm, req)
+}
+
+func (m *cachingModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		key, err := Key(req, stream)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if cached, ok := m.get(ctx, key); ok {
+			for _, resp := range cached.Responses {
+				if resp.CustomMetadata == nil {
+					resp.CustomMetadata = make(map[string]any)
+				}
+				resp.CustomMetadata[HitMetadataKey] = true
+				if !yield(resp, nil) {
+					return
+				}
+			}
+			return
+		}
+
+		e := &entry{CreatedAt: m.now()}
+		for resp, err := range m.llm.GenerateContent(ctx, req, stream) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			// Snapshot the response before yielding it, since the caller may
+			// modify it.
+			snapshot, err := cloneResponse(resp)
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to cache response: %w", err))
+				return
+			}
+			e.Responses = append(e.Responses, snapshot)
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		// Failing to cache the response doesn't fail the call.
+		_ = m.store.Put(ctx, key, data)
+	}
+}
+
+// get returns the cached entry if it exists and didn't expire.
+func (m *cachingModel) get(ctx context.Context, key string) (*entry, bool) {
+	data, err := m.store.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, false
+	}
+	if m.ttl > 0 && m.now().Sub(e.CreatedAt) >= m.ttl {
+		return nil, false
+	}
+	return &e, true
+}
+
+func cloneResponse(resp *model.LLMResponse) (*model.LLMResponse, error) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	var clone model.LLMResponse
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return &clone, nil
+}
+
+// Key returns the cache key of the request: a canonical hash of the model
+// name, the contents, the generation config, including the tool
+// declarations, and the streaming mode.
+//
+// The HTTP options of the config are not part of the key, since they carry
+// transport settings, such as headers, rather than the request itself.
+func Key(req *model.LLMRequest, stream bool) (string, error) {
+	var cfg *genai.GenerateContentConfig
+	if req.Config != nil {
+		c := *req.Config
+		c.HTTPOptions = nil
+		cfg = &c
+	}
+	// encoding/json sorts map keys, so the encoding is canonical.
+	data, err := json.Marshal(struct {
+		Model    string                       `json:"model"`
+		Contents []*genai.Content             `json:"contents"`
+		Config   *genai.GenerateContentConfig `json:"config"`
+		Stream   bool                         `json:"stream"`
+	}{req.Model, req.Contents, cfg, stream})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ErrNotFound is returned by Store.Get if there is no value for the key.
+var ErrNotFound = errors.New("not found")
+
+// Store stores the cached responses.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the data stored under key, or ErrNotFound.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put stores data under key, replacing any previous value.
+	Put(ctx context.Context, key string, data []byte) error
+}
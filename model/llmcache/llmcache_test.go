@@ -0,0 +1,273 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package llmcache
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/modeltest"
+)
+
+func collect(t *testing.T, llm model.LLM, req *model.LLMRequest, stream bool) ([]*model.LLMResponse, error) {
+	t.Helper()
+	var got []*model.LLMResponse
+	for resp, err := range llm.GenerateContent(t.Context(), req, stream) {
+		if err != nil {
+			return got, err
+		}
+		got = append(got, resp)
+	}
+	return got, nil
+}
+
+func hit(resp *model.LLMResponse) *model.LLMResponse {
+	clone := *resp
+	clone.CustomMetadata = map[string]any{HitMetadataKey: true}
+	return &clone
+}
+
+func TestCache(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream bool
+		turn   *modeltest.Turn
+		want   []*model.LLMResponse
+	}{
+		{
+			name: "non-streaming",
+			turn: modeltest.Text("Paris"),
+			want: []*model.LLMResponse{
+				{Content: genai.NewContentFromText("Paris", genai.RoleModel), FinishReason: genai.FinishReasonStop},
+			},
+		},
+		{
+			name:   "streaming",
+			stream: true,
+			turn:   modeltest.Text("Paris").Stream("Pa", "ris"),
+			want: []*model.LLMResponse{
+				{Content: genai.NewContentFromText("Pa", genai.RoleModel), Partial: true},
+				{Content: genai.NewContentFromText("ris", genai.RoleModel), Partial: true},
+				{Content: genai.NewContentFromText("Paris", genai.RoleModel), FinishReason: genai.FinishReasonStop, TurnComplete: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The scripted model fails if it is called more than once.
+			llm, err := New(Config{Model: modeltest.New(tt.turn)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &model.LLMRequest{Model: "m", Contents: genai.Text("Capital of France?")}
+
+			got, err := collect(t, llm, req, tt.stream)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("first call mismatch (-want +got):\n%s", diff)
+			}
+
+			var wantHits []*model.LLMResponse
+			for _, resp := range tt.want {
+				wantHits = append(wantHits, hit(resp))
+			}
+			for range 2 {
+				got, err = collect(t, llm, req, tt.stream)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(wantHits, got); diff != "" {
+					t.Errorf("cached call mismatch (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestCache_CallerChangesResponse(t *testing.T) {
+	llm, err := New(Config{Model: modeltest.New(modeltest.FunctionCall("f", nil))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{Contents: genai.Text("hi")}
+
+	got, err := collect(t, llm, req, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The flow sets IDs of function calls in place.
+	got[0].Content.Parts[0].FunctionCall.ID = "adk-1"
+
+	got, err = collect(t, llm, req, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := got[0].Content.Parts[0].FunctionCall.ID; id != "" {
+		t.Errorf("cached function call ID = %q, want empty", id)
+	}
+}
+
+func TestCache_TTL(t *testing.T) {
+	inner := modeltest.New(modeltest.Text("first"), modeltest.Text("second"))
+	llm, err := New(Config{Model: inner, TTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	llm.(*cachingModel).now = func() time.Time { return now }
+	req := &model.LLMRequest{Contents: genai.Text("hi")}
+
+	text := func() string {
+		got, err := collect(t, llm, req, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got[0].Content.Parts[0].Text
+	}
+	if got := text(); got != "first" {
+		t.Errorf("text = %q, want %q", got, "first")
+	}
+	now = now.Add(59 * time.Second)
+	if got := text(); got != "first" {
+		t.Errorf("text before expiry = %q, want %q", got, "first")
+	}
+	now = now.Add(time.Second)
+	if got := text(); got != "second" {
+		t.Errorf("text after expiry = %q, want %q", got, "second")
+	}
+}
+
+func TestCache_ErrorsNotCached(t *testing.T) {
+	errBoom := errors.New("boom")
+	llm, err := New(Config{Model: modeltest.New(modeltest.Error(errBoom), modeltest.Text("ok"))})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &model.LLMRequest{Contents: genai.Text("hi")}
+
+	if _, err := collect(t, llm, req, false); !errors.Is(err, errBoom) {
+		t.Fatalf("first call error = %v, want %v", err, errBoom)
+	}
+	got, err := collect(t, llm, req, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got[0].CustomMetadata[HitMetadataKey]; ok {
+		t.Errorf("second call was served from cache")
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	dir := t.TempDir()
+	req := &model.LLMRequest{Contents: genai.Text("hi")}
+	for i, turn := range []*modeltest.Turn{modeltest.Text("hello"), nil} {
+		store, err := NewDiskStore(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The second model has no turns, so the response must come from disk.
+		inner := modeltest.New()
+		if turn != nil {
+			inner.Append(turn)
+		}
+		llm, err := New(Config{Model: inner, Store: store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := collect(t, llm, req, false)
+		if err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+		if text := got[0].Content.Parts[0].Text; text != "hello" {
+			t.Errorf("call %d: text = %q, want %q", i, text, "hello")
+		}
+	}
+}
+
+func TestMemoryStore_Eviction(t *testing.T) {
+	ctx := t.Context()
+	store := NewMemoryStore(2)
+	for _, key := range []string{"a", "b"} {
+		if err := store.Put(ctx, key, []byte(key)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Use "a", so "b" is the least recently used.
+	if _, err := store.Get(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(ctx, "c", []byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]error{"a": nil, "b": ErrNotFound, "c": nil} {
+		if _, err := store.Get(ctx, key); !errors.Is(err, want) {
+			t.Errorf("Get(%q) error = %v, want %v", key, err, want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := &model.LLMRequest{
+		Model:    "m",
+		Contents: genai.Text("hi"),
+		Config: &genai.GenerateContentConfig{
+			Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "f"}}}},
+		},
+	}
+	key := func(req *model.LLMRequest, stream bool) string {
+		k, err := Key(req, stream)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	baseKey := key(base, false)
+
+	withHeaders := *base
+	withHeaders.Config = &genai.GenerateContentConfig{
+		Tools:       base.Config.Tools,
+		HTTPOptions: &genai.HTTPOptions{Headers: http.Header{"X": []string{"y"}}},
+	}
+	if got := key(&withHeaders, false); got != baseKey {
+		t.Errorf("key with HTTP options differs")
+	}
+
+	otherTools := *base
+	otherTools.Config = &genai.GenerateContentConfig{
+		Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{Name: "g"}}}},
+	}
+	otherModel := *base
+	otherModel.Model = "n"
+	otherContents := *base
+	otherContents.Contents = genai.Text("hello")
+	for name, k := range map[string]string{
+		"tools":    key(&otherTools, false),
+		"model":    key(&otherModel, false),
+		"contents": key(&otherContents, false),
+		"stream":   key(base, true),
+	} {
+		if k == baseKey {
+			t.Errorf("key with different %s is the same", name)
+		}
+	}
+}
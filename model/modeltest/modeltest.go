@@ -0,0 +1,292 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modeltest provides a scripted [model.LLM] for deterministic tests
+// of agents, without network access.
+//
+// The model replies to every request with the first queued [Turn] matching
+// it, and records all the requests it receives:
+//
+//	llm := modeltest.New(
+//		modeltest.FunctionCall("get_weather", map[string]any{"city": "Paris"}),
+//		modeltest.Text("It is sunny.").When(modeltest.HasFunctionResponse("get_weather")),
+//	)
+//	a, err := llmagent.New(llmagent.Config{Name: "weather", Model: llm, Tools: ...})
+//	...
+//	if got := len(llm.Requests()); got != 2 {
+//		...
+//	}
+package modeltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// ErrNoTurn is returned when no queued turn matches the request.
+var ErrNoTurn = errors.New("modeltest: no turn matches the request")
+
+// Model is a scripted [model.LLM]. It is safe for concurrent use.
+type Model struct {
+	name string
+
+	mu       sync.Mutex
+	turns    []*Turn
+	requests []*model.LLMRequest
+}
+
+// New returns a Model replying with the given turns.
+func New(turns ...*Turn) *Model {
+	return &Model{name: "modeltest", turns: slices.Clone(turns)}
+}
+
+// WithName sets the name returned by the Name method and returns the model.
+func (m *Model) WithName(name string) *Model {
+	m.name = name
+	return m
+}
+
+// Append queues more turns after the pending ones.
+func (m *Model) Append(turns ...*Turn) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.turns = append(m.turns, turns...)
+}
+
+// Requests returns the requests received so far, in order.
+func (m *Model) Requests() []*model.LLMRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.requests)
+}
+
+// Pending returns the number of turns that were not used yet.
+func (m *Model) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.turns)
+}
+
+// Name implements [model.LLM].
+func (m *Model) Name() string {
+	return m.name
+}
+
+// GenerateContent implements [model.LLM].
+//
+// It replies with the first pending turn, in queue order, whose matchers
+// accept the request, and removes it from the queue. If no turn matches,
+// ErrNoTurn is returned.
+//
+// In streaming mode, the chunks of the turn are yielded as partial responses
+// before the final response.
+func (m *Model) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		turn, err := m.next(req)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		if stream {
+			for _, chunk := range turn.chunks {
+				partial := &model.LLMResponse{
+					Content: genai.NewContentFromText(chunk, genai.RoleModel),
+					Partial: true,
+				}
+				if !yield(partial, nil) {
+					return
+				}
+			}
+		}
+		if turn.err != nil {
+			yield(nil, turn.err)
+			return
+		}
+		resp := *turn.response
+		if stream {
+			resp.TurnComplete = true
+		}
+		yield(&resp, nil)
+	}
+}
+
+func (m *Model) next(req *model.LLMRequest) (*Turn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Record a copy, since the flow keeps modifying the request.
+	recorded := *req
+	recorded.Contents = slices.Clone(req.Contents)
+	m.requests = append(m.requests, &recorded)
+
+	for i, turn := range m.turns {
+		if turn.matches(req) {
+			m.turns = slices.Delete(m.turns, i, i+1)
+			return turn, nil
+		}
+	}
+	return nil, fmt.Errorf("%w (request %d, %d pending turns)", ErrNoTurn, len(m.requests), len(m.turns))
+}
+
+// Turn is a scripted reply of the model.
+type Turn struct {
+	response *model.LLMResponse
+	err      error
+	chunks   []string
+	matchers []Matcher
+}
+
+// Response returns a turn replying with resp.
+func Response(resp *model.LLMResponse) *Turn {
+	return &Turn{response: resp}
+}
+
+// Content returns a turn replying with the content.
+func Content(c *genai.Content) *Turn {
+	return Response(&model.LLMResponse{Content: c, FinishReason: genai.FinishReasonStop})
+}
+
+// Text returns a turn replying with the text.
+func Text(text string) *Turn {
+	return Content(genai.NewContentFromText(text, genai.RoleModel))
+}
+
+// FunctionCall returns a turn calling the named function.
+func FunctionCall(name string, args map[string]any) *Turn {
+	return Content(genai.NewContentFromFunctionCall(name, args, genai.RoleModel))
+}
+
+// TransferToAgent returns a turn transferring the conversation to the named
+// agent.
+func TransferToAgent(agentName string) *Turn {
+	return FunctionCall("transfer_to_agent", map[string]any{"agent_name": agentName})
+}
+
+// Error returns a turn failing with err.
+func Error(err error) *Turn {
+	return &Turn{err: err}
+}
+
+// Stream sets the text chunks yielded as partial responses before the final
+// response, or before the error of the turn, in streaming mode.
+func (t *Turn) Stream(chunks ...string) *Turn {
+	t.chunks = append(t.chunks, chunks...)
+	return t
+}
+
+// When restricts the turn to the requests accepted by all the matchers.
+func (t *Turn) When(matchers ...Matcher) *Turn {
+	t.matchers = append(t.matchers, matchers...)
+	return t
+}
+
+func (t *Turn) matches(req *model.LLMRequest) bool {
+	for _, m := range t.matchers {
+		if !m(req) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matcher reports whether a turn applies to the request.
+type Matcher func(req *model.LLMRequest) bool
+
+// LastUserText matches requests whose last user content contains substr.
+func LastUserText(substr string) Matcher {
+	return func(req *model.LLMRequest) bool {
+		for _, c := range slices.Backward(req.Contents) {
+			if c == nil || c.Role != genai.RoleUser || !hasText(c) {
+				continue
+			}
+			for _, p := range c.Parts {
+				if p != nil && strings.Contains(p.Text, substr) {
+					return true
+				}
+			}
+			return false
+		}
+		return false
+	}
+}
+
+// HasFunctionResponse matches requests whose last content contains the
+// response of the named function.
+func HasFunctionResponse(name string) Matcher {
+	return func(req *model.LLMRequest) bool {
+		if len(req.Contents) == 0 || req.Contents[len(req.Contents)-1] == nil {
+			return false
+		}
+		for _, p := range req.Contents[len(req.Contents)-1].Parts {
+			if p != nil && p.FunctionResponse != nil && p.FunctionResponse.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasTool matches requests declaring the named function.
+func HasTool(name string) Matcher {
+	return func(req *model.LLMRequest) bool {
+		if req.Config == nil {
+			return false
+		}
+		for _, t := range req.Config.Tools {
+			if t == nil {
+				continue
+			}
+			for _, decl := range t.FunctionDeclarations {
+				if decl.Name == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+// SystemInstruction matches requests whose system instruction contains
+// substr.
+func SystemInstruction(substr string) Matcher {
+	return func(req *model.LLMRequest) bool {
+		if req.Config == nil || req.Config.SystemInstruction == nil {
+			return false
+		}
+		for _, p := range req.Config.SystemInstruction.Parts {
+			if p != nil && strings.Contains(p.Text, substr) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func hasText(c *genai.Content) bool {
+	for _, p := range c.Parts {
+		if p != nil && p.Text != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modeltest_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/modeltest"
+	"google.golang.org/adk/runner"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+)
+
+func TestModel_GenerateContent(t *testing.T) {
+	errBoom := errors.New("boom")
+	llm := modeltest.New(
+		modeltest.Text("second").When(modeltest.LastUserText("2")),
+		modeltest.Text("first").Stream("fi", "rst"),
+		modeltest.Error(errBoom).Stream("par"),
+	)
+
+	type result struct {
+		Text    string
+		Partial bool
+		Err     error
+	}
+	run := func(text string, stream bool) []result {
+		var got []result
+		req := &model.LLMRequest{Contents: genai.Text(text)}
+		for resp, err := range llm.GenerateContent(t.Context(), req, stream) {
+			if err != nil {
+				got = append(got, result{Err: err})
+				continue
+			}
+			got = append(got, result{Text: resp.Content.Parts[0].Text, Partial: resp.Partial})
+		}
+		return got
+	}
+
+	// The first turn doesn't match, so the second one is used.
+	if diff := cmp.Diff([]result{{Text: "fi", Partial: true}, {Text: "rst", Partial: true}, {Text: "first"}}, run("1", true)); diff != "" {
+		t.Errorf("first request mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]result{{Text: "second"}}, run("2", false)); diff != "" {
+		t.Errorf("second request mismatch (-want +got):\n%s", diff)
+	}
+	got := run("3", true)
+	if len(got) != 2 || got[0] != (result{Text: "par", Partial: true}) || !errors.Is(got[1].Err, errBoom) {
+		t.Errorf("third request = %v, want a partial response and %v", got, errBoom)
+	}
+	if got := run("4", false); len(got) != 1 || !errors.Is(got[0].Err, modeltest.ErrNoTurn) {
+		t.Errorf("fourth request = %v, want %v", got, modeltest.ErrNoTurn)
+	}
+
+	if got, want := len(llm.Requests()), 4; got != want {
+		t.Errorf("len(Requests()) = %d, want %d", got, want)
+	}
+	if got := llm.Pending(); got != 0 {
+		t.Errorf("Pending() = %d, want 0", got)
+	}
+}
+
+func TestModel_Agent(t *testing.T) {
+	type Args struct {
+		City string `json:"city"`
+	}
+	weatherTool, err := functiontool.New(functiontool.Config{
+		Name:        "get_weather",
+		Description: "Returns the weather.",
+	}, func(ctx tool.Context, args Args) (map[string]any, error) {
+		return map[string]any{"weather": "sunny in " + args.City}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	llm := modeltest.New(
+		modeltest.FunctionCall("get_weather", map[string]any{"city": "Paris"}).When(modeltest.HasTool("get_weather")),
+		modeltest.Text("It is sunny.").When(modeltest.HasFunctionResponse("get_weather")),
+	)
+	a, err := llmagent.New(llmagent.Config{
+		Name:  "weather_agent",
+		Model: llm,
+		Tools: []tool.Tool{weatherTool},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessionService := session.InMemoryService()
+	r, err := runner.New(runner.Config{AppName: "app", Agent: a, SessionService: sessionService})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := sessionService.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var texts []string
+	for ev, err := range r.Run(t.Context(), "user", created.Session.ID(), genai.NewContentFromText("Weather in Paris?", genai.RoleUser), agent.RunConfig{}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev.Content != nil && ev.Content.Parts[0].Text != "" {
+			texts = append(texts, ev.Content.Parts[0].Text)
+		}
+	}
+	if diff := cmp.Diff([]string{"It is sunny."}, texts); diff != "" {
+		t.Errorf("texts mismatch (-want +got):\n%s", diff)
+	}
+
+	reqs := llm.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("len(Requests()) = %d, want 2", len(reqs))
+	}
+	last := reqs[1].Contents[len(reqs[1].Contents)-1].Parts[0].FunctionResponse
+	if diff := cmp.Diff(map[string]any{"weather": "sunny in Paris"}, last.Response); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// toChatRequest converts the LLMRequest into an /api/chat request.
+func (m *ollamaModel) toChatRequest(req *model.LLMRequest, stream bool) (*chatRequest, error) {
+	cfg := req.Config
+	if cfg == nil {
+		cfg = &genai.GenerateContentConfig{}
+	}
+
+	messages, err := toChatMessages(cfg.SystemInstruction, req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	cr := &chatRequest{
+		Model:     m.name,
+		Messages:  messages,
+		Stream:    stream,
+		KeepAlive: m.keepAlive,
+	}
+
+	// The server has no tool choice, so tools are only omitted when function
+	// calling is disabled.
+	if fcc := functionCallingConfig(cfg); fcc == nil || fcc.Mode != genai.FunctionCallingConfigModeNone {
+		if cr.Tools, err = toChatTools(cfg.Tools); err != nil {
+			return nil, err
+		}
+	}
+
+	if schema := converters.ResponseJSONSchema(cfg); schema != nil {
+		cr.Format = schema
+	} else if cfg.ResponseMIMEType == "application/json" {
+		cr.Format = "json"
+	}
+
+	if tc := cfg.ThinkingConfig; tc != nil {
+		think := tc.IncludeThoughts || (tc.ThinkingBudget != nil && *tc.ThinkingBudget != 0)
+		cr.Think = &think
+	}
+
+	opts := &options{
+		Temperature:      cfg.Temperature,
+		TopP:             cfg.TopP,
+		NumPredict:       cfg.MaxOutputTokens,
+		Stop:             cfg.StopSequences,
+		Seed:             cfg.Seed,
+		PresencePenalty:  cfg.PresencePenalty,
+		FrequencyPenalty: cfg.FrequencyPenalty,
+	}
+	if cfg.TopK != nil {
+		topK := int32(*cfg.TopK)
+		opts.TopK = &topK
+	}
+	cr.Options = opts
+	return cr, nil
+}
+
+func functionCallingConfig(cfg *genai.GenerateContentConfig) *genai.FunctionCallingConfig {
+	if cfg.ToolConfig == nil {
+		return nil
+	}
+	return cfg.ToolConfig.FunctionCallingConfig
+}
+
+// toChatMessages converts the system instruction and the conversation
+// contents into chat messages.
+func toChatMessages(system *genai.Content, contents []*genai.Content) ([]*chatMessage, error) {
+	var messages []*chatMessage
+	if text := joinText(system); text != "" {
+		messages = append(messages, &chatMessage{Role: "system", Content: text})
+	}
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		role := "user"
+		if c.Role == genai.RoleModel {
+			role = "assistant"
+		}
+		msg := &chatMessage{Role: role}
+		var text strings.Builder
+		for _, p := range c.Parts {
+			switch {
+			case p == nil || p.Thought:
+				// Thoughts are not sent back to the model.
+			case p.FunctionCall != nil:
+				args := p.FunctionCall.Args
+				if args == nil {
+					args = map[string]any{}
+				}
+				msg.ToolCalls = append(msg.ToolCalls, &toolCall{Function: functionCall{
+					Name:      p.FunctionCall.Name,
+					Arguments: args,
+				}})
+			case p.FunctionResponse != nil:
+				resp, err := json.Marshal(p.FunctionResponse.Response)
+				if err != nil {
+					return nil, fmt.Errorf("failed to marshal response of function %q: %w", p.FunctionResponse.Name, err)
+				}
+				messages = append(messages, &chatMessage{
+					Role:     "tool",
+					Content:  string(resp),
+					ToolName: p.FunctionResponse.Name,
+				})
+			case p.Text != "":
+				text.WriteString(p.Text)
+			case p.InlineData != nil:
+				if !strings.HasPrefix(p.InlineData.MIMEType, "image/") {
+					return nil, fmt.Errorf("unsupported inline data MIME type %q", p.InlineData.MIMEType)
+				}
+				msg.Images = append(msg.Images, base64.StdEncoding.EncodeToString(p.InlineData.Data))
+			default:
+				return nil, fmt.Errorf("unsupported part: %+v", p)
+			}
+		}
+		msg.Content = text.String()
+		if msg.Content != "" || len(msg.Images) > 0 || len(msg.ToolCalls) > 0 {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+func toChatTools(tools []*genai.Tool) ([]*chatTool, error) {
+	var result []*chatTool
+	for _, t := range tools {
+		if t == nil {
+			continue
+		}
+		if len(t.FunctionDeclarations) == 0 {
+			return nil, fmt.Errorf("unsupported tool: only function declarations are supported by local models")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			result = append(result, &chatTool{
+				Type: "function",
+				Function: &functionDefinition{
+					Name:        decl.Name,
+					Description: decl.Description,
+					Parameters:  converters.FunctionParameters(decl),
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
+// toLLMResponse converts a complete /api/chat response.
+func toLLMResponse(resp *chatResponse) *model.LLMResponse {
+	msg := resp.Message
+	if msg == nil {
+		msg = &chatMessage{}
+	}
+	return newLLMResponse(toContent(msg.Thinking, msg.Content, msg.ToolCalls), resp)
+}
+
+func newLLMResponse(content *genai.Content, last *chatResponse) *model.LLMResponse {
+	resp := &model.LLMResponse{
+		Content:      content,
+		FinishReason: toFinishReason(last.DoneReason),
+	}
+	if last.Done {
+		resp.UsageMetadata = &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:     last.PromptEvalCount,
+			CandidatesTokenCount: last.EvalCount,
+			TotalTokenCount:      last.PromptEvalCount + last.EvalCount,
+		}
+	}
+	if content == nil {
+		resp.ErrorCode = string(resp.FinishReason)
+		resp.ErrorMessage = "model returned an empty response"
+		if resp.ErrorCode == "" {
+			resp.ErrorCode = "UNKNOWN_ERROR"
+		}
+	}
+	return resp
+}
+
+// toContent builds the model content from the response message fields.
+// It returns nil if the message is empty.
+func toContent(thinking, text string, toolCalls []*toolCall) *genai.Content {
+	var parts []*genai.Part
+	if thinking != "" {
+		parts = append(parts, &genai.Part{Text: thinking, Thought: true})
+	}
+	if text != "" {
+		parts = append(parts, genai.NewPartFromText(text))
+	}
+	for _, tc := range toolCalls {
+		args := tc.Function.Arguments
+		if args == nil {
+			args = map[string]any{}
+		}
+		parts = append(parts, genai.NewPartFromFunctionCall(tc.Function.Name, args))
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return genai.NewContentFromParts(parts, genai.RoleModel)
+}
+
+func toFinishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "stop":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+func joinText(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range c.Parts {
+		if p != nil && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// streamAggregator accumulates streaming chunks into the final response.
+type streamAggregator struct {
+	text      strings.Builder
+	thinking  strings.Builder
+	toolCalls []*toolCall
+	last      chatResponse
+}
+
+// add processes a chunk and returns the partial response to yield, if any.
+func (a *streamAggregator) add(chunk *chatResponse) *model.LLMResponse {
+	a.last = *chunk
+	msg := chunk.Message
+	if msg == nil {
+		return nil
+	}
+	// Tool calls are not split across chunks.
+	a.toolCalls = append(a.toolCalls, msg.ToolCalls...)
+
+	var parts []*genai.Part
+	if msg.Thinking != "" {
+		a.thinking.WriteString(msg.Thinking)
+		parts = append(parts, &genai.Part{Text: msg.Thinking, Thought: true})
+	}
+	if msg.Content != "" {
+		a.text.WriteString(msg.Content)
+		parts = append(parts, genai.NewPartFromText(msg.Content))
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &model.LLMResponse{
+		Content: genai.NewContentFromParts(parts, genai.RoleModel),
+		Partial: true,
+	}
+}
+
+// close returns the aggregated final response.
+func (a *streamAggregator) close() *model.LLMResponse {
+	resp := newLLMResponse(toContent(a.thinking.String(), a.text.String(), a.toolCalls), &a.last)
+	resp.TurnComplete = true
+	return resp
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ollama implements the [model.LLM] interface for locally hosted
+// model servers exposing an Ollama-style /api/chat endpoint.
+//
+// It allows running agents fully offline, e.g.:
+//
+//	model, err := ollama.NewModel(ctx, "qwen3", nil)
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"google.golang.org/adk/internal/httpmodel"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+// DefaultBaseURL is the base URL of a model server running on the local host
+// with the default port.
+const DefaultBaseURL = "http://localhost:11434"
+
+// ClientConfig is the configuration of the local model server client.
+type ClientConfig struct {
+	// BaseURL of the server. The "/api/chat" path is appended to it.
+	// If empty, DefaultBaseURL is used.
+	BaseURL string
+	// KeepAlive controls how long the model stays loaded in memory after a
+	// request, e.g. "5m". If empty, the server default is used.
+	KeepAlive string
+	// Headers are additional HTTP headers sent with every request.
+	Headers http.Header
+	// HTTPClient is used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+type ollamaModel struct {
+	name               string
+	baseURL            string
+	keepAlive          string
+	headers            http.Header
+	httpClient         *http.Client
+	versionHeaderValue string
+}
+
+// NewModel returns [model.LLM], backed by a local model server.
+//
+// The modelName is the name of a model available on the server
+// (e.g., "llama3.2" or "qwen3:8b").
+// A nil cfg is equivalent to an empty ClientConfig.
+func NewModel(ctx context.Context, modelName string, cfg *ClientConfig) (model.LLM, error) {
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	if cfg == nil {
+		cfg = &ClientConfig{}
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &ollamaModel{
+		name:       modelName,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		keepAlive:  cfg.KeepAlive,
+		headers:    cfg.Headers.Clone(),
+		httpClient: httpClient,
+		versionHeaderValue: fmt.Sprintf("google-adk/%s gl-go/%s", version.Version,
+			strings.TrimPrefix(runtime.Version(), "go")),
+	}, nil
+}
+
+func (m *ollamaModel) Name() string {
+	return m.name
+}
+
+// GenerateContent calls the underlying model.
+func (m *ollamaModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	llminternal.MaybeAppendUserContent(req)
+
+	if stream {
+		return m.generateStream(ctx, req)
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		resp, err := m.generate(ctx, req)
+		yield(resp, err)
+	}
+}
+
+// generate calls the model synchronously.
+func (m *ollamaModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	body, err := m.do(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var resp chatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if resp.Error != "" {
+		return nil, &APIError{Provider: provider, StatusCode: http.StatusOK, Message: resp.Error}
+	}
+	return toLLMResponse(&resp), nil
+}
+
+// generateStream returns a stream of responses from the model.
+//
+// The server streams newline-delimited JSON chunks. Text and thinking deltas
+// are yielded as partial responses. Once the last chunk is received, a final
+// response aggregating the text, tool calls, done reason and usage is yielded.
+func (m *ollamaModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		body, err := m.do(ctx, req, true)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer body.Close()
+
+		aggregator := &streamAggregator{}
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64<<10), 4<<20)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var chunk chatResponse
+			if err := json.Unmarshal(line, &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream chunk: %w", err))
+				return
+			}
+			if chunk.Error != "" {
+				yield(nil, &APIError{Provider: provider, StatusCode: http.StatusOK, Message: chunk.Error})
+				return
+			}
+			if partial := aggregator.add(&chunk); partial != nil {
+				if !yield(partial, nil) {
+					return // Consumer stopped
+				}
+			}
+			if chunk.Done {
+				break
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			yield(nil, fmt.Errorf("failed to read stream: %w", err))
+			return
+		}
+		yield(aggregator.close(), nil)
+	}
+}
+
+// do sends the request and returns the body of a successful response.
+func (m *ollamaModel) do(ctx context.Context, req *model.LLMRequest, stream bool) (io.ReadCloser, error) {
+	chatReq, err := m.toChatRequest(req, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert request: %w", err)
+	}
+	header := http.Header{}
+	header.Set("User-Agent", m.versionHeaderValue)
+	return httpmodel.Post(ctx, m.httpClient, &httpmodel.Request{
+		Provider:      provider,
+		URL:           m.baseURL + "/api/chat",
+		Body:          chatReq,
+		DefaultHeader: m.headers,
+		Config:        req.Config,
+		Header:        header,
+		ParseError:    parseError,
+	})
+}
+
+// APIError is returned when the server responds with an error. The server
+// only reports a message.
+type APIError = httpmodel.APIError
+
+const provider = "ollama"
+
+func parseError(data []byte, apiErr *APIError) bool {
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.Error == "" {
+		return false
+	}
+	apiErr.Message = body.Error
+	return true
+}
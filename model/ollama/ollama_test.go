@@ -0,0 +1,304 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// newTestServer returns a server responding with respBody and storing the
+// decoded request body in gotReq.
+func newTestServer(t *testing.T, status int, respBody string, gotReq *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotReq != nil {
+			if err := json.Unmarshal(data, gotReq); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, respBody)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestModel(t *testing.T, srv *httptest.Server) model.LLM {
+	t.Helper()
+	m, err := NewModel(t.Context(), "test-model", &ClientConfig{BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestModel_Generate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *model.LLMRequest
+		respBody string
+		wantReq  map[string]any
+		want     *model.LLMResponse
+	}{
+		{
+			name: "text",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("Be brief.", genai.RoleUser),
+					Temperature:       new(float32),
+				},
+			},
+			respBody: `{
+				"model": "test-model",
+				"message": {"role": "assistant", "content": "Paris"},
+				"done": true,
+				"done_reason": "stop",
+				"prompt_eval_count": 10,
+				"eval_count": 2
+			}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "system", "content": "Be brief."},
+					map[string]any{"role": "user", "content": "What is the capital of France? One word."},
+				},
+				"options": map[string]any{"temperature": float64(0)},
+				"stream":  false,
+			},
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText("Paris", genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount:     10,
+					CandidatesTokenCount: 2,
+					TotalTokenCount:      12,
+				},
+			},
+		},
+		{
+			name: "tools",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromText("Weather in Paris?", genai.RoleUser),
+					genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+					genai.NewContentFromFunctionResponse("get_weather", map[string]any{"result": "sunny"}, genai.RoleUser),
+				},
+				Config: &genai.GenerateContentConfig{
+					Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+						Name:        "get_weather",
+						Description: "Returns the weather.",
+						Parameters: &genai.Schema{
+							Type:       genai.TypeObject,
+							Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+						},
+					}}}},
+				},
+			},
+			respBody: `{
+				"message": {"role": "assistant", "content": "", "tool_calls": [
+					{"function": {"name": "get_weather", "arguments": {"city": "Rome"}}}
+				]},
+				"done": true,
+				"done_reason": "stop"
+			}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": "Weather in Paris?"},
+					map[string]any{"role": "assistant", "content": "", "tool_calls": []any{
+						map[string]any{"function": map[string]any{"name": "get_weather", "arguments": map[string]any{"city": "Paris"}}},
+					}},
+					map[string]any{"role": "tool", "content": `{"result":"sunny"}`, "tool_name": "get_weather"},
+				},
+				"tools": []any{map[string]any{
+					"type": "function",
+					"function": map[string]any{
+						"name":        "get_weather",
+						"description": "Returns the weather.",
+						"parameters": map[string]any{
+							"type":       "object",
+							"properties": map[string]any{"city": map[string]any{"type": "string"}},
+						},
+					},
+				}},
+				"options": map[string]any{},
+				"stream":  false,
+			},
+			want: &model.LLMResponse{
+				Content:       genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Rome"}, genai.RoleModel),
+				FinishReason:  genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{},
+			},
+		},
+		{
+			name: "output_schema",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Capital of France?"),
+				Config: &genai.GenerateContentConfig{
+					ResponseSchema: &genai.Schema{
+						Type:       genai.TypeObject,
+						Properties: map[string]*genai.Schema{"capital": {Type: genai.TypeString}},
+					},
+				},
+			},
+			respBody: `{"message": {"role": "assistant", "content": "{\"capital\":\"Paris\"}"}, "done": true, "done_reason": "stop"}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": "Capital of France?"},
+				},
+				"format": map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"capital": map[string]any{"type": "string"}},
+				},
+				"options": map[string]any{},
+				"stream":  false,
+			},
+			want: &model.LLMResponse{
+				Content:       genai.NewContentFromText(`{"capital":"Paris"}`, genai.RoleModel),
+				FinishReason:  genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{},
+			},
+		},
+		{
+			name: "json_mode",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Capital of France as JSON?"),
+				Config: &genai.GenerateContentConfig{
+					ResponseMIMEType: "application/json",
+					MaxOutputTokens:  5,
+				},
+			},
+			respBody: `{"message": {"role": "assistant", "content": "{\"capital\":"}, "done": true, "done_reason": "length"}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": "Capital of France as JSON?"},
+				},
+				"format":  "json",
+				"options": map[string]any{"num_predict": float64(5)},
+				"stream":  false,
+			},
+			want: &model.LLMResponse{
+				Content:       genai.NewContentFromText(`{"capital":`, genai.RoleModel),
+				FinishReason:  genai.FinishReasonMaxTokens,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReq map[string]any
+			srv := newTestServer(t, http.StatusOK, tt.respBody, &gotReq)
+
+			var got []*model.LLMResponse
+			for resp, err := range newTestModel(t, srv).GenerateContent(t.Context(), tt.req, false) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				got = append(got, resp)
+			}
+
+			if diff := cmp.Diff(tt.wantReq, gotReq); diff != "" {
+				t.Errorf("request mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]*model.LLMResponse{tt.want}, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	respBody := `{"message":{"role":"assistant","content":"","thinking":"Hmm."},"done":false}
+{"message":{"role":"assistant","content":"Let me "},"done":false}
+{"message":{"role":"assistant","content":"check."},"done":false}
+{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_weather","arguments":{"city":"Paris"}}}]},"done":false}
+{"message":{"role":"assistant","content":""},"done":true,"done_reason":"stop","prompt_eval_count":5,"eval_count":7}
+`
+	var gotReq map[string]any
+	srv := newTestServer(t, http.StatusOK, respBody, &gotReq)
+
+	var got []*model.LLMResponse
+	for resp, err := range newTestModel(t, srv).GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Weather in Paris?")}, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = append(got, resp)
+	}
+
+	if gotReq["stream"] != true {
+		t.Errorf("request stream = %v, want true", gotReq["stream"])
+	}
+
+	want := []*model.LLMResponse{
+		{Content: genai.NewContentFromParts([]*genai.Part{{Text: "Hmm.", Thought: true}}, genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("Let me ", genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("check.", genai.RoleModel), Partial: true},
+		{
+			Content: genai.NewContentFromParts([]*genai.Part{
+				{Text: "Hmm.", Thought: true},
+				genai.NewPartFromText("Let me check."),
+				genai.NewPartFromFunctionCall("get_weather", map[string]any{"city": "Paris"}),
+			}, genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     5,
+				CandidatesTokenCount: 7,
+				TotalTokenCount:      12,
+			},
+			TurnComplete: true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModel_GenerateError(t *testing.T) {
+	srv := newTestServer(t, http.StatusNotFound, `{"error": "model \"test-model\" not found, try pulling it first"}`, nil)
+
+	for _, stream := range []bool{false, true} {
+		for _, err := range newTestModel(t, srv).GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("hi")}, stream) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GenerateContent(stream=%v) error = %v, want *APIError", stream, err)
+			}
+			want := &APIError{Provider: "ollama", StatusCode: http.StatusNotFound, Message: `model "test-model" not found, try pulling it first`}
+			if diff := cmp.Diff(want, apiErr); diff != "" {
+				t.Errorf("GenerateContent(stream=%v) error mismatch (-want +got):\n%s", stream, diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ollama
+
+// Wire types of the /api/chat endpoint.
+// Only the subset of fields used by the adapter is declared.
+
+type chatRequest struct {
+	Model    string         `json:"model"`
+	Messages []*chatMessage `json:"messages"`
+	Tools    []*chatTool    `json:"tools,omitempty"`
+	// Format is either "json" or a JSON schema of the expected output.
+	Format    any      `json:"format,omitempty"`
+	Options   *options `json:"options,omitempty"`
+	Stream    bool     `json:"stream"`
+	Think     *bool    `json:"think,omitempty"`
+	KeepAlive string   `json:"keep_alive,omitempty"`
+}
+
+type chatMessage struct {
+	Role     string `json:"role"`
+	Content  string `json:"content"`
+	Thinking string `json:"thinking,omitempty"`
+	// Images are base64-encoded.
+	Images    []string    `json:"images,omitempty"`
+	ToolCalls []*toolCall `json:"tool_calls,omitempty"`
+	// ToolName is set in "tool" messages to the name of the called function.
+	ToolName string `json:"tool_name,omitempty"`
+}
+
+type toolCall struct {
+	Function functionCall `json:"function"`
+}
+
+type functionCall struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type chatTool struct {
+	Type     string              `json:"type"`
+	Function *functionDefinition `json:"function"`
+}
+
+type functionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters"`
+}
+
+type options struct {
+	Temperature      *float32 `json:"temperature,omitempty"`
+	TopP             *float32 `json:"top_p,omitempty"`
+	TopK             *int32   `json:"top_k,omitempty"`
+	NumPredict       int32    `json:"num_predict,omitempty"`
+	Stop             []string `json:"stop,omitempty"`
+	Seed             *int32   `json:"seed,omitempty"`
+	PresencePenalty  *float32 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
+}
+
+// chatResponse is both a complete response and a streaming chunk.
+type chatResponse struct {
+	Model           string       `json:"model"`
+	Message         *chatMessage `json:"message"`
+	Done            bool         `json:"done"`
+	DoneReason      string       `json:"done_reason"`
+	PromptEvalCount int32        `json:"prompt_eval_count"`
+	EvalCount       int32        `json:"eval_count"`
+	// Error is set when a failure happens mid-stream.
+	Error string `json:"error"`
+}
@@ -0,0 +1,430 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/internal/llminternal/converters"
+	"google.golang.org/adk/model"
+)
+
+// toChatRequest converts the LLMRequest into a Chat Completions request.
+func (m *openaiModel) toChatRequest(req *model.LLMRequest, stream bool) (*chatRequest, error) {
+	cfg := req.Config
+	if cfg == nil {
+		cfg = &genai.GenerateContentConfig{}
+	}
+
+	messages, err := toChatMessages(cfg.SystemInstruction, req.Contents)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := toChatTools(cfg.Tools)
+	if err != nil {
+		return nil, err
+	}
+
+	cr := &chatRequest{
+		Model:            m.name,
+		Messages:         messages,
+		Tools:            tools,
+		ToolChoice:       toToolChoice(cfg.ToolConfig),
+		Temperature:      cfg.Temperature,
+		TopP:             cfg.TopP,
+		MaxTokens:        cfg.MaxOutputTokens,
+		Stop:             cfg.StopSequences,
+		Seed:             cfg.Seed,
+		PresencePenalty:  cfg.PresencePenalty,
+		FrequencyPenalty: cfg.FrequencyPenalty,
+		ResponseFormat:   toResponseFormat(cfg),
+		Stream:           stream,
+	}
+	if cfg.CandidateCount > 1 {
+		cr.N = cfg.CandidateCount
+	}
+	if stream {
+		cr.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+	return cr, nil
+}
+
+// toChatMessages converts the system instruction and the conversation
+// contents into Chat Completions messages.
+func toChatMessages(system *genai.Content, contents []*genai.Content) ([]*chatMessage, error) {
+	var messages []*chatMessage
+	if text := joinText(system); text != "" {
+		messages = append(messages, &chatMessage{Role: "system", Content: text})
+	}
+
+	ids := converters.NewFunctionCallIDs("call_")
+	for _, c := range contents {
+		if c == nil {
+			continue
+		}
+		var (
+			msgs []*chatMessage
+			err  error
+		)
+		if c.Role == genai.RoleModel {
+			msgs, err = assistantMessages(c, ids)
+		} else {
+			msgs, err = userMessages(c, ids)
+		}
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msgs...)
+	}
+	return messages, nil
+}
+
+func assistantMessages(c *genai.Content, ids *converters.FunctionCallIDs) ([]*chatMessage, error) {
+	msg := &chatMessage{Role: "assistant"}
+	var text strings.Builder
+	for _, p := range c.Parts {
+		switch {
+		case p == nil || p.Thought:
+			// Thoughts are not sent back to the model.
+		case p.FunctionCall != nil:
+			args, err := json.Marshal(p.FunctionCall.Args)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal arguments of function call %q: %w", p.FunctionCall.Name, err)
+			}
+			if p.FunctionCall.Args == nil {
+				args = []byte("{}")
+			}
+			msg.ToolCalls = append(msg.ToolCalls, &toolCall{
+				ID:   ids.ForCall(p.FunctionCall),
+				Type: "function",
+				Function: functionCall{
+					Name:      p.FunctionCall.Name,
+					Arguments: string(args),
+				},
+			})
+		case p.Text != "":
+			text.WriteString(p.Text)
+		default:
+			return nil, fmt.Errorf("unsupported part in model content: %+v", p)
+		}
+	}
+	if text.Len() > 0 {
+		msg.Content = text.String()
+	}
+	if msg.Content == nil && len(msg.ToolCalls) == 0 {
+		return nil, nil
+	}
+	return []*chatMessage{msg}, nil
+}
+
+func userMessages(c *genai.Content, ids *converters.FunctionCallIDs) ([]*chatMessage, error) {
+	var (
+		messages []*chatMessage
+		parts    []*contentPart
+	)
+	for _, p := range c.Parts {
+		switch {
+		case p == nil || p.Thought:
+		case p.FunctionResponse != nil:
+			// Tool results must immediately follow the assistant message with
+			// the tool calls, so they go before any other user input.
+			resp, err := json.Marshal(p.FunctionResponse.Response)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal response of function %q: %w", p.FunctionResponse.Name, err)
+			}
+			messages = append(messages, &chatMessage{
+				Role:       "tool",
+				ToolCallID: ids.ForResponse(p.FunctionResponse),
+				Content:    string(resp),
+			})
+		case p.Text != "":
+			parts = append(parts, &contentPart{Type: "text", Text: p.Text})
+		case p.InlineData != nil:
+			if !strings.HasPrefix(p.InlineData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported inline data MIME type %q", p.InlineData.MIMEType)
+			}
+			url := "data:" + p.InlineData.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.InlineData.Data)
+			parts = append(parts, &contentPart{Type: "image_url", ImageURL: &imageURL{URL: url}})
+		case p.FileData != nil:
+			if !strings.HasPrefix(p.FileData.MIMEType, "image/") {
+				return nil, fmt.Errorf("unsupported file data MIME type %q", p.FileData.MIMEType)
+			}
+			parts = append(parts, &contentPart{Type: "image_url", ImageURL: &imageURL{URL: p.FileData.FileURI}})
+		default:
+			return nil, fmt.Errorf("unsupported part in user content: %+v", p)
+		}
+	}
+	switch {
+	case len(parts) == 1 && parts[0].Type == "text":
+		messages = append(messages, &chatMessage{Role: "user", Content: parts[0].Text})
+	case len(parts) > 0:
+		messages = append(messages, &chatMessage{Role: "user", Content: parts})
+	}
+	return messages, nil
+}
+
+func toChatTools(tools []*genai.Tool) ([]*chatTool, error) {
+	var result []*chatTool
+	for _, t := range tools {
+		if t == nil {
+			continue
+		}
+		if len(t.FunctionDeclarations) == 0 {
+			return nil, fmt.Errorf("unsupported tool: only function declarations are supported by the Chat Completions API")
+		}
+		for _, decl := range t.FunctionDeclarations {
+			result = append(result, &chatTool{
+				Type: "function",
+				Function: &functionDefinition{
+					Name:        decl.Name,
+					Description: decl.Description,
+					Parameters:  converters.FunctionParameters(decl),
+				},
+			})
+		}
+	}
+	return result, nil
+}
+
+func toToolChoice(cfg *genai.ToolConfig) any {
+	if cfg == nil || cfg.FunctionCallingConfig == nil {
+		return nil
+	}
+	fcc := cfg.FunctionCallingConfig
+	switch fcc.Mode {
+	case genai.FunctionCallingConfigModeAuto:
+		return "auto"
+	case genai.FunctionCallingConfigModeNone:
+		return "none"
+	case genai.FunctionCallingConfigModeAny:
+		if len(fcc.AllowedFunctionNames) == 1 {
+			return map[string]any{
+				"type":     "function",
+				"function": map[string]any{"name": fcc.AllowedFunctionNames[0]},
+			}
+		}
+		return "required"
+	default:
+		return nil
+	}
+}
+
+func toResponseFormat(cfg *genai.GenerateContentConfig) *responseFormat {
+	if schema := converters.ResponseJSONSchema(cfg); schema != nil {
+		return &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &jsonSchema{Name: "response", Schema: schema},
+		}
+	}
+	if cfg.ResponseMIMEType == "application/json" {
+		return &responseFormat{Type: "json_object"}
+	}
+	return nil
+}
+
+// toLLMResponse converts a complete Chat Completions response.
+func toLLMResponse(resp *chatResponse) (*model.LLMResponse, error) {
+	choice := resp.Choices[0]
+	msg := choice.Message
+	if msg == nil {
+		msg = &responseMessage{}
+	}
+	content, err := toContent(msg.ReasoningContent, msg.Content, msg.Refusal, msg.ToolCalls)
+	if err != nil {
+		return nil, err
+	}
+	return newLLMResponse(content, choice.FinishReason, resp.Usage), nil
+}
+
+func newLLMResponse(content *genai.Content, finishReason string, u *usage) *model.LLMResponse {
+	resp := &model.LLMResponse{
+		Content:       content,
+		FinishReason:  toFinishReason(finishReason),
+		UsageMetadata: toUsageMetadata(u),
+	}
+	if content == nil {
+		resp.ErrorCode = string(resp.FinishReason)
+		resp.ErrorMessage = "model returned an empty response"
+		if resp.ErrorCode == "" {
+			resp.ErrorCode = "UNKNOWN_ERROR"
+		}
+	}
+	return resp
+}
+
+// toContent builds the model content from the response message fields.
+// It returns nil if the message is empty.
+func toContent(reasoning, text, refusal string, toolCalls []*toolCall) (*genai.Content, error) {
+	var parts []*genai.Part
+	if reasoning != "" {
+		parts = append(parts, &genai.Part{Text: reasoning, Thought: true})
+	}
+	if text == "" {
+		text = refusal
+	}
+	if text != "" {
+		parts = append(parts, genai.NewPartFromText(text))
+	}
+	for _, tc := range toolCalls {
+		args := map[string]any{}
+		if strings.TrimSpace(tc.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+				return nil, fmt.Errorf("failed to parse arguments of tool call %q: %w", tc.Function.Name, err)
+			}
+		}
+		parts = append(parts, &genai.Part{FunctionCall: &genai.FunctionCall{
+			ID:   tc.ID,
+			Name: tc.Function.Name,
+			Args: args,
+		}})
+	}
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	return genai.NewContentFromParts(parts, genai.RoleModel), nil
+}
+
+func toFinishReason(reason string) genai.FinishReason {
+	switch reason {
+	case "":
+		return ""
+	case "stop", "tool_calls", "function_call":
+		return genai.FinishReasonStop
+	case "length":
+		return genai.FinishReasonMaxTokens
+	case "content_filter":
+		return genai.FinishReasonSafety
+	default:
+		return genai.FinishReasonOther
+	}
+}
+
+func toUsageMetadata(u *usage) *genai.GenerateContentResponseUsageMetadata {
+	if u == nil {
+		return nil
+	}
+	md := &genai.GenerateContentResponseUsageMetadata{
+		PromptTokenCount:     u.PromptTokens,
+		CandidatesTokenCount: u.CompletionTokens,
+		TotalTokenCount:      u.TotalTokens,
+	}
+	if u.PromptTokensDetails != nil {
+		md.CachedContentTokenCount = u.PromptTokensDetails.CachedTokens
+	}
+	if u.CompletionTokensDetails != nil {
+		md.ThoughtsTokenCount = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return md
+}
+
+func joinText(c *genai.Content) string {
+	if c == nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range c.Parts {
+		if p != nil && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// streamAggregator accumulates streaming chunks into the final response.
+type streamAggregator struct {
+	text         strings.Builder
+	reasoning    strings.Builder
+	refusal      strings.Builder
+	toolCalls    []*toolCall
+	finishReason string
+	usage        *usage
+}
+
+// add processes a chunk and returns the partial response to yield, if any.
+func (a *streamAggregator) add(chunk *chatResponse) *model.LLMResponse {
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+	if len(chunk.Choices) == 0 {
+		return nil
+	}
+	choice := chunk.Choices[0]
+	if choice.FinishReason != "" {
+		a.finishReason = choice.FinishReason
+	}
+	delta := choice.Delta
+	if delta == nil {
+		return nil
+	}
+
+	for _, tc := range delta.ToolCalls {
+		idx := len(a.toolCalls)
+		if tc.Index != nil {
+			idx = *tc.Index
+		}
+		for len(a.toolCalls) <= idx {
+			a.toolCalls = append(a.toolCalls, &toolCall{})
+		}
+		cur := a.toolCalls[idx]
+		if tc.ID != "" {
+			cur.ID = tc.ID
+		}
+		if cur.Function.Name == "" {
+			cur.Function.Name = tc.Function.Name
+		}
+		cur.Function.Arguments += tc.Function.Arguments
+	}
+	a.refusal.WriteString(delta.Refusal)
+
+	var parts []*genai.Part
+	if delta.ReasoningContent != "" {
+		a.reasoning.WriteString(delta.ReasoningContent)
+		parts = append(parts, &genai.Part{Text: delta.ReasoningContent, Thought: true})
+	}
+	if delta.Content != "" {
+		a.text.WriteString(delta.Content)
+		parts = append(parts, genai.NewPartFromText(delta.Content))
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return &model.LLMResponse{
+		Content: genai.NewContentFromParts(parts, genai.RoleModel),
+		Partial: true,
+	}
+}
+
+// close returns the aggregated final response.
+func (a *streamAggregator) close() (*model.LLMResponse, error) {
+	// Skip placeholders of tool calls with missing indexes.
+	var toolCalls []*toolCall
+	for _, tc := range a.toolCalls {
+		if tc.Function.Name != "" {
+			toolCalls = append(toolCalls, tc)
+		}
+	}
+	content, err := toContent(a.reasoning.String(), a.text.String(), a.refusal.String(), toolCalls)
+	if err != nil {
+		return nil, err
+	}
+	resp := newLLMResponse(content, a.finishReason, a.usage)
+	resp.TurnComplete = true
+	return resp, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package openai implements the [model.LLM] interface for OpenAI-compatible
+// Chat Completions APIs, such as OpenAI, vLLM, LM Studio or Azure-style
+// gateways.
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"runtime"
+	"strings"
+
+	"google.golang.org/adk/internal/httpmodel"
+	"google.golang.org/adk/internal/llminternal"
+	"google.golang.org/adk/internal/sse"
+	"google.golang.org/adk/internal/version"
+	"google.golang.org/adk/model"
+)
+
+// DefaultBaseURL is the base URL of the OpenAI API.
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// ClientConfig is the configuration of the Chat Completions client.
+type ClientConfig struct {
+	// BaseURL of the API, e.g. "http://localhost:8000/v1" for a local vLLM
+	// server. The "/chat/completions" path is appended to it.
+	// If empty, DefaultBaseURL is used.
+	BaseURL string
+	// APIKey is sent as a bearer token in the Authorization header.
+	// It is optional, since many self-hosted servers don't require one.
+	APIKey string
+	// Headers are additional HTTP headers sent with every request,
+	// e.g. "api-key" for Azure-style gateways.
+	Headers http.Header
+	// HTTPClient is used to send requests. If nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+}
+
+type openaiModel struct {
+	name               string
+	baseURL            string
+	apiKey             string
+	headers            http.Header
+	httpClient         *http.Client
+	versionHeaderValue string
+}
+
+// NewModel returns [model.LLM], backed by an OpenAI-compatible Chat
+// Completions API.
+//
+// The modelName is sent as is in the "model" field of every request
+// (e.g., "gpt-4o-mini" or the name of the model served by vLLM).
+// A nil cfg is equivalent to an empty ClientConfig.
+func NewModel(ctx context.Context, modelName string, cfg *ClientConfig) (model.LLM, error) {
+	if modelName == "" {
+		return nil, fmt.Errorf("model name is required")
+	}
+	if cfg == nil {
+		cfg = &ClientConfig{}
+	}
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &openaiModel{
+		name:       modelName,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     cfg.APIKey,
+		headers:    cfg.Headers.Clone(),
+		httpClient: httpClient,
+		versionHeaderValue: fmt.Sprintf("google-adk/%s gl-go/%s", version.Version,
+			strings.TrimPrefix(runtime.Version(), "go")),
+	}, nil
+}
+
+func (m *openaiModel) Name() string {
+	return m.name
+}
+
+// GenerateContent calls the underlying model.
+func (m *openaiModel) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	llminternal.MaybeAppendUserContent(req)
+
+	if stream {
+		return m.generateStream(ctx, req)
+	}
+
+	return func(yield func(*model.LLMResponse, error) bool) {
+		resp, err := m.generate(ctx, req)
+		yield(resp, err)
+	}
+}
+
+// generate calls the model synchronously returning result from the first choice.
+func (m *openaiModel) generate(ctx context.Context, req *model.LLMRequest) (*model.LLMResponse, error) {
+	body, err := m.do(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var resp chatResponse
+	if err := json.NewDecoder(body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+	return toLLMResponse(&resp)
+}
+
+// generateStream returns a stream of responses from the model.
+//
+// Text deltas are yielded as partial responses. Once the stream ends, a final
+// response aggregating the text, tool calls, finish reason and usage is
+// yielded.
+func (m *openaiModel) generateStream(ctx context.Context, req *model.LLMRequest) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		body, err := m.do(ctx, req, true)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer body.Close()
+
+		aggregator := &streamAggregator{}
+		for ev, err := range sse.Events(body) {
+			if err != nil {
+				yield(nil, fmt.Errorf("failed to read stream: %w", err))
+				return
+			}
+			if string(ev.Data) == "[DONE]" {
+				break
+			}
+			var chunk chatResponse
+			if err := json.Unmarshal(ev.Data, &chunk); err != nil {
+				yield(nil, fmt.Errorf("failed to decode stream chunk: %w", err))
+				return
+			}
+			if chunk.Error != nil {
+				apiErr := &APIError{Provider: provider, StatusCode: http.StatusOK}
+				chunk.Error.fill(apiErr)
+				yield(nil, apiErr)
+				return
+			}
+			if partial := aggregator.add(&chunk); partial != nil {
+				if !yield(partial, nil) {
+					return // Consumer stopped
+				}
+			}
+		}
+		final, err := aggregator.close()
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		yield(final, nil)
+	}
+}
+
+// do sends the request and returns the body of a successful response.
+func (m *openaiModel) do(ctx context.Context, req *model.LLMRequest, stream bool) (io.ReadCloser, error) {
+	chatReq, err := m.toChatRequest(req, stream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert request: %w", err)
+	}
+	header := http.Header{}
+	header.Set("User-Agent", m.versionHeaderValue)
+	if stream {
+		header.Set("Accept", "text/event-stream")
+	}
+	if m.apiKey != "" {
+		header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+	return httpmodel.Post(ctx, m.httpClient, &httpmodel.Request{
+		Provider:      provider,
+		URL:           m.baseURL + "/chat/completions",
+		Body:          chatReq,
+		DefaultHeader: m.headers,
+		Config:        req.Config,
+		Header:        header,
+		ParseError:    parseError,
+	})
+}
+
+// APIError is returned when the API responds with an error.
+type APIError = httpmodel.APIError
+
+const provider = "openai"
+
+func parseError(data []byte, apiErr *APIError) bool {
+	var body struct {
+		Error *apiErrorBody `json:"error"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil || body.Error == nil {
+		return false
+	}
+	body.Error.fill(apiErr)
+	return true
+}
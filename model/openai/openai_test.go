@@ -0,0 +1,308 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// newTestServer returns a server responding with respBody and storing the
+// decoded request body in gotReq.
+func newTestServer(t *testing.T, status int, contentType, respBody string, gotReq *map[string]any) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if got, want := r.Header.Get("Authorization"), "Bearer test-key"; got != want {
+			t.Errorf("Authorization header = %q, want %q", got, want)
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gotReq != nil {
+			if err := json.Unmarshal(data, gotReq); err != nil {
+				t.Fatal(err)
+			}
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, respBody)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestModel(t *testing.T, srv *httptest.Server) model.LLM {
+	t.Helper()
+	m, err := NewModel(t.Context(), "test-model", &ClientConfig{
+		BaseURL: srv.URL + "/v1/",
+		APIKey:  "test-key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestModel_Generate(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      *model.LLMRequest
+		respBody string
+		wantReq  map[string]any
+		want     *model.LLMResponse
+	}{
+		{
+			name: "text",
+			req: &model.LLMRequest{
+				Contents: genai.Text("What is the capital of France? One word."),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("Be brief.", genai.RoleUser),
+					Temperature:       new(float32),
+					MaxOutputTokens:   10,
+				},
+			},
+			respBody: `{
+				"id": "chatcmpl-1",
+				"choices": [{"index": 0, "message": {"role": "assistant", "content": "Paris"}, "finish_reason": "stop"}],
+				"usage": {"prompt_tokens": 10, "completion_tokens": 1, "total_tokens": 11}
+			}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "system", "content": "Be brief."},
+					map[string]any{"role": "user", "content": "What is the capital of France? One word."},
+				},
+				"temperature": float64(0),
+				"max_tokens":  float64(10),
+			},
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText("Paris", genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+				UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+					PromptTokenCount:     10,
+					CandidatesTokenCount: 1,
+					TotalTokenCount:      11,
+				},
+			},
+		},
+		{
+			name: "tools",
+			req: &model.LLMRequest{
+				Contents: []*genai.Content{
+					genai.NewContentFromText("Weather in Paris?", genai.RoleUser),
+					genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel),
+					genai.NewContentFromFunctionResponse("get_weather", map[string]any{"result": "sunny"}, genai.RoleUser),
+				},
+				Config: &genai.GenerateContentConfig{
+					Tools: []*genai.Tool{{FunctionDeclarations: []*genai.FunctionDeclaration{{
+						Name:        "get_weather",
+						Description: "Returns the weather.",
+						Parameters: &genai.Schema{
+							Type:       genai.TypeObject,
+							Properties: map[string]*genai.Schema{"city": {Type: genai.TypeString}},
+							Required:   []string{"city"},
+						},
+					}}}},
+				},
+			},
+			respBody: `{
+				"choices": [{"message": {"role": "assistant", "tool_calls": [
+					{"id": "call_abc", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Rome\"}"}}
+				]}, "finish_reason": "tool_calls"}]
+			}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": "Weather in Paris?"},
+					map[string]any{"role": "assistant", "tool_calls": []any{map[string]any{
+						"id":       "call_1",
+						"type":     "function",
+						"function": map[string]any{"name": "get_weather", "arguments": `{"city":"Paris"}`},
+					}}},
+					map[string]any{"role": "tool", "tool_call_id": "call_1", "content": `{"result":"sunny"}`},
+				},
+				"tools": []any{map[string]any{
+					"type": "function",
+					"function": map[string]any{
+						"name":        "get_weather",
+						"description": "Returns the weather.",
+						"parameters": map[string]any{
+							"type":       "object",
+							"properties": map[string]any{"city": map[string]any{"type": "string"}},
+							"required":   []any{"city"},
+						},
+					},
+				}},
+			},
+			want: &model.LLMResponse{
+				Content: genai.NewContentFromParts([]*genai.Part{{FunctionCall: &genai.FunctionCall{
+					ID:   "call_abc",
+					Name: "get_weather",
+					Args: map[string]any{"city": "Rome"},
+				}}}, genai.RoleModel),
+				FinishReason: genai.FinishReasonStop,
+			},
+		},
+		{
+			name: "output_schema",
+			req: &model.LLMRequest{
+				Contents: genai.Text("Capital of France?"),
+				Config: &genai.GenerateContentConfig{
+					ResponseSchema: &genai.Schema{
+						Type:       genai.TypeObject,
+						Properties: map[string]*genai.Schema{"capital": {Type: genai.TypeString}},
+					},
+				},
+			},
+			respBody: `{"choices": [{"message": {"content": "{\"capital\":\"Paris\"}"}, "finish_reason": "length"}]}`,
+			wantReq: map[string]any{
+				"model": "test-model",
+				"messages": []any{
+					map[string]any{"role": "user", "content": "Capital of France?"},
+				},
+				"response_format": map[string]any{
+					"type": "json_schema",
+					"json_schema": map[string]any{
+						"name": "response",
+						"schema": map[string]any{
+							"type":       "object",
+							"properties": map[string]any{"capital": map[string]any{"type": "string"}},
+						},
+					},
+				},
+			},
+			want: &model.LLMResponse{
+				Content:      genai.NewContentFromText(`{"capital":"Paris"}`, genai.RoleModel),
+				FinishReason: genai.FinishReasonMaxTokens,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotReq map[string]any
+			srv := newTestServer(t, http.StatusOK, "application/json", tt.respBody, &gotReq)
+
+			var got []*model.LLMResponse
+			for resp, err := range newTestModel(t, srv).GenerateContent(t.Context(), tt.req, false) {
+				if err != nil {
+					t.Fatalf("GenerateContent() error = %v", err)
+				}
+				got = append(got, resp)
+			}
+
+			if diff := cmp.Diff(tt.wantReq, gotReq); diff != "" {
+				t.Errorf("request mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]*model.LLMResponse{tt.want}, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModel_GenerateStream(t *testing.T) {
+	respBody := `data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Let me "}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":"check."}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":7,"total_tokens":12}}
+
+data: [DONE]
+
+`
+	var gotReq map[string]any
+	srv := newTestServer(t, http.StatusOK, "text/event-stream", respBody, &gotReq)
+
+	var got []*model.LLMResponse
+	for resp, err := range newTestModel(t, srv).GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("Weather in Paris?")}, true) {
+		if err != nil {
+			t.Fatalf("GenerateContent() error = %v", err)
+		}
+		got = append(got, resp)
+	}
+
+	if gotReq["stream"] != true {
+		t.Errorf("request stream = %v, want true", gotReq["stream"])
+	}
+	if diff := cmp.Diff(map[string]any{"include_usage": true}, gotReq["stream_options"]); diff != "" {
+		t.Errorf("request stream_options mismatch (-want +got):\n%s", diff)
+	}
+
+	want := []*model.LLMResponse{
+		{Content: genai.NewContentFromText("Let me ", genai.RoleModel), Partial: true},
+		{Content: genai.NewContentFromText("check.", genai.RoleModel), Partial: true},
+		{
+			Content: genai.NewContentFromParts([]*genai.Part{
+				genai.NewPartFromText("Let me check."),
+				{FunctionCall: &genai.FunctionCall{ID: "call_1", Name: "get_weather", Args: map[string]any{"city": "Paris"}}},
+			}, genai.RoleModel),
+			FinishReason: genai.FinishReasonStop,
+			UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+				PromptTokenCount:     5,
+				CandidatesTokenCount: 7,
+				TotalTokenCount:      12,
+			},
+			TurnComplete: true,
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModel_GenerateError(t *testing.T) {
+	srv := newTestServer(t, http.StatusTooManyRequests, "application/json",
+		`{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`, nil)
+
+	for _, stream := range []bool{false, true} {
+		for _, err := range newTestModel(t, srv).GenerateContent(t.Context(), &model.LLMRequest{Contents: genai.Text("hi")}, stream) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("GenerateContent(stream=%v) error = %v, want *APIError", stream, err)
+			}
+			want := &APIError{
+				Provider:   "openai",
+				StatusCode: http.StatusTooManyRequests,
+				Type:       "requests",
+				Code:       "rate_limit_exceeded",
+				Message:    "Rate limit reached",
+			}
+			if diff := cmp.Diff(want, apiErr); diff != "" {
+				t.Errorf("GenerateContent(stream=%v) error mismatch (-want +got):\n%s", stream, diff)
+			}
+		}
+	}
+}
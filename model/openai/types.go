@@ -0,0 +1,155 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Wire types of the Chat Completions API.
+// Only the subset of fields used by the adapter is declared.
+
+type chatRequest struct {
+	Model            string          `json:"model"`
+	Messages         []*chatMessage  `json:"messages"`
+	Tools            []*chatTool     `json:"tools,omitempty"`
+	ToolChoice       any             `json:"tool_choice,omitempty"`
+	Temperature      *float32        `json:"temperature,omitempty"`
+	TopP             *float32        `json:"top_p,omitempty"`
+	MaxTokens        int32           `json:"max_tokens,omitempty"`
+	N                int32           `json:"n,omitempty"`
+	Stop             []string        `json:"stop,omitempty"`
+	Seed             *int32          `json:"seed,omitempty"`
+	PresencePenalty  *float32        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float32        `json:"frequency_penalty,omitempty"`
+	ResponseFormat   *responseFormat `json:"response_format,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+	StreamOptions    *streamOptions  `json:"stream_options,omitempty"`
+}
+
+type chatMessage struct {
+	Role string `json:"role"`
+	// Content is either a string or a list of contentPart.
+	Content    any         `json:"content,omitempty"`
+	ToolCalls  []*toolCall `json:"tool_calls,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+}
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type chatTool struct {
+	Type     string              `json:"type"`
+	Function *functionDefinition `json:"function"`
+}
+
+type functionDefinition struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Parameters  any    `json:"parameters,omitempty"`
+}
+
+type toolCall struct {
+	// Index is only set in streaming chunks.
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function functionCall `json:"function"`
+}
+
+type functionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string `json:"name"`
+	Schema any    `json:"schema"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// chatResponse is both a complete response and a streaming chunk.
+type chatResponse struct {
+	ID      string        `json:"id"`
+	Model   string        `json:"model"`
+	Choices []*chatChoice `json:"choices"`
+	Usage   *usage        `json:"usage"`
+	// Error is set by some servers when a failure happens mid-stream.
+	Error *apiErrorBody `json:"error"`
+}
+
+type chatChoice struct {
+	Index int `json:"index"`
+	// Message is set in complete responses.
+	Message *responseMessage `json:"message"`
+	// Delta is set in streaming chunks.
+	Delta        *responseMessage `json:"delta"`
+	FinishReason string           `json:"finish_reason"`
+}
+
+type responseMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// ReasoningContent is an extension used by reasoning models served by
+	// vLLM and similar servers.
+	ReasoningContent string      `json:"reasoning_content"`
+	Refusal          string      `json:"refusal"`
+	ToolCalls        []*toolCall `json:"tool_calls"`
+}
+
+type usage struct {
+	PromptTokens        int32 `json:"prompt_tokens"`
+	CompletionTokens    int32 `json:"completion_tokens"`
+	TotalTokens         int32 `json:"total_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens int32 `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int32 `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
+
+type apiErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	// Code is a string for OpenAI, but some servers report a number.
+	Code json.RawMessage `json:"code"`
+}
+
+// fill sets the fields of the error reported by the API.
+func (b *apiErrorBody) fill(e *APIError) {
+	e.Type = b.Type
+	e.Message = b.Message
+	var code any
+	if err := json.Unmarshal(b.Code, &code); err == nil && code != nil {
+		e.Code = fmt.Sprint(code)
+	}
+}
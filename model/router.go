@@ -0,0 +1,229 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"maps"
+	"net/http"
+	"slices"
+
+	"google.golang.org/genai"
+)
+
+// ModelNameMetadataKey is the key of LLMResponse.CustomMetadata under which
+// the models returned by NewFallback and NewRouter record the name of the
+// model that served the request.
+const ModelNameMetadataKey = "adk_model_name"
+
+// FallbackConfig is the configuration of the model returned by NewFallback.
+type FallbackConfig struct {
+	// Models are tried in order until one of them succeeds. Required.
+	Models []LLM
+	// ShouldFallback reports whether the next model should be tried after a
+	// model failed with err. If nil, IsRetryable is used.
+	ShouldFallback func(err error) bool
+}
+
+// NewFallback returns an LLM calling the first of cfg.Models and falling back
+// to the next one on retryable errors, such as rate limiting or server errors.
+//
+// In streaming mode, the next model is only tried if the failed one didn't
+// yield any response yet, so no duplicate partial responses are returned.
+// The name of the model that served the request is recorded in the
+// CustomMetadata of every response under ModelNameMetadataKey.
+//
+// The Name of the returned LLM is the name of the first model.
+func NewFallback(cfg FallbackConfig) (LLM, error) {
+	if len(cfg.Models) == 0 {
+		return nil, fmt.Errorf("at least one model is required")
+	}
+	if slices.Contains(cfg.Models, nil) {
+		return nil, fmt.Errorf("models must not be nil")
+	}
+	shouldFallback := cfg.ShouldFallback
+	if shouldFallback == nil {
+		shouldFallback = IsRetryable
+	}
+	return &fallbackModel{
+		models:         slices.Clone(cfg.Models),
+		shouldFallback: shouldFallback,
+	}, nil
+}
+
+type fallbackModel struct {
+	models         []LLM
+	shouldFallback func(error) bool
+}
+
+func (f *fallbackModel) Name() string {
+	return f.models[0].Name()
+}
+
+func (f *fallbackModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		for i, m := range f.models {
+			var (
+				failure  error
+				yielded  bool
+				consumed = true
+			)
+			for resp, err := range m.GenerateContent(ctx, cloneRequest(req, m.Name()), stream) {
+				if err != nil {
+					failure = err
+					break
+				}
+				yielded = true
+				if !yield(withModelName(resp, m.Name()), nil) {
+					consumed = false
+					break
+				}
+			}
+			if !consumed || failure == nil {
+				return
+			}
+			// Don't fall back if the caller's context is done, since the next
+			// model would fail the same way.
+			if yielded || i == len(f.models)-1 || ctx.Err() != nil || !f.shouldFallback(failure) {
+				yield(nil, failure)
+				return
+			}
+		}
+	}
+}
+
+// RouterConfig is the configuration of the model returned by NewRouter.
+type RouterConfig struct {
+	// Name is returned by the Name method of the router.
+	// If empty, "router" is used.
+	Name string
+	// Select returns the model to serve the request. Required.
+	//
+	// When the router is the model of an llmagent, ctx is the
+	// agent.InvocationContext of the call, which allows routing by agent name.
+	Select func(ctx context.Context, req *LLMRequest) (LLM, error)
+}
+
+// NewRouter returns an LLM forwarding every request to the model chosen by
+// cfg.Select, e.g. based on the estimated size of the prompt.
+//
+// The name of the model that served the request is recorded in the
+// CustomMetadata of every response under ModelNameMetadataKey.
+func NewRouter(cfg RouterConfig) (LLM, error) {
+	if cfg.Select == nil {
+		return nil, fmt.Errorf("select function is required")
+	}
+	name := cfg.Name
+	if name == "" {
+		name = "router"
+	}
+	return &routerModel{name: name, selectModel: cfg.Select}, nil
+}
+
+type routerModel struct {
+	name        string
+	selectModel func(ctx context.Context, req *LLMRequest) (LLM, error)
+}
+
+func (r *routerModel) Name() string {
+	return r.name
+}
+
+func (r *routerModel) GenerateContent(ctx context.Context, req *LLMRequest, stream bool) iter.Seq2[*LLMResponse, error] {
+	return func(yield func(*LLMResponse, error) bool) {
+		m, err := r.selectModel(ctx, req)
+		if err != nil {
+			yield(nil, fmt.Errorf("failed to select model: %w", err))
+			return
+		}
+		if m == nil {
+			yield(nil, fmt.Errorf("failed to select model: no model selected"))
+			return
+		}
+		for resp, err := range m.GenerateContent(ctx, cloneRequest(req, m.Name()), stream) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(withModelName(resp, m.Name()), nil) {
+				return
+			}
+		}
+	}
+}
+
+// statusCoder is implemented by the errors of model implementations that
+// carry the HTTP status code of the failed call.
+type statusCoder interface {
+	HTTPStatusCode() int
+}
+
+// IsRetryable reports whether err is a transient failure of a model call:
+// rate limiting (HTTP 429), a server error (HTTP 5xx) or an exceeded deadline.
+//
+// The status code is taken from [genai.APIError] or from errors implementing
+// an HTTPStatusCode() int method.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	code := 0
+	var sc statusCoder
+	var apiErr genai.APIError
+	switch {
+	case errors.As(err, &sc):
+		code = sc.HTTPStatusCode()
+	case errors.As(err, &apiErr):
+		code = apiErr.Code
+	}
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// cloneRequest returns a copy of req for the named model, so that changes
+// made by one model to the request don't leak to the next one.
+func cloneRequest(req *LLMRequest, name string) *LLMRequest {
+	clone := *req
+	clone.Model = name
+	clone.Contents = slices.Clone(req.Contents)
+	if req.Config != nil {
+		cfg := *req.Config
+		if cfg.HTTPOptions != nil {
+			opts := *cfg.HTTPOptions
+			opts.Headers = opts.Headers.Clone()
+			cfg.HTTPOptions = &opts
+		}
+		clone.Config = &cfg
+	}
+	return &clone
+}
+
+func withModelName(resp *LLMResponse, name string) *LLMResponse {
+	if resp == nil {
+		return nil
+	}
+	clone := *resp
+	clone.CustomMetadata = maps.Clone(resp.CustomMetadata)
+	if clone.CustomMetadata == nil {
+		clone.CustomMetadata = make(map[string]any)
+	}
+	clone.CustomMetadata[ModelNameMetadataKey] = name
+	return &clone
+}
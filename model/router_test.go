@@ -0,0 +1,227 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+// fakeLLM yields its responses and then its error, if any.
+type fakeLLM struct {
+	name      string
+	responses []*model.LLMResponse
+	err       error
+
+	calls int
+	req   *model.LLMRequest
+}
+
+func (f *fakeLLM) Name() string { return f.name }
+
+func (f *fakeLLM) GenerateContent(ctx context.Context, req *model.LLMRequest, stream bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(yield func(*model.LLMResponse, error) bool) {
+		f.calls++
+		f.req = req
+		for _, resp := range f.responses {
+			if !yield(resp, nil) {
+				return
+			}
+		}
+		if f.err != nil {
+			yield(nil, f.err)
+		}
+	}
+}
+
+type statusError int
+
+func (e statusError) Error() string       { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) HTTPStatusCode() int { return int(e) }
+
+func text(s string) *model.LLMResponse {
+	return &model.LLMResponse{Content: genai.NewContentFromText(s, genai.RoleModel)}
+}
+
+func served(s, name string) *model.LLMResponse {
+	resp := text(s)
+	resp.CustomMetadata = map[string]any{model.ModelNameMetadataKey: name}
+	return resp
+}
+
+func collect(llm model.LLM, req *model.LLMRequest) ([]*model.LLMResponse, error) {
+	var got []*model.LLMResponse
+	for resp, err := range llm.GenerateContent(context.Background(), req, true) {
+		if err != nil {
+			return got, err
+		}
+		got = append(got, resp)
+	}
+	return got, nil
+}
+
+func TestFallback(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   *fakeLLM
+		secondary *fakeLLM
+		want      []*model.LLMResponse
+		wantErr   error
+		wantCalls []int
+	}{
+		{
+			name:      "primary succeeds",
+			primary:   &fakeLLM{name: "a", responses: []*model.LLMResponse{text("hi")}},
+			secondary: &fakeLLM{name: "b", responses: []*model.LLMResponse{text("unused")}},
+			want:      []*model.LLMResponse{served("hi", "a")},
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "rate limited",
+			primary:   &fakeLLM{name: "a", err: statusError(http.StatusTooManyRequests)},
+			secondary: &fakeLLM{name: "b", responses: []*model.LLMResponse{text("hi")}},
+			want:      []*model.LLMResponse{served("hi", "b")},
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "genai server error",
+			primary:   &fakeLLM{name: "a", err: fmt.Errorf("failed to call model: %w", genai.APIError{Code: http.StatusServiceUnavailable})},
+			secondary: &fakeLLM{name: "b", responses: []*model.LLMResponse{text("hi")}},
+			want:      []*model.LLMResponse{served("hi", "b")},
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "deadline exceeded",
+			primary:   &fakeLLM{name: "a", err: context.DeadlineExceeded},
+			secondary: &fakeLLM{name: "b", responses: []*model.LLMResponse{text("hi")}},
+			want:      []*model.LLMResponse{served("hi", "b")},
+			wantCalls: []int{1, 1},
+		},
+		{
+			name:      "not retryable",
+			primary:   &fakeLLM{name: "a", err: statusError(http.StatusBadRequest)},
+			secondary: &fakeLLM{name: "b", responses: []*model.LLMResponse{text("unused")}},
+			wantErr:   statusError(http.StatusBadRequest),
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "failure after partial response",
+			primary:   &fakeLLM{name: "a", responses: []*model.LLMResponse{text("h")}, err: statusError(http.StatusInternalServerError)},
+			secondary: &fakeLLM{name: "b", responses: []*model.LLMResponse{text("unused")}},
+			want:      []*model.LLMResponse{served("h", "a")},
+			wantErr:   statusError(http.StatusInternalServerError),
+			wantCalls: []int{1, 0},
+		},
+		{
+			name:      "all fail",
+			primary:   &fakeLLM{name: "a", err: statusError(http.StatusInternalServerError)},
+			secondary: &fakeLLM{name: "b", err: statusError(http.StatusBadGateway)},
+			wantErr:   statusError(http.StatusBadGateway),
+			wantCalls: []int{1, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llm, err := model.NewFallback(model.FallbackConfig{Models: []model.LLM{tt.primary, tt.secondary}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := llm.Name(), "a"; got != want {
+				t.Errorf("Name() = %q, want %q", got, want)
+			}
+
+			got, err := collect(llm, &model.LLMRequest{Model: "a"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GenerateContent() error = %v, want %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantCalls, []int{tt.primary.calls, tt.secondary.calls}); diff != "" {
+				t.Errorf("calls mismatch (-want +got):\n%s", diff)
+			}
+			if tt.secondary.req != nil && tt.secondary.req.Model != "b" {
+				t.Errorf("secondary request model = %q, want %q", tt.secondary.req.Model, "b")
+			}
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	small := &fakeLLM{name: "small", responses: []*model.LLMResponse{text("small")}}
+	large := &fakeLLM{name: "large", responses: []*model.LLMResponse{text("large")}}
+	llm, err := model.NewRouter(model.RouterConfig{
+		Select: func(ctx context.Context, req *model.LLMRequest) (model.LLM, error) {
+			if len(req.Contents) > 1 {
+				return large, nil
+			}
+			return small, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := collect(llm, &model.LLMRequest{Contents: genai.Text("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*model.LLMResponse{served("small", "small")}, got); diff != "" {
+		t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = collect(llm, &model.LLMRequest{Contents: []*genai.Content{
+		genai.NewContentFromText("hi", genai.RoleUser),
+		genai.NewContentFromText("hello", genai.RoleModel),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]*model.LLMResponse{served("large", "large")}, got); diff != "" {
+		t.Errorf("GenerateContent() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("boom"), false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true},
+		{statusError(http.StatusTooManyRequests), true},
+		{statusError(http.StatusInternalServerError), true},
+		{statusError(http.StatusNotFound), false},
+		{genai.APIError{Code: http.StatusTooManyRequests}, true},
+		{genai.APIError{Code: http.StatusForbidden}, false},
+	}
+	for _, tt := range tests {
+		if got := model.IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/genai"
+)
+
+// TokenCounter is implemented by models that can count the input tokens of a
+// request, typically by calling a dedicated endpoint of the provider.
+type TokenCounter interface {
+	CountTokens(ctx context.Context, req *LLMRequest) (int, error)
+}
+
+// CountTokens returns the number of input tokens of req for the given model.
+//
+// If llm implements [TokenCounter], its count is returned. Otherwise the
+// count is estimated with [EstimateTokens].
+func CountTokens(ctx context.Context, llm LLM, req *LLMRequest) (int, error) {
+	if c, ok := llm.(TokenCounter); ok {
+		return c.CountTokens(ctx, req)
+	}
+	return EstimateTokens(req), nil
+}
+
+const (
+	// charsPerToken is the average number of characters of a token for
+	// English text, as commonly observed across tokenizers.
+	charsPerToken = 4
+	// blobTokens is the estimated cost of inline or file data. It matches the
+	// fixed cost of an image for Gemini models.
+	blobTokens = 258
+	// contentTokens is the estimated overhead of a content (role, separators).
+	contentTokens = 4
+)
+
+// EstimateTokens returns a heuristic estimate of the number of input tokens of
+// req, including the system instruction and tool declarations.
+//
+// The estimate assumes about four characters per token for text and
+// serialized function calls and responses, and a fixed cost per blob.
+// It is meant for budgeting when the model can't count tokens itself, and may
+// differ noticeably from the count of the actual tokenizer.
+func EstimateTokens(req *LLMRequest) int {
+	if req == nil {
+		return 0
+	}
+	n := 0
+	for _, c := range req.Contents {
+		n += EstimateContentTokens(c)
+	}
+	if req.Config != nil {
+		n += EstimateContentTokens(req.Config.SystemInstruction)
+		if len(req.Config.Tools) > 0 {
+			n += jsonTokens(req.Config.Tools)
+		}
+	}
+	return n
+}
+
+// EstimateContentTokens returns a heuristic estimate of the number of tokens
+// of c. See [EstimateTokens].
+func EstimateContentTokens(c *genai.Content) int {
+	if c == nil {
+		return 0
+	}
+	n := contentTokens
+	for _, p := range c.Parts {
+		n += estimatePartTokens(p)
+	}
+	return n
+}
+
+func estimatePartTokens(p *genai.Part) int {
+	if p == nil {
+		return 0
+	}
+	n := textTokens(p.Text)
+	if p.InlineData != nil || p.FileData != nil {
+		n += blobTokens
+	}
+	if p.FunctionCall != nil {
+		n += textTokens(p.FunctionCall.Name) + jsonTokens(p.FunctionCall.Args)
+	}
+	if p.FunctionResponse != nil {
+		n += textTokens(p.FunctionResponse.Name) + jsonTokens(p.FunctionResponse.Response)
+	}
+	if p.ExecutableCode != nil {
+		n += textTokens(p.ExecutableCode.Code)
+	}
+	if p.CodeExecutionResult != nil {
+		n += textTokens(p.CodeExecutionResult.Output)
+	}
+	return n
+}
+
+func textTokens(s string) int {
+	return (len(s) + charsPerToken - 1) / charsPerToken
+}
+
+func jsonTokens(v any) int {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return 0
+	}
+	return textTokens(string(data))
+}
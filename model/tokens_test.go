@@ -0,0 +1,127 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *model.LLMRequest
+		want int
+	}{
+		{
+			name: "nil",
+			want: 0,
+		},
+		{
+			name: "text",
+			req:  &model.LLMRequest{Contents: genai.Text(strings.Repeat("a", 40))},
+			want: 4 + 10,
+		},
+		{
+			name: "blob",
+			req: &model.LLMRequest{Contents: []*genai.Content{
+				genai.NewContentFromBytes([]byte("png"), "image/png", genai.RoleUser),
+			}},
+			want: 4 + 258,
+		},
+		{
+			name: "function call and response",
+			req: &model.LLMRequest{Contents: []*genai.Content{
+				genai.NewContentFromFunctionCall("tool", map[string]any{"a": "b"}, genai.RoleModel),     // {"a":"b"}
+				genai.NewContentFromFunctionResponse("tool", map[string]any{"r": "ok"}, genai.RoleUser), // {"r":"ok"}
+			}},
+			want: 2 * (4 + 1 + 3),
+		},
+		{
+			name: "system instruction",
+			req: &model.LLMRequest{
+				Contents: genai.Text("abcd"),
+				Config: &genai.GenerateContentConfig{
+					SystemInstruction: genai.NewContentFromText("abcdefgh", genai.RoleUser),
+				},
+			},
+			want: (4 + 1) + (4 + 2),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.EstimateTokens(tt.req); got != tt.want {
+				t.Errorf("EstimateTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+type countingModel struct {
+	count int
+	err   error
+}
+
+func (m *countingModel) Name() string { return "counting" }
+
+func (m *countingModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(func(*model.LLMResponse, error) bool) {}
+}
+
+func (m *countingModel) CountTokens(context.Context, *model.LLMRequest) (int, error) {
+	return m.count, m.err
+}
+
+type plainModel struct{}
+
+func (plainModel) Name() string { return "plain" }
+
+func (plainModel) GenerateContent(context.Context, *model.LLMRequest, bool) iter.Seq2[*model.LLMResponse, error] {
+	return func(func(*model.LLMResponse, error) bool) {}
+}
+
+func TestCountTokens(t *testing.T) {
+	req := &model.LLMRequest{Contents: genai.Text("abcd")}
+	errCount := errors.New("count failed")
+
+	tests := []struct {
+		name    string
+		llm     model.LLM
+		want    int
+		wantErr error
+	}{
+		{name: "counter", llm: &countingModel{count: 42}, want: 42},
+		{name: "counter error", llm: &countingModel{err: errCount}, wantErr: errCount},
+		{name: "estimate", llm: plainModel{}, want: model.EstimateTokens(req)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.CountTokens(t.Context(), tt.llm, req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CountTokens() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("CountTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
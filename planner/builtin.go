@@ -0,0 +1,50 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+// BuiltIn is a planner using the built-in thinking features of the model.
+// The model must support thinking, e.g. Gemini 2.5 models.
+type BuiltIn struct {
+	// ThinkingConfig is set in the config of every request, replacing the
+	// thinking config of the agent, if any. If nil, the request is left
+	// unchanged.
+	ThinkingConfig *genai.ThinkingConfig
+}
+
+// BuildPlanningInstruction implements Planner. It applies the thinking
+// config to the request and returns no instruction.
+func (p BuiltIn) BuildPlanningInstruction(ctx agent.ReadonlyContext, req *model.LLMRequest) (string, error) {
+	if p.ThinkingConfig == nil {
+		return "", nil
+	}
+	if req.Config == nil {
+		req.Config = &genai.GenerateContentConfig{}
+	}
+	req.Config.ThinkingConfig = p.ThinkingConfig
+	return "", nil
+}
+
+// ProcessPlanningResponse implements Planner. The model already marks its
+// thoughts, so the parts are kept unchanged.
+func (p BuiltIn) ProcessPlanningResponse(ctx agent.ReadonlyContext, parts []*genai.Part) ([]*genai.Part, error) {
+	return nil, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package planner defines the interface for planners that let an LLM agent
+// plan its actions before executing them, and provides the built-in
+// implementations:
+//   - [BuiltIn] uses the thinking capabilities of the model.
+//   - [PlanReAct] instructs the model to write down an explicit plan, its
+//     reasoning and its actions before giving a final answer.
+//
+// Planners are set in the Planner field of llmagent.Config.
+package planner
+
+import (
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+// Planner guides the model through planning and processes the planning
+// parts of its responses.
+type Planner interface {
+	// BuildPlanningInstruction returns the planning instruction appended to
+	// the system instruction of the request, or an empty string if there is
+	// none. It can also configure the request, e.g. enable the thinking of
+	// the model.
+	BuildPlanningInstruction(ctx agent.ReadonlyContext, req *model.LLMRequest) (string, error)
+	// ProcessPlanningResponse returns the parts replacing the parts of a
+	// model response, or nil to keep them unchanged. Planning parts should be
+	// marked as thoughts, so they are neither shown to users nor sent back to
+	// the model in later requests.
+	ProcessPlanningResponse(ctx agent.ReadonlyContext, parts []*genai.Part) ([]*genai.Part, error)
+}
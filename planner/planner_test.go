@@ -0,0 +1,185 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/planner"
+)
+
+func TestBuiltIn_BuildPlanningInstruction(t *testing.T) {
+	thinking := &genai.ThinkingConfig{IncludeThoughts: true, ThinkingBudget: genai.Ptr[int32](1024)}
+
+	testCases := []struct {
+		name    string
+		planner planner.BuiltIn
+		req     *model.LLMRequest
+		want    *genai.GenerateContentConfig
+	}{
+		{
+			name:    "no config",
+			planner: planner.BuiltIn{ThinkingConfig: thinking},
+			req:     &model.LLMRequest{},
+			want:    &genai.GenerateContentConfig{ThinkingConfig: thinking},
+		},
+		{
+			name:    "replaces thinking config",
+			planner: planner.BuiltIn{ThinkingConfig: thinking},
+			req: &model.LLMRequest{Config: &genai.GenerateContentConfig{
+				Temperature:    genai.Ptr[float32](0.5),
+				ThinkingConfig: &genai.ThinkingConfig{ThinkingBudget: genai.Ptr[int32](0)},
+			}},
+			want: &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.5), ThinkingConfig: thinking},
+		},
+		{
+			name:    "nil thinking config",
+			planner: planner.BuiltIn{},
+			req:     &model.LLMRequest{Config: &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.5)}},
+			want:    &genai.GenerateContentConfig{Temperature: genai.Ptr[float32](0.5)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			instruction, err := tc.planner.BuildPlanningInstruction(nil, tc.req)
+			if err != nil {
+				t.Fatalf("BuildPlanningInstruction() error = %v", err)
+			}
+			if instruction != "" {
+				t.Errorf("BuildPlanningInstruction() = %q, want empty", instruction)
+			}
+			if diff := cmp.Diff(tc.want, tc.req.Config); diff != "" {
+				t.Errorf("request config mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPlanReAct_BuildPlanningInstruction(t *testing.T) {
+	instruction, err := planner.PlanReAct{}.BuildPlanningInstruction(nil, &model.LLMRequest{})
+	if err != nil {
+		t.Fatalf("BuildPlanningInstruction() error = %v", err)
+	}
+	for _, tag := range []string{planner.PlanningTag, planner.ReplanningTag, planner.ReasoningTag, planner.ActionTag, planner.FinalAnswerTag} {
+		if !strings.Contains(instruction, tag) {
+			t.Errorf("BuildPlanningInstruction() doesn't mention %s", tag)
+		}
+	}
+}
+
+func TestPlanReAct_ProcessPlanningResponse(t *testing.T) {
+	call := func(name string) *genai.Part {
+		return &genai.Part{FunctionCall: &genai.FunctionCall{Name: name}}
+	}
+	thought := func(text string) *genai.Part {
+		return &genai.Part{Text: text, Thought: true}
+	}
+
+	testCases := []struct {
+		name  string
+		parts []*genai.Part
+		want  []*genai.Part
+	}{
+		{
+			name: "no parts",
+		},
+		{
+			name: "tagged parts",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*PLANNING*/ 1. Search."),
+				genai.NewPartFromText("/*REASONING*/ Found it."),
+				genai.NewPartFromText("untagged"),
+			},
+			want: []*genai.Part{
+				thought("/*PLANNING*/ 1. Search."),
+				thought("/*REASONING*/ Found it."),
+				genai.NewPartFromText("untagged"),
+			},
+		},
+		{
+			name: "final answer",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*REASONING*/ Done. /*FINAL_ANSWER*/ Paris."),
+			},
+			want: []*genai.Part{
+				thought("/*REASONING*/ Done. /*FINAL_ANSWER*/"),
+				genai.NewPartFromText(" Paris."),
+			},
+		},
+		{
+			name: "split after last final answer tag",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*FINAL_ANSWER*/ draft /*FINAL_ANSWER*/Paris."),
+			},
+			want: []*genai.Part{
+				thought("/*FINAL_ANSWER*/ draft /*FINAL_ANSWER*/"),
+				genai.NewPartFromText("Paris."),
+			},
+		},
+		{
+			name: "final answer tag only",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*FINAL_ANSWER*/"),
+			},
+			want: []*genai.Part{
+				thought("/*FINAL_ANSWER*/"),
+			},
+		},
+		{
+			name: "first group of function calls",
+			parts: []*genai.Part{
+				genai.NewPartFromText("/*ACTION*/"),
+				call("search"),
+				call(""),
+				call("lookup"),
+				genai.NewPartFromText("/*REASONING*/ hallucinated"),
+				call("search"),
+			},
+			want: []*genai.Part{
+				thought("/*ACTION*/"),
+				call("search"),
+				call("lookup"),
+			},
+		},
+		{
+			name: "function call without name",
+			parts: []*genai.Part{
+				call(""),
+				call("search"),
+			},
+			want: []*genai.Part{
+				call("search"),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := planner.PlanReAct{}.ProcessPlanningResponse(nil, tc.parts)
+			if err != nil {
+				t.Fatalf("ProcessPlanningResponse() error = %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("ProcessPlanningResponse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
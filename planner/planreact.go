@@ -0,0 +1,134 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package planner
+
+import (
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent"
+	"google.golang.org/adk/model"
+)
+
+// Tags of the sections of the responses written by models following the
+// PlanReAct instruction.
+const (
+	PlanningTag    = "/*PLANNING*/"
+	ReplanningTag  = "/*REPLANNING*/"
+	ReasoningTag   = "/*REASONING*/"
+	ActionTag      = "/*ACTION*/"
+	FinalAnswerTag = "/*FINAL_ANSWER*/"
+)
+
+// PlanReAct is a planner instructing the model to make a plan, then to
+// execute it with tools, interleaving its actions with reasoning, and to
+// finish with a final answer.
+//
+// Unlike BuiltIn, it doesn't require the model to support thinking. The
+// planning, reasoning and action sections of the responses are marked as
+// thoughts, so only the final answer and the function calls are kept.
+type PlanReAct struct{}
+
+// BuildPlanningInstruction implements Planner.
+func (p PlanReAct) BuildPlanningInstruction(ctx agent.ReadonlyContext, req *model.LLMRequest) (string, error) {
+	return planReActInstruction, nil
+}
+
+// ProcessPlanningResponse implements Planner.
+//
+// The text parts before the first function call are split into thoughts and
+// answer: a part starting with a planning, reasoning or action tag is a
+// thought, and a part containing the final answer tag is split after the last
+// occurrence of the tag. Only the first group of function calls is kept, and
+// function calls without names are dropped.
+func (p PlanReAct) ProcessPlanningResponse(ctx agent.ReadonlyContext, parts []*genai.Part) ([]*genai.Part, error) {
+	if len(parts) == 0 {
+		return nil, nil
+	}
+	var processed []*genai.Part
+	for i, part := range parts {
+		if part.FunctionCall == nil {
+			processed = append(processed, splitPlanningPart(part)...)
+			continue
+		}
+		if part.FunctionCall.Name == "" {
+			continue
+		}
+		// Stop at the first group of function calls.
+		processed = append(processed, part)
+		for _, next := range parts[i+1:] {
+			if next.FunctionCall == nil {
+				break
+			}
+			if next.FunctionCall.Name != "" {
+				processed = append(processed, next)
+			}
+		}
+		break
+	}
+	return processed, nil
+}
+
+// splitPlanningPart returns the part split into its thought and answer
+// parts.
+func splitPlanningPart(part *genai.Part) []*genai.Part {
+	if part.Text == "" || part.Thought {
+		return []*genai.Part{part}
+	}
+	if i := strings.LastIndex(part.Text, FinalAnswerTag); i >= 0 {
+		reasoning, answer := part.Text[:i+len(FinalAnswerTag)], part.Text[i+len(FinalAnswerTag):]
+		var parts []*genai.Part
+		if reasoning != "" {
+			parts = append(parts, &genai.Part{Text: reasoning, Thought: true})
+		}
+		if answer != "" {
+			parts = append(parts, &genai.Part{Text: answer})
+		}
+		return parts
+	}
+	for _, tag := range []string{PlanningTag, ReplanningTag, ReasoningTag, ActionTag} {
+		if strings.HasPrefix(part.Text, tag) {
+			thought := *part
+			thought.Thought = true
+			return []*genai.Part{&thought}
+		}
+	}
+	return []*genai.Part{part}
+}
+
+const planReActInstruction = `When answering the question, try to leverage the available tools to gather the information instead of your memorized knowledge.
+
+Follow this process when answering the question: (1) first come up with a plan in natural language text format; (2) Then use tools to execute the plan and provide reasoning between tool calls to make a summary of current state and next step. Tool calls and reasoning should be interleaved with each other. (3) In the end, return one final answer.
+
+Follow this format when answering the question: (1) The planning part should be under ` + PlanningTag + `. (2) The tool calls should be under ` + ActionTag + `, and the reasoning parts should be under ` + ReasoningTag + `. (3) The final answer part should be under ` + FinalAnswerTag + `.
+
+Below are the requirements for the planning:
+The plan is made to answer the user query if following the plan. The plan is coherent and covers all aspects of information from user query, and only involves the tools that are accessible by the agent. The plan contains the decomposed steps as a numbered list where each step should use one or multiple available tools. By reading the plan, you can intuitively know which tools to trigger or what actions to take.
+If the initial plan cannot be successfully executed, you should learn from previous execution results and revise your plan. The revised plan should be under ` + ReplanningTag + `. Then use tools to follow the new plan.
+
+Below are the requirements for the reasoning:
+The reasoning makes a summary of the current trajectory based on the user query and tool outputs. Based on the tool outputs and plan, the reasoning also comes up with instructions to the next steps, making the trajectory closer to the final answer.
+
+Below are the requirements for the final answer:
+The final answer should be precise and follow query formatting requirements. Some queries may not be answerable with the available tools and information. In those cases, inform the user why you cannot process their query and ask for more information.
+
+Below are the requirements for the tool calls:
+The available tools are described in the context and can be directly called. You cannot use any parameters or fields that are not explicitly defined in the tool declarations. The tool calls should be directly relevant to the user query and reasoning steps.
+
+VERY IMPORTANT instruction that you MUST follow in addition to the above instructions:
+
+You should ask for clarification if you need more information to answer the question.
+You should prefer using the information available in the context instead of repeated tool use.`
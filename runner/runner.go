@@ -16,28 +16,38 @@
 package runner
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"iter"
 	"log"
+	"slices"
+	"strings"
 	"time"
 
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/auth"
+	agentinternal "google.golang.org/adk/internal/agent"
 	"google.golang.org/adk/internal/agent/parentmap"
 	"google.golang.org/adk/internal/agent/runconfig"
 	artifactinternal "google.golang.org/adk/internal/artifact"
 	icontext "google.golang.org/adk/internal/context"
+	"google.golang.org/adk/internal/credential"
 	"google.golang.org/adk/internal/llminternal"
 	imemory "google.golang.org/adk/internal/memory"
 	"google.golang.org/adk/internal/plugininternal"
 	"google.golang.org/adk/internal/sessioninternal"
+	"google.golang.org/adk/internal/utils"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/plugin"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/compaction"
 )
 
 // Config is used to create a [Runner].
@@ -51,8 +61,13 @@ type Config struct {
 	ArtifactService artifact.Service
 	// optional
 	MemoryService memory.Service
+	// optional, stores the credentials of the users requested by tools.
+	// If nil, the credentials are kept in memory.
+	CredentialService auth.CredentialService
 	// optional
 	PluginConfig PluginConfig
+	// optional, compacts the session events at the end of each invocation.
+	Compaction *compaction.Config
 }
 
 type PluginConfig struct {
@@ -75,6 +90,12 @@ func New(cfg Config) (*Runner, error) {
 		return nil, fmt.Errorf("failed to create agent tree: %w", err)
 	}
 
+	if cfg.Compaction != nil {
+		if err := cfg.Compaction.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid compaction config: %w", err)
+		}
+	}
+
 	pluginManager, err := plugininternal.NewPluginManager(plugininternal.PluginConfig{
 		Plugins:      cfg.PluginConfig.Plugins,
 		CloseTimeout: cfg.PluginConfig.CloseTimeout,
@@ -83,14 +104,21 @@ func New(cfg Config) (*Runner, error) {
 		return nil, fmt.Errorf("failed to create plugin manager: %w", err)
 	}
 
+	credentialService := cfg.CredentialService
+	if credentialService == nil {
+		credentialService = auth.InMemoryCredentialService()
+	}
+
 	return &Runner{
-		appName:         cfg.AppName,
-		rootAgent:       cfg.Agent,
-		sessionService:  cfg.SessionService,
-		artifactService: cfg.ArtifactService,
-		memoryService:   cfg.MemoryService,
-		parents:         parents,
-		pluginManager:   pluginManager,
+		appName:           cfg.AppName,
+		rootAgent:         cfg.Agent,
+		sessionService:    cfg.SessionService,
+		artifactService:   cfg.ArtifactService,
+		memoryService:     cfg.MemoryService,
+		credentialService: credentialService,
+		parents:           parents,
+		pluginManager:     pluginManager,
+		compaction:        cfg.Compaction,
 	}, nil
 }
 
@@ -98,14 +126,16 @@ func New(cfg Config) (*Runner, error) {
 // processing, event generation, and interaction with various services like
 // artifact storage, session management, and memory.
 type Runner struct {
-	appName         string
-	rootAgent       agent.Agent
-	sessionService  session.Service
-	artifactService artifact.Service
-	memoryService   memory.Service
+	appName           string
+	rootAgent         agent.Agent
+	sessionService    session.Service
+	artifactService   artifact.Service
+	memoryService     memory.Service
+	credentialService auth.CredentialService
 
 	parents       parentmap.Map
 	pluginManager *plugininternal.PluginManager
+	compaction    *compaction.Config
 }
 
 // Run runs the agent for the given user input, yielding events from agents.
@@ -128,15 +158,23 @@ func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.C
 
 		storedSession := resp.Session
 
-		agentToRun, err := r.findAgentToRun(storedSession)
+		agentToRun, branch, err := r.findAgentToRun(storedSession, msg)
 		if err != nil {
 			yield(nil, err)
 			return
 		}
 
+		// Agents run by agent tools share the limits of the invocation
+		// running the tool.
+		limits := runconfig.LimitsFromContext(ctx)
+		if limits == nil {
+			limits = runconfig.NewLimits(cfg)
+		}
 		ctx = parentmap.ToContext(ctx, r.parents)
 		ctx = runconfig.ToContext(ctx, &runconfig.RunConfig{
-			StreamingMode: runconfig.StreamingMode(cfg.StreamingMode),
+			StreamingMode:          runconfig.StreamingMode(cfg.StreamingMode),
+			MaxConcurrentToolCalls: cfg.MaxConcurrentToolCalls,
+			Limits:                 limits,
 		})
 		ctx = plugininternal.ToContext(ctx, r.pluginManager)
 
@@ -161,9 +199,15 @@ func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.C
 		}
 
 		ctx := icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
-			Artifacts:   artifacts,
-			Memory:      memoryImpl,
+			Artifacts: artifacts,
+			Memory:    memoryImpl,
+			Credentials: &credential.Credentials{
+				Service: r.credentialService,
+				UserID:  storedSession.UserID(),
+				AppName: storedSession.AppName(),
+			},
 			Session:     sessioninternal.NewMutableSession(r.sessionService, storedSession),
+			Branch:      branch,
 			Agent:       agentToRun,
 			UserContent: msg,
 			RunConfig:   &cfg,
@@ -229,9 +273,27 @@ func (r *Runner) Run(ctx context.Context, userID, sessionID string, msg *genai.C
 				return
 			}
 		}
+
+		if r.compaction != nil {
+			if err := r.compact(ctx, storedSession); err != nil {
+				// Compaction is best effort, the invocation itself succeeded.
+				log.Printf("Failed to compact session %s: %v", storedSession.ID(), err)
+			}
+		}
 	}
 }
 
+// compact appends a compaction event to the session if the compaction
+// thresholds are crossed.
+func (r *Runner) compact(ctx agent.InvocationContext, storedSession session.Session) error {
+	event, err := compaction.Compact(ctx, *r.compaction, storedSession)
+	if err != nil || event == nil {
+		return err
+	}
+	event.InvocationID = ctx.InvocationID()
+	return r.sessionService.AppendEvent(ctx, storedSession, event)
+}
+
 func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSession session.Session, msg *genai.Content, saveInputBlobsAsArtifacts bool, pluginManager *plugininternal.PluginManager) (agent.InvocationContext, error) {
 	if msg == nil {
 		return ctx, nil
@@ -247,7 +309,9 @@ func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSessi
 			ctx = icontext.NewInvocationContext(ctx, icontext.InvocationContextParams{
 				Artifacts:   ctx.Artifacts(),
 				Memory:      ctx.Memory(),
+				Credentials: ctx.Credentials(),
 				Session:     ctx.Session(),
+				Branch:      ctx.Branch(),
 				Agent:       ctx.Agent(),
 				UserContent: msg,
 				RunConfig:   ctx.RunConfig(),
@@ -286,14 +350,29 @@ func (r *Runner) appendMessageToSession(ctx agent.InvocationContext, storedSessi
 }
 
 // findAgentToRun returns the agent that should handle the next request based on
-// session history.
-func (r *Runner) findAgentToRun(session session.Session) (agent.Agent, error) {
+// session history, and the branch to run it in.
+//
+// Function responses sent by the client are handled by the agent which issued
+// the function calls, regardless of its type, in the branch of the function
+// calls.
+func (r *Runner) findAgentToRun(session session.Session, msg *genai.Content) (agent.Agent, string, error) {
 	events := session.Events()
+	callEvent, err := findMatchingFunctionCall(events, msg)
+	if err != nil {
+		return nil, "", err
+	}
+	if callEvent != nil {
+		callAgent := findAgent(r.rootAgent, callEvent.Author)
+		if callAgent == nil {
+			return nil, "", fmt.Errorf("agent %q of function call event %s not found", callEvent.Author, callEvent.ID)
+		}
+		agentToRun, branch := r.findWorkflowToResume(callAgent, callEvent.Branch)
+		return agentToRun, branch, nil
+	}
+
 	for i := events.Len() - 1; i >= 0; i-- {
 		event := events.At(i)
 
-		// TODO: findMatchingFunctionCall.
-
 		if event.Author == "user" {
 			continue
 		}
@@ -306,12 +385,126 @@ func (r *Runner) findAgentToRun(session session.Session) (agent.Agent, error) {
 		}
 
 		if r.isTransferableAcrossAgentTree(subAgent) {
-			return subAgent, nil
+			return subAgent, "", nil
 		}
 	}
 
 	// Falls back to root agent if no suitable agents are found in the session.
-	return r.rootAgent, nil
+	return r.rootAgent, "", nil
+}
+
+// ErrNoMatchingFunctionCall is returned by Run if the message contains a
+// function response which doesn't match any long-running function call of
+// the session, or which the user already sent.
+var ErrNoMatchingFunctionCall = errors.New("no matching function call")
+
+// findMatchingFunctionCall returns the event with the long-running function
+// calls answered by the function responses of msg, or nil if msg contains no
+// function responses.
+func findMatchingFunctionCall(events session.Events, msg *genai.Content) (*session.Event, error) {
+	var match *session.Event
+	for _, resp := range utils.FunctionResponses(msg) {
+		ev, answered := findLongRunningFunctionCall(events, resp)
+		if ev == nil {
+			return nil, fmt.Errorf("%w for the response of %q with ID %q", ErrNoMatchingFunctionCall, resp.Name, resp.ID)
+		}
+		if answered {
+			return nil, fmt.Errorf("%w: the call of %q with ID %q already has this response", ErrNoMatchingFunctionCall, resp.Name, resp.ID)
+		}
+		if match != nil && (match.Author != ev.Author || match.Branch != ev.Branch) {
+			return nil, fmt.Errorf("function responses answer function calls of both agent %q and agent %q", match.Author, ev.Author)
+		}
+		match = ev
+	}
+	return match, nil
+}
+
+// findLongRunningFunctionCall returns the latest event with the long-running
+// function call answered by resp, or nil, and whether the user already sent
+// the same response to the call. The user may send several responses to a
+// call, e.g. status updates before the result, but sending the same one again
+// would run the agent twice for it.
+func findLongRunningFunctionCall(events session.Events, resp *genai.FunctionResponse) (ev *session.Event, answered bool) {
+	if resp.ID == "" {
+		return nil, false
+	}
+	for i := events.Len() - 1; i >= 0; i-- {
+		ev = events.At(i)
+		if !slices.Contains(ev.LongRunningToolIDs, resp.ID) {
+			continue
+		}
+		if !slices.ContainsFunc(utils.FunctionCalls(ev.Content), func(call *genai.FunctionCall) bool { return call.ID == resp.ID }) {
+			continue
+		}
+		for j := i + 1; j < events.Len(); j++ {
+			later := events.At(j)
+			// The tool itself may return an intermediate response, only
+			// the responses of the user count.
+			if later.Author != "user" {
+				continue
+			}
+			if slices.ContainsFunc(utils.FunctionResponses(later.Content), func(r *genai.FunctionResponse) bool { return sameResponse(r, resp) }) {
+				return ev, true
+			}
+		}
+		return ev, false
+	}
+	return nil, false
+}
+
+// sameResponse reports whether a and b are the same response to the same
+// function call. The responses are compared in JSON since stored sessions
+// may decode numbers with another type.
+func sameResponse(a, b *genai.FunctionResponse) bool {
+	if a.ID != b.ID {
+		return false
+	}
+	aJSON, err := json.Marshal(a.Response)
+	if err != nil {
+		return false
+	}
+	bJSON, err := json.Marshal(b.Response)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(aJSON, bJSON)
+}
+
+// findWorkflowToResume returns the outermost workflow agent running the agent
+// with the given branch, directly or through other workflow agents, and the
+// branch of the workflow agent. Running the workflow agent, rather than the
+// agent, lets the workflow resume from its checkpoint and continue with the
+// steps after the agent. If no workflow agent runs the agent, the agent and
+// its branch are returned.
+func (r *Runner) findWorkflowToResume(a agent.Agent, branch string) (agent.Agent, string) {
+	for parent := r.parents[a.Name()]; parent != nil; parent = r.parents[parent.Name()] {
+		internalAgent, ok := parent.(agentinternal.Agent)
+		if !ok {
+			break
+		}
+		switch agentinternal.Reveal(internalAgent).AgentType {
+		case agentinternal.TypeLoopAgent, agentinternal.TypeSequentialAgent:
+		case agentinternal.TypeParallelAgent, agentinternal.TypeGraphAgent:
+			// These agents run their sub-agents in branches named after
+			// them.
+			branch = trimBranch(branch, parent.Name())
+		default:
+			return a, branch
+		}
+		a = parent
+	}
+	return a, branch
+}
+
+// trimBranch returns the part of the branch before the segment of the agent,
+// or the branch if it has no such segment.
+func trimBranch(branch, agentName string) string {
+	segments := strings.Split(branch, ".")
+	i := slices.Index(segments, agentName)
+	if i < 0 {
+		return branch
+	}
+	return strings.Join(segments[:i], ".")
 }
 
 // checks if the agent and its parent chain allow transfer up the tree.
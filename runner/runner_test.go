@@ -17,17 +17,26 @@ package runner
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"iter"
 	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/parallelagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
 	"google.golang.org/adk/artifact"
+	"google.golang.org/adk/internal/agent/parentmap"
+	"google.golang.org/adk/internal/utils"
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/modeltest"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/compaction"
 )
 
 func TestRunner_findAgentToRun(t *testing.T) {
@@ -36,13 +45,16 @@ func TestRunner_findAgentToRun(t *testing.T) {
 	appName, userID, sessionID := "test", "userID", "sessionID"
 
 	agentTree := agentTree(t)
+	workflow := workflowTree(t)
 
 	tests := []struct {
-		name      string
-		rootAgent agent.Agent
-		session   session.Session
-		wantAgent agent.Agent
-		wantErr   bool
+		name       string
+		rootAgent  agent.Agent
+		session    session.Session
+		msg        *genai.Content
+		wantAgent  agent.Agent
+		wantBranch string
+		wantErr    error
 	}{
 		{
 			name: "last event from agent allowing transfer",
@@ -80,20 +92,103 @@ func TestRunner_findAgentToRun(t *testing.T) {
 			rootAgent: agentTree.root,
 			wantAgent: agentTree.root,
 		},
+		{
+			name: "function response to agent not allowing transfer",
+			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
+				{
+					Author: "no_transfer_agent",
+					Branch: "root.no_transfer_agent",
+					LLMResponse: model.LLMResponse{
+						Content: &genai.Content{
+							Role:  genai.RoleModel,
+							Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "approve"}}},
+						},
+					},
+					LongRunningToolIDs: []string{"call-1"},
+				},
+				{
+					Author: "allows_transfer_agent",
+				},
+			}),
+			msg:        functionResponse("call-1"),
+			rootAgent:  agentTree.root,
+			wantAgent:  agentTree.noTransferAgent,
+			wantBranch: "root.no_transfer_agent",
+		},
+		{
+			name: "function response to agent in workflow",
+			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
+				{
+					Author: "branch_b",
+					Branch: "fan_out.branch_b",
+					LLMResponse: model.LLMResponse{
+						Content: &genai.Content{
+							Role:  genai.RoleModel,
+							Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "approve"}}},
+						},
+					},
+					LongRunningToolIDs: []string{"call-1"},
+				},
+			}),
+			msg:       functionResponse("call-1"),
+			rootAgent: workflow,
+			wantAgent: workflow,
+		},
+		{
+			name: "function response to answered call",
+			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
+				{
+					Author: "no_transfer_agent",
+					LLMResponse: model.LLMResponse{
+						Content: &genai.Content{
+							Role:  genai.RoleModel,
+							Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "approve"}}},
+						},
+					},
+					LongRunningToolIDs: []string{"call-1"},
+				},
+				{
+					Author:      "user",
+					LLMResponse: model.LLMResponse{Content: functionResponse("call-1")},
+				},
+			}),
+			msg:       functionResponse("call-1"),
+			rootAgent: agentTree.root,
+			wantErr:   ErrNoMatchingFunctionCall,
+		},
+		{
+			name: "function response with unknown ID",
+			session: createSession(t, t.Context(), appName, userID, sessionID, []*session.Event{
+				{
+					Author: "allows_transfer_agent",
+				},
+			}),
+			msg:       functionResponse("unknown"),
+			rootAgent: agentTree.root,
+			wantErr:   ErrNoMatchingFunctionCall,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			parents, err := parentmap.New(tt.rootAgent)
+			if err != nil {
+				t.Fatal(err)
+			}
 			r := &Runner{
 				rootAgent: tt.rootAgent,
+				parents:   parents,
 			}
-			gotAgent, err := r.findAgentToRun(tt.session)
-			if (err != nil) != tt.wantErr {
+			gotAgent, gotBranch, err := r.findAgentToRun(tt.session, tt.msg)
+			if !errors.Is(err, tt.wantErr) {
 				t.Errorf("Runner.findAgentToRun() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if tt.wantAgent != gotAgent {
-				t.Errorf("Runner.findAgentToRun() = %+v, want %+v", gotAgent.Name(), tt.wantAgent.Name())
+				t.Errorf("Runner.findAgentToRun() = %+v, want %+v", gotAgent, tt.wantAgent)
+			}
+			if gotBranch != tt.wantBranch {
+				t.Errorf("Runner.findAgentToRun() branch = %q, want %q", gotBranch, tt.wantBranch)
 			}
 		})
 	}
@@ -314,6 +409,12 @@ func TestRunner_SaveInputBlobsAsArtifacts(t *testing.T) {
 	}
 }
 
+func functionResponse(id string) *genai.Content {
+	content := genai.NewContentFromFunctionResponse("approve", map[string]any{"approved": true}, genai.RoleUser)
+	content.Parts[0].FunctionResponse.ID = id
+	return content
+}
+
 // creates agentTree for tests and returns references to the agents
 func agentTree(t *testing.T) agentTreeStruct {
 	t.Helper()
@@ -337,6 +438,31 @@ func agentTree(t *testing.T) agentTreeStruct {
 	}
 }
 
+// workflowTree returns a sequential agent running an LLM agent, then two LLM
+// agents in parallel.
+func workflowTree(t *testing.T) agent.Agent {
+	t.Helper()
+
+	fanOut := must(parallelagent.New(parallelagent.Config{
+		AgentConfig: agent.Config{
+			Name: "fan_out",
+			SubAgents: []agent.Agent{
+				must(llmagent.New(llmagent.Config{Name: "branch_a"})),
+				must(llmagent.New(llmagent.Config{Name: "branch_b"})),
+			},
+		},
+	}))
+	return must(sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{
+			Name: "pipeline",
+			SubAgents: []agent.Agent{
+				must(llmagent.New(llmagent.Config{Name: "step"})),
+				fanOut,
+			},
+		},
+	}))
+}
+
 type agentTreeStruct struct {
 	root, noTransferAgent, allowsTransferAgent agent.Agent
 }
@@ -370,3 +496,113 @@ func createSession(t *testing.T, ctx context.Context, sessionID, appName, userID
 
 	return resp.Session
 }
+
+func TestRunner_FunctionResponseTwice(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+
+	approvalAgent := must(agent.New(agent.Config{
+		Name: "approval_agent",
+		Run: func(ctx agent.InvocationContext) iter.Seq2[*session.Event, error] {
+			return func(yield func(*session.Event, error) bool) {
+				ev := session.NewEvent(ctx.InvocationID())
+				ev.Author = ctx.Agent().Name()
+				if len(utils.FunctionResponses(ctx.UserContent())) > 0 {
+					ev.Content = genai.NewContentFromText("approved", genai.RoleModel)
+				} else {
+					ev.Content = &genai.Content{
+						Role:  genai.RoleModel,
+						Parts: []*genai.Part{{FunctionCall: &genai.FunctionCall{ID: "call-1", Name: "approve"}}},
+					}
+					ev.LongRunningToolIDs = []string{"call-1"}
+				}
+				yield(ev, nil)
+			}
+		},
+	}))
+	r, err := New(Config{
+		AppName:        "testApp",
+		Agent:          approvalAgent,
+		SessionService: sessionService,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "s"}); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(msg *genai.Content) error {
+		for _, err := range r.Run(ctx, "user", "s", msg, agent.RunConfig{}) {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := run(genai.NewContentFromText("please approve", genai.RoleUser)); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if err := run(functionResponse("call-1")); err != nil {
+		t.Fatalf("Run() with the first response error = %v", err)
+	}
+	if err := run(functionResponse("call-1")); !errors.Is(err, ErrNoMatchingFunctionCall) {
+		t.Errorf("Run() with the second response error = %v, want %v", err, ErrNoMatchingFunctionCall)
+	}
+}
+
+func TestRunner_Compaction(t *testing.T) {
+	ctx := t.Context()
+	sessionService := session.InMemoryService()
+	llm := modeltest.New(modeltest.Text("a1"), modeltest.Text("a2"), modeltest.Text("a3"))
+	summarizer := modeltest.New(modeltest.Text("The user said q1."), modeltest.Text("The user said q1 and q2."))
+
+	r, err := New(Config{
+		AppName:        "testApp",
+		Agent:          must(llmagent.New(llmagent.Config{Name: "test_agent", Model: llm})),
+		SessionService: sessionService,
+		Compaction: &compaction.Config{
+			Summarizer:     compaction.NewLLMSummarizer(summarizer, ""),
+			EventThreshold: 3,
+			RetainEvents:   2,
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := sessionService.Create(ctx, &session.CreateRequest{AppName: "testApp", UserID: "user", SessionID: "s"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, msg := range []string{"q1", "q2", "q3"} {
+		for _, err := range r.Run(ctx, "user", "s", genai.NewContentFromText(msg, genai.RoleUser), agent.RunConfig{}) {
+			if err != nil {
+				t.Fatalf("Run(%q) error = %v", msg, err)
+			}
+		}
+	}
+
+	// The first invocation is compacted at the end of the second one, the
+	// second one at the end of the third one.
+	want := []*genai.Content{
+		genai.NewContentFromText("The user said q1.", genai.RoleModel),
+		genai.NewContentFromText("q2", genai.RoleUser),
+		genai.NewContentFromText("a2", genai.RoleModel),
+		genai.NewContentFromText("q3", genai.RoleUser),
+	}
+	if diff := cmp.Diff(want, llm.Requests()[2].Contents); diff != "" {
+		t.Errorf("contents of the last request mismatch (-want +got):\n%s", diff)
+	}
+	if got := len(summarizer.Requests()); got != 2 {
+		t.Errorf("summarizer called %d times, want 2", got)
+	}
+
+	// The full history stays in the session.
+	resp, err := sessionService.Get(ctx, &session.GetRequest{AppName: "testApp", UserID: "user", SessionID: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Session.Events().Len(); got != 8 {
+		t.Errorf("session has %d events, want 8", got)
+	}
+}
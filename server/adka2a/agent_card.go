@@ -85,7 +85,23 @@ func buildLLMAgentSkills(agent agent.Agent, llmState *llminternal.State) []a2a.A
 		}
 	}
 
-	// TODO(yarolegovich): mention planning and code-execution skills once supported (and if configured)
+	if llmState.Planner != nil {
+		skills = append(skills, a2a.AgentSkill{
+			ID:          fmt.Sprintf("%s-planner", agent.Name()),
+			Name:        "planning",
+			Description: "Can think about the tasks to do and make plans",
+			Tags:        []string{"llm", "planning"},
+		})
+	}
+
+	if llmState.CodeExecutor != nil {
+		skills = append(skills, a2a.AgentSkill{
+			ID:          fmt.Sprintf("%s-code-executor", agent.Name()),
+			Name:        "code-execution",
+			Description: "Can execute code",
+			Tags:        []string{"llm", "code_execution"},
+		})
+	}
 
 	return skills
 }
@@ -266,6 +282,8 @@ func getDefaultAgentDescription(state *iagent.State) string {
 		return "A sequential workflow agent"
 	case iagent.TypeParallelAgent:
 		return "A parallel workflow agent"
+	case iagent.TypeGraphAgent:
+		return "A graph workflow agent"
 	case iagent.TypeLLMAgent:
 		return "An LLM-based agent"
 	default:
@@ -281,6 +299,8 @@ func getAgentTypeTag(state *iagent.State) string {
 		return "sequential_workflow"
 	case iagent.TypeParallelAgent:
 		return "parallel_workflow"
+	case iagent.TypeGraphAgent:
+		return "graph_workflow"
 	case iagent.TypeLLMAgent:
 		return "llm_agent"
 	default:
@@ -307,6 +327,6 @@ func getInternalState(agent agent.Agent) *iagent.State {
 }
 
 func isWorkflowAgent(state *iagent.State) bool {
-	workflowAgents := []iagent.Type{iagent.TypeLoopAgent, iagent.TypeSequentialAgent, iagent.TypeParallelAgent}
+	workflowAgents := []iagent.Type{iagent.TypeLoopAgent, iagent.TypeSequentialAgent, iagent.TypeParallelAgent, iagent.TypeGraphAgent}
 	return slices.Contains(workflowAgents, state.AgentType)
 }
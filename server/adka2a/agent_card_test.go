@@ -25,6 +25,8 @@ import (
 	"google.golang.org/adk/agent/workflowagents/loopagent"
 	"google.golang.org/adk/agent/workflowagents/parallelagent"
 	"google.golang.org/adk/agent/workflowagents/sequentialagent"
+	"google.golang.org/adk/codeexecutor"
+	"google.golang.org/adk/planner"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/geminitool"
 	"google.golang.org/adk/tool/loadartifactstool"
@@ -97,6 +99,50 @@ func TestGetAgentSkills_LLMAgent(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "llm with planner",
+			agent: must(llmagent.New(llmagent.Config{
+				Name:        "Test LLM",
+				Description: "Test llm.",
+				Planner:     planner.PlanReAct{},
+			})),
+			want: []a2a.AgentSkill{
+				{
+					ID:          "Test LLM",
+					Description: "Test llm.",
+					Name:        "model",
+					Tags:        []string{"llm"},
+				},
+				{
+					ID:          "Test LLM-planner",
+					Name:        "planning",
+					Description: "Can think about the tasks to do and make plans",
+					Tags:        []string{"llm", "planning"},
+				},
+			},
+		},
+		{
+			name: "llm with code executor",
+			agent: must(llmagent.New(llmagent.Config{
+				Name:         "Test LLM",
+				Description:  "Test llm.",
+				CodeExecutor: codeexecutor.BuiltIn{},
+			})),
+			want: []a2a.AgentSkill{
+				{
+					ID:          "Test LLM",
+					Description: "Test llm.",
+					Name:        "model",
+					Tags:        []string{"llm"},
+				},
+				{
+					ID:          "Test LLM-code-executor",
+					Name:        "code-execution",
+					Description: "Can execute code",
+					Tags:        []string{"llm", "code_execution"},
+				},
+			},
+		},
 		{
 			name: "empty loop agent",
 			agent: must(loopagent.New(loopagent.Config{
@@ -156,6 +156,12 @@ func (e *Executor) process(ctx ExecutorContext, r *runner.Runner, processor *eve
 				return fmt.Errorf("event write failed: %w", err)
 			}
 		}
+
+		if progress := processor.makeProgressUpdate(adkEvent); progress != nil {
+			if err := q.Write(ctx, progress); err != nil {
+				return fmt.Errorf("progress update write failed: %w", err)
+			}
+		}
 	}
 
 	if finalChunk, ok := processor.makeFinalArtifactUpdate(); ok {
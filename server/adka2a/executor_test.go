@@ -16,6 +16,7 @@ package adka2a
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
 	"net/http/httptest"
@@ -31,9 +32,14 @@ import (
 	"google.golang.org/genai"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/llmagent"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/modeltest"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 type testQueue struct {
@@ -537,3 +543,167 @@ func TestExecutor_Cancel_AfterEvent(t *testing.T) {
 		t.Fatal("Agent did not unblock")
 	}
 }
+
+func TestExecutor_StructuredOutput(t *testing.T) {
+	noop, err := functiontool.New(functiontool.Config{Name: "noop"}, func(tool.Context, struct{}) (map[string]any, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		turns       []*modeltest.Turn
+		wantPrompts int
+	}{
+		{
+			name: "valid",
+			turns: []*modeltest.Turn{
+				modeltest.FunctionCall("set_model_response", map[string]any{"capital": "Paris"}),
+			},
+			wantPrompts: 1,
+		},
+		{
+			name: "invalid call re-prompted",
+			turns: []*modeltest.Turn{
+				modeltest.FunctionCall("set_model_response", map[string]any{"city": "Paris"}),
+				modeltest.FunctionCall("set_model_response", map[string]any{"capital": "Paris"}),
+			},
+			wantPrompts: 2,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			task := &a2a.Task{ID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
+			hiMsg := a2a.NewMessageForTask(a2a.MessageRoleUser, task, a2a.TextPart{Text: "Capital of France?"})
+
+			llm := modeltest.New(tc.turns...)
+			agent, err := llmagent.New(llmagent.Config{
+				Name:  "capital_agent",
+				Model: llm,
+				Tools: []tool.Tool{noop},
+				OutputSchema: &genai.Schema{
+					Type:       genai.TypeObject,
+					Properties: map[string]*genai.Schema{"capital": {Type: genai.TypeString}},
+					Required:   []string{"capital"},
+				},
+				MaxOutputSchemaRetries: 1,
+			})
+			if err != nil {
+				t.Fatalf("llmagent.New() error = %v", err)
+			}
+			runnerConfig := runner.Config{AppName: agent.Name(), Agent: agent, SessionService: session.InMemoryService()}
+			executor := NewExecutor(ExecutorConfig{RunnerConfig: runnerConfig})
+			queue := &testQueue{Queue: newInMemoryQueue(t)}
+			reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID, Message: hiMsg, StoredTask: task}
+
+			if err := executor.Execute(t.Context(), reqCtx, queue); err != nil {
+				t.Fatalf("executor.Execute() error = %v, want nil", err)
+			}
+			if got := len(llm.Requests()); got != tc.wantPrompts {
+				t.Errorf("model called %d times, want %d", got, tc.wantPrompts)
+			}
+
+			var output string
+			for _, event := range queue.events {
+				update, ok := event.(*a2a.TaskArtifactUpdateEvent)
+				if !ok {
+					continue
+				}
+				for _, part := range update.Artifact.Parts {
+					if text, ok := part.(a2a.TextPart); ok {
+						output = text.Text
+					}
+				}
+			}
+			var got map[string]any
+			if err := json.Unmarshal([]byte(output), &got); err != nil {
+				t.Fatalf("last text part %q is not JSON: %v", output, err)
+			}
+			if diff := cmp.Diff(map[string]any{"capital": "Paris"}, got); diff != "" {
+				t.Errorf("structured output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExecutor_ToolConfirmation(t *testing.T) {
+	task := &a2a.Task{ID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
+
+	var paid int
+	pay, err := functiontool.New(functiontool.Config{Name: "pay", RequireConfirmation: true}, func(_ tool.Context, args struct {
+		Amount int `json:"amount"`
+	}) (map[string]any, error) {
+		paid += args.Amount
+		return map[string]any{"paid": args.Amount}, nil
+	})
+	if err != nil {
+		t.Fatalf("functiontool.New() error = %v", err)
+	}
+	llm := modeltest.New(
+		modeltest.FunctionCall("pay", map[string]any{"amount": 100.0}),
+		modeltest.Text("Paid."),
+	)
+	agent, err := llmagent.New(llmagent.Config{Name: "payment_agent", Model: llm, Tools: []tool.Tool{pay}})
+	if err != nil {
+		t.Fatalf("llmagent.New() error = %v", err)
+	}
+	runnerConfig := runner.Config{AppName: agent.Name(), Agent: agent, SessionService: session.InMemoryService()}
+	executor := NewExecutor(ExecutorConfig{RunnerConfig: runnerConfig})
+
+	execute := func(parts ...a2a.Part) *testQueue {
+		msg := a2a.NewMessageForTask(a2a.MessageRoleUser, task, parts...)
+		reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID, Message: msg, StoredTask: task}
+		queue := &testQueue{Queue: newInMemoryQueue(t)}
+		if err := executor.Execute(t.Context(), reqCtx, queue); err != nil {
+			t.Fatalf("executor.Execute() error = %v, want nil", err)
+		}
+		return queue
+	}
+	finalState := func(queue *testQueue) a2a.TaskState {
+		update, ok := queue.events[len(queue.events)-1].(*a2a.TaskStatusUpdateEvent)
+		if !ok {
+			t.Fatalf("last event is %T, want a status update", queue.events[len(queue.events)-1])
+		}
+		return update.Status.State
+	}
+
+	queue := execute(a2a.TextPart{Text: "Pay 100."})
+	if got := finalState(queue); got != a2a.TaskStateInputRequired {
+		t.Fatalf("final state = %v, want %v", got, a2a.TaskStateInputRequired)
+	}
+	var requestID string
+	for _, event := range queue.events {
+		update, ok := event.(*a2a.TaskArtifactUpdateEvent)
+		if !ok {
+			continue
+		}
+		for _, part := range update.Artifact.Parts {
+			if data, ok := part.(a2a.DataPart); ok && data.Data["name"] == toolconfirmation.FunctionCallName {
+				requestID, _ = data.Data["id"].(string)
+			}
+		}
+	}
+	if requestID == "" {
+		t.Fatal("no confirmation request")
+	}
+	if paid != 0 {
+		t.Fatalf("paid %d before the confirmation", paid)
+	}
+
+	queue = execute(a2a.DataPart{
+		Data: map[string]any{
+			"id":       requestID,
+			"name":     toolconfirmation.FunctionCallName,
+			"response": map[string]any{"confirmed": true},
+		},
+		Metadata: map[string]any{a2aDataPartMetaTypeKey: a2aDataPartTypeFunctionResponse},
+	})
+	if got := finalState(queue); got != a2a.TaskStateCompleted {
+		t.Errorf("final state = %v, want %v", got, a2a.TaskStateCompleted)
+	}
+	if paid != 100 {
+		t.Errorf("paid %d, want 100", paid)
+	}
+}
@@ -137,6 +137,28 @@ func (p *eventProcessor) makeFinalStatusUpdate() *a2a.TaskStatusUpdateEvent {
 	return ev
 }
 
+// makeProgressUpdate returns a working status update reporting the progress
+// of a workflow agent, or nil if the event doesn't report any.
+func (p *eventProcessor) makeProgressUpdate(event *session.Event) *a2a.TaskStatusUpdateEvent {
+	if event == nil || event.Actions.IterationSummary == nil {
+		return nil
+	}
+	summary := event.Actions.IterationSummary
+	meta := maps.Clone(p.meta.eventMeta)
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta[metadataIterationKey] = map[string]any{
+		"agent":          event.Author,
+		"iteration":      summary.Iteration,
+		"max_iterations": summary.MaxIterations,
+		"done":           summary.Done,
+	}
+	ev := a2a.NewStatusUpdateEvent(p.reqCtx, a2a.TaskStateWorking, nil)
+	ev.Metadata = meta
+	return ev
+}
+
 func (p *eventProcessor) makeTaskFailedEvent(cause error, event *session.Event) *a2a.TaskStatusUpdateEvent {
 	meta := p.meta.eventMeta
 	if event != nil {
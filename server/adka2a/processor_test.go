@@ -437,6 +437,35 @@ func TestEventProcessor_ArtifactUpdates(t *testing.T) {
 	}
 }
 
+func TestEventProcessor_ProgressUpdate(t *testing.T) {
+	task := &a2a.Task{ID: a2a.NewTaskID(), ContextID: a2a.NewContextID()}
+	reqCtx := &a2asrv.RequestContext{TaskID: task.ID, ContextID: task.ContextID}
+	processor := newEventProcessor(reqCtx, invocationMeta{})
+
+	if got := processor.makeProgressUpdate(&session.Event{LLMResponse: modelResponseFromParts(genai.NewPartFromText("hi"))}); got != nil {
+		t.Errorf("makeProgressUpdate() = %v, want nil for an event without progress", got)
+	}
+
+	got := processor.makeProgressUpdate(&session.Event{
+		Author: "refine",
+		Actions: session.EventActions{
+			IterationSummary: &session.IterationSummary{Iteration: 1, MaxIterations: 3},
+		},
+	})
+	if got == nil {
+		t.Fatal("makeProgressUpdate() = nil, want a status update")
+	}
+	if got.Status.State != a2a.TaskStateWorking || got.Final {
+		t.Errorf("makeProgressUpdate() = %+v, want a non-final working status update", got)
+	}
+	want := map[string]any{
+		metadataIterationKey: map[string]any{"agent": "refine", "iteration": uint(1), "max_iterations": uint(3), "done": false},
+	}
+	if diff := cmp.Diff(want, got.Metadata); diff != "" {
+		t.Errorf("makeProgressUpdate() metadata mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func makeTerminalEvents(processor *eventProcessor) []a2a.Event {
 	result := make([]a2a.Event, 0, 2)
 	if finalUpdate, ok := processor.makeFinalArtifactUpdate(); ok {
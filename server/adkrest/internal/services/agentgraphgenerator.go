@@ -22,6 +22,7 @@ import (
 	"github.com/awalterschulze/gographviz"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/agent/workflowagents/graphagent"
 	agentinternal "google.golang.org/adk/internal/agent"
 	llmagentinternal "google.golang.org/adk/internal/llminternal"
 	"google.golang.org/adk/tool"
@@ -39,6 +40,7 @@ var supportedClusterAgents = []agentinternal.Type{
 	agentinternal.TypeLoopAgent,
 	agentinternal.TypeSequentialAgent,
 	agentinternal.TypeParallelAgent,
+	agentinternal.TypeGraphAgent,
 }
 
 type namedInstance interface {
@@ -167,6 +169,20 @@ func drawCluster(parentGraph, cluster *gographviz.Graph, agent agent.Agent, high
 			if err != nil {
 				return fmt.Errorf("draw cluster: draw edge: %w", err)
 			}
+		// Graph sub-agents should be connected by the edges of the graph.
+		case agentinternal.TypeGraphAgent:
+			cfg, _ := agentinternal.Reveal(agentInternal).Config.(graphagent.Config)
+			for _, edge := range cfg.Edges {
+				if edge.From != subAgent.Name() {
+					continue
+				}
+				for _, to := range edge.To {
+					err = drawEdge(parentGraph, edge.From, to, highlightedPairs)
+					if err != nil {
+						return fmt.Errorf("draw cluster: draw edge: %w", err)
+					}
+				}
+			}
 		}
 		// Parallel sub-agents shouldn't be connected, they will be a part of the sub graph.
 	}
@@ -0,0 +1,176 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compaction condenses the older events of long sessions into
+// summaries, so the conversation history sent to the model stays small.
+//
+// The summaries are stored in the session as events with a
+// [session.EventCompaction] action. The summarized events are kept in the
+// session for audit, but are replaced by the summary when the history is
+// built for the model.
+//
+// Compaction is enabled with the Compaction field of runner.Config, the
+// runner then calls [Compact] at the end of every invocation.
+package compaction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// DefaultRetainEvents is the default number of recent events kept verbatim.
+const DefaultRetainEvents = 10
+
+// Config is the configuration of the event compaction.
+//
+// The events added since the last compaction are compacted once their number
+// exceeds EventThreshold or their estimated token count exceeds
+// TokenThreshold. At least one threshold must be set.
+type Config struct {
+	// Summarizer condenses the compacted events. Required.
+	Summarizer Summarizer
+	// EventThreshold is the number of events since the last compaction above
+	// which the session is compacted. Zero disables the threshold.
+	EventThreshold int
+	// TokenThreshold is the estimated number of tokens of the events since
+	// the last compaction above which the session is compacted, see
+	// [model.EstimateTokens]. Zero disables the threshold.
+	TokenThreshold int
+	// RetainEvents is the number of most recent events kept verbatim.
+	// The retained range is extended back to the start of the invocation of
+	// its first event, so function calls stay paired with their responses.
+	// If zero, DefaultRetainEvents is used.
+	RetainEvents int
+}
+
+// Validate checks that the config is complete.
+func (c *Config) Validate() error {
+	if c.Summarizer == nil {
+		return errors.New("summarizer is required")
+	}
+	if c.EventThreshold <= 0 && c.TokenThreshold <= 0 {
+		return errors.New("either event or token threshold is required")
+	}
+	return nil
+}
+
+// Compact checks the thresholds of cfg against the events of sess and, if one
+// is crossed, summarizes the older events. The summary covers the previous
+// summary, if any, so the latest summary always starts at the first
+// summarized event of the session.
+//
+// It returns the event to append to the session, or nil if no compaction is
+// needed. The caller sets the InvocationID of the event.
+func Compact(ctx context.Context, cfg Config, sess session.Session) (*session.Event, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid compaction config: %w", err)
+	}
+	retain := cfg.RetainEvents
+	if retain <= 0 {
+		retain = DefaultRetainEvents
+	}
+
+	var events []*session.Event
+	var last *session.EventCompaction
+	for ev := range sess.Events().All() {
+		if c := ev.Actions.Compaction; c != nil {
+			last = c
+			continue
+		}
+		events = append(events, ev)
+	}
+
+	// Only the events added since the last compaction count towards the
+	// thresholds.
+	pending := events
+	if last != nil {
+		pending = nil
+		for _, ev := range events {
+			if ev.Timestamp.After(last.EndTimestamp) {
+				pending = append(pending, ev)
+			}
+		}
+	}
+	if !exceeds(cfg, pending) {
+		return nil, nil
+	}
+
+	end := len(pending) - retain
+	// Don't split invocations, the retained events start with a new one.
+	for end > 0 && pending[end].InvocationID == pending[end-1].InvocationID {
+		end--
+	}
+	if end <= 0 {
+		return nil, nil
+	}
+	compacted := pending[:end]
+
+	var previous *session.Event
+	compaction := &session.EventCompaction{
+		StartTimestamp: compacted[0].Timestamp,
+		EndTimestamp:   compacted[end-1].Timestamp,
+	}
+	if last != nil {
+		compaction.StartTimestamp = last.StartTimestamp
+		previous = summaryEvent(last)
+	}
+
+	toSummarize := compacted
+	if previous != nil {
+		toSummarize = append([]*session.Event{previous}, compacted...)
+	}
+	summary, err := cfg.Summarizer.Summarize(ctx, toSummarize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize events: %w", err)
+	}
+	if summary == nil {
+		return nil, nil
+	}
+	compaction.CompactedContent = summary
+
+	ev := session.NewEvent("")
+	ev.Author = "user"
+	ev.Actions.Compaction = compaction
+	return ev, nil
+}
+
+func exceeds(cfg Config, events []*session.Event) bool {
+	if cfg.EventThreshold > 0 && len(events) > cfg.EventThreshold {
+		return true
+	}
+	if cfg.TokenThreshold > 0 {
+		tokens := 0
+		for _, ev := range events {
+			tokens += model.EstimateContentTokens(ev.Content)
+		}
+		if tokens > cfg.TokenThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryEvent returns an event holding the summary of c, used to build the
+// input of the next summary.
+func summaryEvent(c *session.EventCompaction) *session.Event {
+	return &session.Event{
+		Timestamp:   c.EndTimestamp,
+		Author:      "summary",
+		LLMResponse: model.LLMResponse{Content: c.CompactedContent},
+	}
+}
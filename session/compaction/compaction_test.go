@@ -0,0 +1,219 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compaction_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/model/modeltest"
+	"google.golang.org/adk/session"
+	"google.golang.org/adk/session/compaction"
+)
+
+var t0 = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newSession returns a session with one event per text, authored by the user
+// for odd indexes and by the agent otherwise. Each pair of events is a
+// separate invocation.
+func newSession(t *testing.T, texts ...string) (session.Service, session.Session) {
+	t.Helper()
+	svc := session.InMemoryService()
+	resp, err := svc.Create(t.Context(), &session.CreateRequest{AppName: "app", UserID: "user", SessionID: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range texts {
+		ev := session.NewEvent("inv" + string(rune('0'+i/2)))
+		ev.Timestamp = t0.Add(time.Duration(i) * time.Second)
+		ev.Author, ev.Content = "user", genai.NewContentFromText(text, genai.RoleUser)
+		if i%2 == 1 {
+			ev.Author, ev.Content = "agent", genai.NewContentFromText(text, genai.RoleModel)
+		}
+		if err := svc.AppendEvent(t.Context(), resp.Session, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return svc, resp.Session
+}
+
+type fakeSummarizer struct {
+	got [][]string
+}
+
+func (s *fakeSummarizer) Summarize(_ context.Context, events []*session.Event) (*genai.Content, error) {
+	var texts []string
+	for _, ev := range events {
+		texts = append(texts, ev.Content.Parts[0].Text)
+	}
+	s.got = append(s.got, texts)
+	return genai.NewContentFromText("summary of "+strings.Join(texts, ","), genai.RoleModel), nil
+}
+
+func TestCompact(t *testing.T) {
+	tests := []struct {
+		name       string
+		texts      []string
+		cfg        compaction.Config
+		want       *session.EventCompaction
+		wantInputs [][]string
+	}{
+		{
+			name:  "below threshold",
+			texts: []string{"q1", "a1", "q2", "a2"},
+			cfg:   compaction.Config{EventThreshold: 4, RetainEvents: 2},
+		},
+		{
+			name:  "event threshold",
+			texts: []string{"q1", "a1", "q2", "a2", "q3"},
+			cfg:   compaction.Config{EventThreshold: 4, RetainEvents: 2},
+			want: &session.EventCompaction{
+				StartTimestamp:   t0,
+				EndTimestamp:     t0.Add(1 * time.Second),
+				CompactedContent: genai.NewContentFromText("summary of q1,a1", genai.RoleModel),
+			},
+			wantInputs: [][]string{{"q1", "a1"}},
+		},
+		{
+			name:  "retains whole invocations",
+			texts: []string{"q1", "a1", "q2", "a2", "q3", "a3"},
+			cfg:   compaction.Config{EventThreshold: 4, RetainEvents: 1},
+			want: &session.EventCompaction{
+				StartTimestamp:   t0,
+				EndTimestamp:     t0.Add(3 * time.Second),
+				CompactedContent: genai.NewContentFromText("summary of q1,a1,q2,a2", genai.RoleModel),
+			},
+			wantInputs: [][]string{{"q1", "a1", "q2", "a2"}},
+		},
+		{
+			name:  "token threshold",
+			texts: []string{strings.Repeat("a", 400), "a1", "q2"},
+			cfg:   compaction.Config{TokenThreshold: 100, RetainEvents: 1},
+			want: &session.EventCompaction{
+				StartTimestamp:   t0,
+				EndTimestamp:     t0.Add(1 * time.Second),
+				CompactedContent: genai.NewContentFromText("summary of "+strings.Repeat("a", 400)+",a1", genai.RoleModel),
+			},
+			wantInputs: [][]string{{strings.Repeat("a", 400), "a1"}},
+		},
+		{
+			name:  "nothing to compact",
+			texts: []string{"q1", "a1"},
+			cfg:   compaction.Config{EventThreshold: 1, RetainEvents: 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, sess := newSession(t, tt.texts...)
+			summarizer := &fakeSummarizer{}
+			tt.cfg.Summarizer = summarizer
+
+			ev, err := compaction.Compact(t.Context(), tt.cfg, sess)
+			if err != nil {
+				t.Fatalf("Compact() error = %v", err)
+			}
+			var got *session.EventCompaction
+			if ev != nil {
+				got = ev.Actions.Compaction
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Compact() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantInputs, summarizer.got); diff != "" {
+				t.Errorf("Summarize() inputs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCompact_IncludesPreviousSummary(t *testing.T) {
+	svc, sess := newSession(t, "q1", "a1", "q2", "a2", "q3")
+	summarizer := &fakeSummarizer{}
+	cfg := compaction.Config{Summarizer: summarizer, EventThreshold: 2, RetainEvents: 1}
+
+	ev, err := compaction.Compact(t.Context(), cfg, sess)
+	if err != nil || ev == nil {
+		t.Fatalf("Compact() = (%v, %v), want compaction", ev, err)
+	}
+	if err := svc.AppendEvent(t.Context(), sess, ev); err != nil {
+		t.Fatal(err)
+	}
+	// Only the events since the last compaction count.
+	if ev, err := compaction.Compact(t.Context(), cfg, sess); err != nil || ev != nil {
+		t.Fatalf("Compact() = (%v, %v), want no compaction", ev, err)
+	}
+
+	for i, text := range []string{"a3", "q4", "a4"} {
+		ev := session.NewEvent("inv-next" + text)
+		ev.Timestamp = t0.Add(time.Duration(10+i) * time.Second)
+		ev.Author, ev.Content = "agent", genai.NewContentFromText(text, genai.RoleModel)
+		if err := svc.AppendEvent(t.Context(), sess, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ev, err = compaction.Compact(t.Context(), cfg, sess)
+	if err != nil || ev == nil {
+		t.Fatalf("Compact() = (%v, %v), want compaction", ev, err)
+	}
+
+	want := &session.EventCompaction{
+		StartTimestamp:   t0,
+		EndTimestamp:     t0.Add(11 * time.Second),
+		CompactedContent: genai.NewContentFromText("summary of summary of q1,a1,q2,a2,q3,a3,q4", genai.RoleModel),
+	}
+	if diff := cmp.Diff(want, ev.Actions.Compaction); diff != "" {
+		t.Errorf("Compact() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestLLMSummarizer(t *testing.T) {
+	llm := modeltest.New(modeltest.Text("The user asked for the weather in Paris."))
+	s := compaction.NewLLMSummarizer(llm, "Summarize.")
+
+	call := session.NewEvent("inv")
+	call.Author = "agent"
+	call.Content = genai.NewContentFromFunctionCall("get_weather", map[string]any{"city": "Paris"}, genai.RoleModel)
+	resp := session.NewEvent("inv")
+	resp.Author = "agent"
+	resp.Content = genai.NewContentFromFunctionResponse("get_weather", map[string]any{"result": "sunny"}, genai.RoleUser)
+	question := session.NewEvent("inv")
+	question.Author = "user"
+	question.Content = genai.NewContentFromText("Weather in Paris?", genai.RoleUser)
+
+	got, err := s.Summarize(t.Context(), []*session.Event{question, call, resp})
+	if err != nil {
+		t.Fatalf("Summarize() error = %v", err)
+	}
+	if diff := cmp.Diff(genai.NewContentFromText("The user asked for the weather in Paris.", genai.RoleModel), got); diff != "" {
+		t.Errorf("Summarize() mismatch (-want +got):\n%s", diff)
+	}
+
+	wantPrompt := `Summarize.
+
+user: Weather in Paris?
+agent: called get_weather({"city":"Paris"})
+agent: get_weather returned {"result":"sunny"}
+`
+	wantReq := []*model.LLMRequest{{Model: llm.Name(), Contents: genai.Text(wantPrompt)}}
+	if diff := cmp.Diff(wantReq, llm.Requests()); diff != "" {
+		t.Errorf("request mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compaction
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+	"google.golang.org/adk/session"
+)
+
+// Summarizer condenses a range of events into a single content.
+type Summarizer interface {
+	Summarize(ctx context.Context, events []*session.Event) (*genai.Content, error)
+}
+
+// DefaultInstruction is the default instruction of the summarizer returned by
+// [NewLLMSummarizer].
+const DefaultInstruction = `The following is a conversation history between a user and AI agents.
+Summarize the conversation, focusing on key information and decisions made,
+as well as any unresolved questions or tasks.
+The summary should be concise and capture the essence of the interaction.`
+
+// NewLLMSummarizer returns a [Summarizer] asking llm to summarize the events.
+//
+// The events are rendered as a transcript appended to the instruction.
+// If instruction is empty, DefaultInstruction is used.
+func NewLLMSummarizer(llm model.LLM, instruction string) Summarizer {
+	if instruction == "" {
+		instruction = DefaultInstruction
+	}
+	return &llmSummarizer{llm: llm, instruction: instruction}
+}
+
+type llmSummarizer struct {
+	llm         model.LLM
+	instruction string
+}
+
+func (s *llmSummarizer) Summarize(ctx context.Context, events []*session.Event) (*genai.Content, error) {
+	transcript := formatEvents(events)
+	if transcript == "" {
+		return nil, nil
+	}
+	req := &model.LLMRequest{
+		Model:    s.llm.Name(),
+		Contents: genai.Text(s.instruction + "\n\n" + transcript),
+	}
+	var text strings.Builder
+	for resp, err := range s.llm.GenerateContent(ctx, req, false) {
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil || resp.Partial || resp.Content == nil {
+			continue
+		}
+		for _, p := range resp.Content.Parts {
+			if p.Text != "" && !p.Thought {
+				text.WriteString(p.Text)
+			}
+		}
+	}
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("model %q returned an empty summary", s.llm.Name())
+	}
+	return genai.NewContentFromText(text.String(), genai.RoleModel), nil
+}
+
+// formatEvents renders the events as a transcript, one line per part.
+func formatEvents(events []*session.Event) string {
+	var sb strings.Builder
+	for _, ev := range events {
+		if ev.Content == nil {
+			continue
+		}
+		for _, p := range ev.Content.Parts {
+			switch {
+			case p.Thought:
+			case p.Text != "":
+				fmt.Fprintf(&sb, "%s: %s\n", ev.Author, p.Text)
+			case p.FunctionCall != nil:
+				fmt.Fprintf(&sb, "%s: called %s(%s)\n", ev.Author, p.FunctionCall.Name, marshal(p.FunctionCall.Args))
+			case p.FunctionResponse != nil:
+				fmt.Fprintf(&sb, "%s: %s returned %s\n", ev.Author, p.FunctionResponse.Name, marshal(p.FunctionResponse.Response))
+			}
+		}
+	}
+	return sb.String()
+}
+
+func marshal(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
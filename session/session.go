@@ -20,8 +20,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"google.golang.org/genai"
 
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/model"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 // Session represents a series of interactions between a user and agents.
@@ -154,6 +157,46 @@ type EventActions struct {
 	TransferToAgent string
 	// The agent is escalating to a higher level agent.
 	Escalate bool
+
+	// Credentials requested by tools, keyed by the ID of the function call
+	// requesting them. The flow asks the client for these credentials and
+	// resumes the function calls once they are provided.
+	RequestedAuthConfigs map[string]*auth.Config
+	// Confirmations requested by tools, keyed by the ID of the function call
+	// requesting them. The flow asks the user for these confirmations and
+	// resumes the function calls once they are provided.
+	RequestedToolConfirmations map[string]*toolconfirmation.ToolConfirmation
+
+	// If set, the event summarizes a range of earlier events of the session.
+	// The summarized events stay in the session, but are replaced by the
+	// summary when building the conversation history sent to the model.
+	Compaction *EventCompaction
+
+	// If set, the event reports the end of an iteration of a loop agent,
+	// e.g. to show the progress of the loop.
+	IterationSummary *IterationSummary
+}
+
+// EventCompaction describes a range of events of the session replaced by a
+// summary.
+type EventCompaction struct {
+	// StartTimestamp is the timestamp of the first summarized event.
+	StartTimestamp time.Time
+	// EndTimestamp is the timestamp of the last summarized event.
+	EndTimestamp time.Time
+	// CompactedContent is the summary of the events.
+	CompactedContent *genai.Content
+}
+
+// IterationSummary describes an iteration of a loop agent.
+type IterationSummary struct {
+	// Iteration is the 0-based index of the iteration.
+	Iteration uint
+	// MaxIterations is the maximum number of iterations of the loop, or 0 if
+	// it is unbounded.
+	MaxIterations uint
+	// Done reports whether the loop ends after the iteration.
+	Done bool
 }
 
 // Prefixes for defining session's state scopes
@@ -0,0 +1,94 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/model"
+)
+
+func TestEvent_IsFinalResponse(t *testing.T) {
+	contentEvent := func(parts ...*genai.Part) *Event {
+		return &Event{LLMResponse: model.LLMResponse{Content: genai.NewContentFromParts(parts, genai.RoleModel)}}
+	}
+
+	testCases := []struct {
+		name  string
+		event *Event
+		want  bool
+	}{
+		{
+			name:  "text",
+			event: contentEvent(genai.NewPartFromText("hi")),
+			want:  true,
+		},
+		{
+			name:  "function call",
+			event: contentEvent(genai.NewPartFromFunctionCall("f", nil)),
+		},
+		{
+			name:  "function response",
+			event: contentEvent(genai.NewPartFromFunctionResponse("f", nil)),
+		},
+		{
+			name:  "partial",
+			event: &Event{LLMResponse: model.LLMResponse{Content: genai.NewContentFromText("h", genai.RoleModel), Partial: true}},
+		},
+		{
+			name:  "executable code",
+			event: contentEvent(genai.NewPartFromExecutableCode("print(1)", genai.LanguagePython)),
+			want:  true,
+		},
+		{
+			name:  "trailing code execution result",
+			event: contentEvent(genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "1")),
+		},
+		{
+			name: "text after code execution result",
+			event: contentEvent(
+				genai.NewPartFromExecutableCode("print(1)", genai.LanguagePython),
+				genai.NewPartFromCodeExecutionResult(genai.OutcomeOK, "1"),
+				genai.NewPartFromText("It's 1."),
+			),
+			want: true,
+		},
+		{
+			name: "long running function call",
+			event: &Event{
+				LLMResponse:        model.LLMResponse{Content: genai.NewContentFromFunctionCall("f", nil, genai.RoleModel)},
+				LongRunningToolIDs: []string{"id"},
+			},
+			want: true,
+		},
+		{
+			name: "skip summarization",
+			event: &Event{
+				LLMResponse: model.LLMResponse{Content: genai.NewContentFromFunctionResponse("f", nil, genai.RoleUser)},
+				Actions:     EventActions{SkipSummarization: true},
+			},
+			want: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.event.IsFinalResponse(); got != tc.want {
+				t.Errorf("IsFinalResponse() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package functiontool_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/genai"
+
+	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/internal/testutil"
+	"google.golang.org/adk/model/modeltest"
+	"google.golang.org/adk/tool"
+	"google.golang.org/adk/tool/functiontool"
+	"google.golang.org/adk/tool/toolconfirmation"
+)
+
+func TestFunctionTool_RequireConfirmation(t *testing.T) {
+	type PayArgs struct {
+		Amount int `json:"amount"`
+	}
+
+	testCases := []struct {
+		name         string
+		cfg          functiontool.Config
+		confirmation map[string]any
+		wantPaid     []int
+		wantResponse map[string]any
+	}{
+		{
+			name:         "approved",
+			cfg:          functiontool.Config{RequireConfirmation: true},
+			confirmation: map[string]any{"confirmed": true},
+			wantPaid:     []int{100},
+			wantResponse: map[string]any{"paid": float64(100)},
+		},
+		{
+			name:         "rejected",
+			cfg:          functiontool.Config{RequireConfirmation: true},
+			confirmation: map[string]any{"confirmed": false},
+			wantResponse: map[string]any{"error": "This tool call is rejected."},
+		},
+		{
+			name:         "approved with modified arguments",
+			cfg:          functiontool.Config{RequireConfirmation: true},
+			confirmation: map[string]any{"confirmed": true, "args": map[string]any{"amount": 50}},
+			wantPaid:     []int{50},
+			wantResponse: map[string]any{"paid": float64(50)},
+		},
+		{
+			name:         "approved with encoded response",
+			cfg:          functiontool.Config{RequireConfirmation: true},
+			confirmation: map[string]any{"response": `{"confirmed": true}`},
+			wantPaid:     []int{100},
+			wantResponse: map[string]any{"paid": float64(100)},
+		},
+		{
+			name: "not required by provider",
+			cfg: functiontool.Config{
+				RequireConfirmation: true,
+				RequireConfirmationProvider: func(args map[string]any) bool {
+					return args["amount"].(float64) > 1000
+				},
+			},
+			wantPaid:     []int{100},
+			wantResponse: map[string]any{"paid": float64(100)},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var paid []int
+			cfg := tc.cfg
+			cfg.Name = "pay"
+			cfg.Description = "pays the amount"
+			pay, err := functiontool.New(cfg, func(ctx tool.Context, args PayArgs) (map[string]any, error) {
+				paid = append(paid, args.Amount)
+				return map[string]any{"paid": args.Amount}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			llm := modeltest.New(
+				modeltest.FunctionCall("pay", map[string]any{"amount": 100.0}),
+				modeltest.Text("done"),
+			)
+			a, err := llmagent.New(llmagent.Config{
+				Name:  "payment_agent",
+				Model: llm,
+				Tools: []tool.Tool{pay},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			runner := testutil.NewTestAgentRunner(t, a)
+
+			events, err := testutil.CollectEvents(runner.Run(t, "session", "pay 100"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := events[len(events)-1]
+			if tc.confirmation != nil {
+				if len(events) != 3 {
+					t.Fatalf("got %d events, want the function call, response and confirmation events", len(events))
+				}
+				if len(paid) != 0 {
+					t.Fatalf("tool ran before the confirmation: %v", paid)
+				}
+				call := last.Content.Parts[0].FunctionCall
+				if call.Name != toolconfirmation.FunctionCallName || !last.IsFinalResponse() {
+					t.Fatalf("last event is not a confirmation request: %+v", last.Content)
+				}
+				original := call.Args["originalFunctionCall"].(map[string]any)
+				if got, want := original["id"], events[0].Content.Parts[0].FunctionCall.ID; got != want {
+					t.Errorf("original function call ID = %v, want %v", got, want)
+				}
+				if hint := call.Args["toolConfirmation"].(map[string]any)["hint"]; hint == nil {
+					t.Error("confirmation request has no hint")
+				}
+
+				reply := genai.NewContentFromFunctionResponse(call.Name, tc.confirmation, genai.RoleUser)
+				reply.Parts[0].FunctionResponse.ID = call.ID
+				events, err = testutil.CollectEvents(runner.RunContent(t, "session", reply))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var response map[string]any
+			for _, ev := range events {
+				for _, p := range ev.Content.Parts {
+					if p.FunctionResponse != nil {
+						response = p.FunctionResponse.Response
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.wantResponse, response); diff != "" {
+				t.Errorf("function response mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantPaid, paid); diff != "" {
+				t.Errorf("payments mismatch (-want +got):\n%s", diff)
+			}
+			if got := events[len(events)-1].Content.Parts[0].Text; got != "done" {
+				t.Errorf("last event text = %q, want %q", got, "done")
+			}
+		})
+	}
+}
@@ -46,6 +46,19 @@ type Config struct {
 	OutputSchema *jsonschema.Schema
 	// IsLongRunning makes a FunctionTool a long-running operation.
 	IsLongRunning bool
+	// RequireConfirmation makes the tool ask the user to approve each call
+	// before running the handler. The invocation is suspended until the
+	// client approves or rejects the call, see the toolconfirmation package.
+	// Rejected calls return an error to the model instead of running.
+	RequireConfirmation bool
+	// RequireConfirmationProvider decides from the arguments of each call
+	// whether it requires a confirmation, e.g. only for amounts above a
+	// threshold. If set, RequireConfirmation is ignored.
+	RequireConfirmationProvider func(args map[string]any) bool
+	// Sequential makes the calls of the tool run alone, instead of
+	// concurrently with the other function calls of a model response. Set it
+	// if the handler isn't safe for concurrent use.
+	Sequential bool
 }
 
 // Func represents a Go function that can be wrapped in a tool.
@@ -115,6 +128,11 @@ func (f *functionTool[TArgs, TResults]) IsLongRunning() bool {
 	return f.cfg.IsLongRunning
 }
 
+// Sequential implements tool.Sequential.
+func (f *functionTool[TArgs, TResults]) Sequential() bool {
+	return f.cfg.Sequential
+}
+
 // ProcessRequest packs the function tool's declaration into the LLM request.
 func (f *functionTool[TArgs, TResults]) ProcessRequest(ctx tool.Context, req *model.LLMRequest) error {
 	return toolutils.PackTool(req, f)
@@ -158,6 +176,19 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (result
 	if !ok {
 		return nil, fmt.Errorf("unexpected args type, got: %T", args)
 	}
+	if f.requiresConfirmation(m) {
+		confirmation := ctx.ToolConfirmation()
+		if confirmation == nil {
+			hint := fmt.Sprintf("Please approve or reject the tool call %s() by responding with a FunctionResponse with an expected ToolConfirmation payload.", f.Name())
+			if err := ctx.RequestConfirmation(hint, nil); err != nil {
+				return nil, err
+			}
+			return map[string]any{"error": "This tool call requires confirmation, please approve or reject."}, nil
+		}
+		if !confirmation.Confirmed {
+			return map[string]any{"error": "This tool call is rejected."}, nil
+		}
+	}
 	input, err := typeutil.ConvertToWithJSONSchema[map[string]any, TArgs](m, f.inputSchema)
 	if err != nil {
 		return nil, err
@@ -184,6 +215,13 @@ func (f *functionTool[TArgs, TResults]) Run(ctx tool.Context, args any) (result
 	return wrappedOutput, nil
 }
 
+func (f *functionTool[TArgs, TResults]) requiresConfirmation(args map[string]any) bool {
+	if f.cfg.RequireConfirmationProvider != nil {
+		return f.cfg.RequireConfirmationProvider(args)
+	}
+	return f.cfg.RequireConfirmation
+}
+
 // ** NOTE FOR REVIEWERS **
 // Initially I started to borrow the design of the MCP ServerTool and
 // ToolHandlerFor/ToolHandler [1], but got diverged.
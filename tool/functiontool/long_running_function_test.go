@@ -16,6 +16,7 @@ package functiontool_test
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
@@ -23,9 +24,12 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/agent/workflowagents/sequentialagent"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/internal/toolinternal"
+	"google.golang.org/adk/runner"
 	"google.golang.org/adk/tool"
 	"google.golang.org/adk/tool/functiontool"
 )
@@ -323,3 +327,93 @@ func TestLongRunningToolIDsAreSet(t *testing.T) {
 			functionCallEvent.LLMResponse.Content.Parts[0].FunctionCall.ID)
 	}
 }
+
+func TestLongRunningFunctionResumedByClient(t *testing.T) {
+	mockModel := &testutil.MockModel{Responses: []*genai.Content{
+		genai.NewContentFromFunctionCall("approve", map[string]any{}, "model"),
+		genai.NewContentFromText("approved", "model"),
+	}}
+	approve, err := functiontool.New(functiontool.Config{
+		Name:          "approve",
+		Description:   "asks a human for approval",
+		IsLongRunning: true,
+	}, func(ctx tool.Context, _ IncArgs) (map[string]any, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	approver, err := llmagent.New(llmagent.Config{
+		Name:                     "approver",
+		Model:                    mockModel,
+		Tools:                    []tool.Tool{approve},
+		DisallowTransferToParent: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := sequentialagent.New(sequentialagent.Config{
+		AgentConfig: agent.Config{Name: "workflow", SubAgents: []agent.Agent{approver}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := testutil.NewTestAgentRunner(t, root)
+
+	// The tool has no result yet, so the invocation ends with the call.
+	events, err := testutil.CollectEvents(r.Run(t, "session", "approve the request"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || len(events[0].LongRunningToolIDs) != 1 {
+		eventsJSON, _ := json.MarshalIndent(events, "", "  ")
+		t.Fatalf("got events %s, want only the long-running function call", eventsJSON)
+	}
+	call := events[0].Content.Parts[0].FunctionCall
+
+	tests := []struct {
+		name     string
+		response *genai.Content
+		wantErr  error
+		want     []*genai.Content
+	}{
+		{
+			name:     "unknown function call",
+			response: NewContentFromFunctionResponseWithID("approve", map[string]any{"approved": true}, "unknown", "user"),
+			wantErr:  runner.ErrNoMatchingFunctionCall,
+		},
+		{
+			name:     "resumed by the calling agent",
+			response: NewContentFromFunctionResponseWithID("approve", map[string]any{"approved": true}, call.ID, "user"),
+			want:     []*genai.Content{genai.NewContentFromText("approved", "model")},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			events, err := testutil.CollectEvents(r.RunContent(t, "session", tc.response))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tc.wantErr)
+			}
+			var got []*genai.Content
+			for _, ev := range events {
+				if ev.Author != approver.Name() {
+					t.Errorf("event author = %q, want %q", ev.Author, approver.Name())
+				}
+				got = append(got, ev.Content)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	wantContents := []*genai.Content{
+		genai.NewContentFromText("approve the request", "user"),
+		genai.NewContentFromFunctionCall("approve", map[string]any{}, "model"),
+		genai.NewContentFromFunctionResponse("approve", map[string]any{"approved": true}, "user"),
+	}
+	if diff := cmp.Diff(wantContents, mockModel.Requests[len(mockModel.Requests)-1].Contents,
+		cmpopts.IgnoreFields(genai.FunctionCall{}, "ID"), cmpopts.IgnoreFields(genai.FunctionResponse{}, "ID")); diff != "" {
+		t.Errorf("LLMRequest.Contents mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -18,11 +18,13 @@ package mcptoolset
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/internal/version"
 	"google.golang.org/adk/tool"
 )
@@ -54,10 +56,18 @@ func New(cfg Config) (tool.Toolset, error) {
 	if client == nil {
 		client = mcp.NewClient(&mcp.Implementation{Name: "adk-mcp-client", Version: version.Version}, nil)
 	}
+	transport := cfg.Transport
+	if cfg.Auth != nil {
+		var err error
+		if transport, err = withAuth(transport); err != nil {
+			return nil, err
+		}
+	}
 	return &set{
 		client:     client,
-		transport:  cfg.Transport,
+		transport:  transport,
 		toolFilter: cfg.ToolFilter,
+		auth:       cfg.Auth,
 	}, nil
 }
 
@@ -72,12 +82,48 @@ type Config struct {
 	// If ToolFilter is nil, then all tools are returned.
 	// tool.StringPredicate can be convenient if there's a known fixed list of tool names.
 	ToolFilter tool.Predicate
+	// Auth describes the credential sent to the MCP server.
+	// If the credential of a tool call isn't available, e.g. because the
+	// user must authorize the access first, the tool requests it from the
+	// client and is called again once the client provides it. Tools are
+	// listed with the credential only if it is available without the user,
+	// see auth.Resolve.
+	//
+	// Auth requires a *mcp.StreamableClientTransport or
+	// *mcp.SSEClientTransport.
+	Auth *auth.Config
+}
+
+// withAuth returns a copy of the transport applying the credentials carried
+// by the request contexts.
+func withAuth(t mcp.Transport) (mcp.Transport, error) {
+	switch t := t.(type) {
+	case *mcp.StreamableClientTransport:
+		c := *t
+		c.HTTPClient = authClient(t.HTTPClient)
+		return &c, nil
+	case *mcp.SSEClientTransport:
+		c := *t
+		c.HTTPClient = authClient(t.HTTPClient)
+		return &c, nil
+	}
+	return nil, fmt.Errorf("auth requires an HTTP transport, got %T", t)
+}
+
+func authClient(c *http.Client) *http.Client {
+	if c == nil {
+		c = http.DefaultClient
+	}
+	clone := *c
+	clone.Transport = &auth.Transport{Base: c.Transport}
+	return &clone
 }
 
 type set struct {
 	client     *mcp.Client
 	transport  mcp.Transport
 	toolFilter tool.Predicate
+	auth       *auth.Config
 
 	mu      sync.Mutex
 	session *mcp.ClientSession
@@ -97,7 +143,18 @@ func (*set) IsLongRunning() bool {
 
 // Tools fetch MCP tools from the server, convert to adk tool.Tool and filter by name.
 func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
-	session, err := s.getSession(ctx)
+	listCtx := context.Context(ctx)
+	if s.auth != nil {
+		cred, err := auth.Resolve(ctx, s.auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credential: %w", err)
+		}
+		if cred != nil {
+			listCtx = auth.WithCredential(ctx, s.auth.Scheme, cred)
+		}
+	}
+
+	session, err := s.getSession(listCtx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get MCP session: %w", err)
 	}
@@ -106,7 +163,7 @@ func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
 
 	cursor := ""
 	for {
-		resp, err := session.ListTools(ctx, &mcp.ListToolsParams{
+		resp, err := session.ListTools(listCtx, &mcp.ListToolsParams{
 			Cursor: cursor,
 		})
 		if err != nil {
@@ -114,7 +171,7 @@ func (s *set) Tools(ctx agent.ReadonlyContext) ([]tool.Tool, error) {
 		}
 
 		for _, mcpTool := range resp.Tools {
-			t, err := convertTool(mcpTool, s.getSession)
+			t, err := convertTool(mcpTool, s.getSession, s.auth)
 			if err != nil {
 				return nil, fmt.Errorf("failed to convert MCP tool %q to adk tool: %w", mcpTool.Name, err)
 			}
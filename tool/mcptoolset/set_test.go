@@ -16,10 +16,12 @@ package mcptoolset_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"iter"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -31,11 +33,13 @@ import (
 
 	"google.golang.org/adk/agent"
 	"google.golang.org/adk/agent/llmagent"
+	"google.golang.org/adk/auth"
 	icontext "google.golang.org/adk/internal/context"
 	"google.golang.org/adk/internal/httprr"
 	"google.golang.org/adk/internal/testutil"
 	"google.golang.org/adk/model"
 	"google.golang.org/adk/model/gemini"
+	"google.golang.org/adk/model/modeltest"
 	"google.golang.org/adk/runner"
 	"google.golang.org/adk/session"
 	"google.golang.org/adk/tool"
@@ -307,3 +311,197 @@ func TestToolFilter(t *testing.T) {
 		t.Errorf("tools mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func TestAuth(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "auth_server", Version: "v1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami", Description: "returns the authorization header"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: req.Extra.Header.Get("Authorization")}},
+			}, nil, nil
+		})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	defer func() {
+		// The MCP session keeps a hanging GET request open.
+		srv.CloseClientConnections()
+		srv.Close()
+	}()
+
+	authConfig := &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "bearer"}}
+	ts, err := mcptoolset.New(mcptoolset.Config{
+		Transport: &mcp.StreamableClientTransport{Endpoint: srv.URL},
+		Auth:      authConfig,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:     "auth_agent",
+		Model:    modeltest.New(modeltest.FunctionCall("whoami", nil), modeltest.Text("done")),
+		Toolsets: []tool.Toolset{ts},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "who am I?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want the function call, response and auth events", len(events))
+	}
+	call := events[2].Content.Parts[0].FunctionCall
+	if call.Name != auth.RequestCredentialFunctionName {
+		t.Fatalf("got call %q, want %q", call.Name, auth.RequestCredentialFunctionName)
+	}
+
+	// The user provides the token.
+	resp := authConfig.Clone()
+	resp.CredentialKey = authConfig.Key()
+	resp.ExchangedCredential = &auth.Credential{
+		Type: auth.CredentialHTTP,
+		HTTP: &auth.HTTPAuth{Scheme: "bearer", Credentials: auth.HTTPCredentials{Token: "secret"}},
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response map[string]any
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatal(err)
+	}
+	reply := genai.NewContentFromFunctionResponse(call.Name, response, genai.RoleUser)
+	reply.Parts[0].FunctionResponse.ID = call.ID
+	events, err = testutil.CollectEvents(runner.RunContent(t, "session", reply))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 {
+		t.Fatal("got no events")
+	}
+	got := events[0].Content.Parts[0].FunctionResponse.Response
+	if diff := cmp.Diff(map[string]any{"output": "Bearer secret"}, got); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuth_OAuth2ClientSecret(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "auth_server", Version: "v1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami", Description: "returns the authorization header"},
+		func(ctx context.Context, req *mcp.CallToolRequest, _ struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: req.Extra.Header.Get("Authorization")}},
+			}, nil, nil
+		})
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	defer func() {
+		// The MCP session keeps a hanging GET request open.
+		srv.CloseClientConnections()
+		srv.Close()
+	}()
+	// The token endpoint only accepts the client secret of the tool.
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() failed: %v", err)
+		}
+		user, secret, ok := r.BasicAuth()
+		if !ok {
+			user, secret = r.Form.Get("client_id"), r.Form.Get("client_secret")
+		}
+		if user != "client" || secret != "tool-secret" || r.Form.Get("code") != "code" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "user-token", "token_type": "Bearer"})
+	}))
+	defer tokenSrv.Close()
+
+	authConfig := &auth.Config{
+		Scheme: &auth.Scheme{
+			Type: auth.SchemeOAuth2,
+			Flows: &auth.OAuthFlows{AuthorizationCode: &auth.OAuthFlow{
+				AuthorizationURL: "https://example.com/authorize",
+				TokenURL:         tokenSrv.URL,
+			}},
+		},
+		RawCredential: &auth.Credential{
+			Type: auth.CredentialOAuth2,
+			OAuth2: &auth.OAuth2Auth{
+				ClientID:     "client",
+				ClientSecret: "tool-secret",
+				RedirectURI:  "https://example.com/callback",
+			},
+		},
+	}
+	ts, err := mcptoolset.New(mcptoolset.Config{
+		Transport: &mcp.StreamableClientTransport{Endpoint: srv.URL},
+		Auth:      authConfig,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MCP tool set: %v", err)
+	}
+	a, err := llmagent.New(llmagent.Config{
+		Name:     "auth_agent",
+		Model:    modeltest.New(modeltest.FunctionCall("whoami", nil), modeltest.Text("done")),
+		Toolsets: []tool.Toolset{ts},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := testutil.NewTestAgentRunner(t, a)
+
+	events, err := testutil.CollectEvents(runner.Run(t, "session", "who am I?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want the function call, response and auth events", len(events))
+	}
+	for _, ev := range events {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "tool-secret") {
+			t.Errorf("event %s contains the client secret", data)
+		}
+	}
+	call := events[2].Content.Parts[0].FunctionCall
+
+	// The client returns the requested config with the authorization
+	// response.
+	response, _ := call.Args["authConfig"].(map[string]any)
+	exchanged, _ := response["exchangedAuthCredential"].(map[string]any)
+	oauth2, _ := exchanged["oauth2"].(map[string]any)
+	if oauth2 == nil {
+		t.Fatalf("no OAuth2 credential in the request %v", call.Args)
+	}
+	oauth2["authResponseUri"] = fmt.Sprintf("https://example.com/callback?code=code&state=%s", oauth2["state"])
+	reply := genai.NewContentFromFunctionResponse(call.Name, response, genai.RoleUser)
+	reply.Parts[0].FunctionResponse.ID = call.ID
+	events, err = testutil.CollectEvents(runner.RunContent(t, "session", reply))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 {
+		t.Fatal("got no events")
+	}
+	got := events[0].Content.Parts[0].FunctionResponse.Response
+	if diff := cmp.Diff(map[string]any{"output": "Bearer user-token"}, got); diff != "" {
+		t.Errorf("function response mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAuth_UnsupportedTransport(t *testing.T) {
+	clientTransport, _ := mcp.NewInMemoryTransports()
+	_, err := mcptoolset.New(mcptoolset.Config{
+		Transport: clientTransport,
+		Auth:      &auth.Config{Scheme: &auth.Scheme{Type: auth.SchemeHTTP, Scheme: "bearer"}},
+	})
+	if err == nil {
+		t.Error("New() with an in-memory transport and auth succeeded, want error")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"google.golang.org/genai"
 
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/internal/toolinternal"
 	"google.golang.org/adk/internal/toolinternal/toolutils"
 	"google.golang.org/adk/model"
@@ -31,7 +32,7 @@ import (
 
 type getSessionFunc func(ctx context.Context) (*mcp.ClientSession, error)
 
-func convertTool(t *mcp.Tool, getSessionFunc getSessionFunc) (tool.Tool, error) {
+func convertTool(t *mcp.Tool, getSessionFunc getSessionFunc, authConfig *auth.Config) (tool.Tool, error) {
 	mcp := &mcpTool{
 		name:        t.Name,
 		description: t.Description,
@@ -40,6 +41,7 @@ func convertTool(t *mcp.Tool, getSessionFunc getSessionFunc) (tool.Tool, error)
 			Description: t.Description,
 		},
 		getSessionFunc: getSessionFunc,
+		auth:           authConfig,
 	}
 
 	// Since t.InputSchema and t.OutputSchema are pointers (*jsonschema.Schema) and the destination ResponseJsonSchema
@@ -62,6 +64,7 @@ type mcpTool struct {
 	funcDeclaration *genai.FunctionDeclaration
 
 	getSessionFunc getSessionFunc
+	auth           *auth.Config
 }
 
 // Name implements the tool.Tool.
@@ -88,13 +91,27 @@ func (t *mcpTool) Declaration() *genai.FunctionDeclaration {
 }
 
 func (t *mcpTool) Run(ctx tool.Context, args any) (map[string]any, error) {
-	session, err := t.getSessionFunc(ctx)
+	callCtx := context.Context(ctx)
+	if t.auth != nil {
+		cred, err := ctx.Credential(t.auth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credential: %w", err)
+		}
+		if cred == nil {
+			if err := ctx.RequestCredential(t.auth); err != nil {
+				return nil, fmt.Errorf("failed to request credential: %w", err)
+			}
+			return map[string]any{"output": "Pending user authorization."}, nil
+		}
+		callCtx = auth.WithCredential(ctx, t.auth.Scheme, cred)
+	}
+
+	session, err := t.getSessionFunc(callCtx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
 
-	// TODO: add auth
-	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+	res, err := session.CallTool(callCtx, &mcp.CallToolParams{
 		Name:      t.name,
 		Arguments: args,
 	})
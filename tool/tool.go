@@ -21,8 +21,10 @@ import (
 	"context"
 
 	"google.golang.org/adk/agent"
+	"google.golang.org/adk/auth"
 	"google.golang.org/adk/memory"
 	"google.golang.org/adk/session"
+	"google.golang.org/adk/tool/toolconfirmation"
 )
 
 // Tool defines the interface for a callable tool.
@@ -36,6 +38,15 @@ type Tool interface {
 	IsLongRunning() bool
 }
 
+// Sequential is implemented by tools which must not run concurrently with
+// other function calls, e.g. because they are not safe for concurrent use.
+//
+// The function calls of a model response run concurrently. If Sequential
+// returns true, the function calls of the tool run alone instead.
+type Sequential interface {
+	Sequential() bool
+}
+
 // Context defines the interface for the context passed to a tool when it's
 // called. It provides access to invocation-specific information and allows
 // the tool to interact with the agent's state and memory.
@@ -51,6 +62,26 @@ type Context interface {
 	Actions() *session.EventActions
 	// SearchMemory performs a semantic search on the agent's memory.
 	SearchMemory(context.Context, string) (*memory.SearchResponse, error)
+
+	// Credential returns the credential described by the config, if it is
+	// available without asking the user: a credential the user provided
+	// earlier, the raw credential of an API key or HTTP scheme, or a token of
+	// the OAuth2 client credentials flow. It returns nil otherwise.
+	Credential(*auth.Config) (*auth.Credential, error)
+	// RequestCredential asks the client for the credential described by the
+	// config. Once the client provides it, the function call is run again
+	// and Credential returns it.
+	RequestCredential(*auth.Config) error
+
+	// RequestConfirmation asks the user to approve the function call, with
+	// a hint telling what to confirm and optional tool specific data. The
+	// invocation is suspended until the client responds. The function call
+	// is then run again, and ToolConfirmation returns the response.
+	RequestConfirmation(hint string, payload any) error
+	// ToolConfirmation returns the response of the user to the confirmation
+	// requested by the function call, or nil if the function call isn't
+	// resumed after a confirmation request.
+	ToolConfirmation() *toolconfirmation.ToolConfirmation
 }
 
 // Toolset is an interface for a collection of tools. It allows grouping
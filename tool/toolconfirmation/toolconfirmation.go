@@ -0,0 +1,55 @@
+// Copyright 2025 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package toolconfirmation defines the confirmations tools request from the
+// user before running, e.g. to approve a payment or a deletion.
+//
+// A tool requests a confirmation with tool.Context.RequestConfirmation, or
+// with the RequireConfirmation option of functiontool.Config. The flow then
+// yields an event calling the FunctionCallName function, with the
+// [RequestArgs] arguments, and suspends the invocation like a long-running
+// tool. The client approves or rejects the call by sending a function
+// response to this call, whose response is a [ToolConfirmation], e.g.
+// {"confirmed": true}. The flow then runs the original function call again,
+// and tool.Context.ToolConfirmation returns the response.
+package toolconfirmation
+
+import "google.golang.org/genai"
+
+// FunctionCallName is the name of the function called by the events
+// requesting confirmations from the client.
+const FunctionCallName = "adk_request_confirmation"
+
+// ToolConfirmation is a confirmation requested by a tool, or the response of
+// the user to it.
+type ToolConfirmation struct {
+	// Hint tells the user what to confirm.
+	Hint string `json:"hint,omitempty"`
+	// Confirmed reports whether the user approved the function call.
+	Confirmed bool `json:"confirmed"`
+	// Payload is tool specific data, requested by the tool or provided by the
+	// user.
+	Payload any `json:"payload,omitempty"`
+	// Args, if set in the response of the user, replaces the arguments of the
+	// confirmed function call.
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// RequestArgs are the arguments of the calls requesting confirmations.
+type RequestArgs struct {
+	// OriginalFunctionCall is the function call to confirm.
+	OriginalFunctionCall *genai.FunctionCall `json:"originalFunctionCall"`
+	// ToolConfirmation is the requested confirmation.
+	ToolConfirmation *ToolConfirmation `json:"toolConfirmation"`
+}